@@ -0,0 +1,61 @@
+package resilient
+
+// AggregateStats sums the statistics of several clients into a fleet-wide
+// view. Counters and ConcurrencyLimit are added up, and the latency
+// histograms are merged; when the clients use different WithLatencyBuckets
+// the merged Latency has no buckets or percentiles, only Count, Sum and Max.
+func AggregateStats(clients ...StatsProvider) Stats {
+	var total Stats
+	for _, c := range clients {
+		total = addStats(total, c.Stats())
+	}
+	return total
+}
+
+func addStats(a, b Stats) Stats {
+	a.TotalRequests += b.TotalRequests
+	a.TotalErrors += b.TotalErrors
+	a.RateLimited += b.RateLimited
+	a.Hedged += b.Hedged
+	a.ConcurrencyWaits += b.ConcurrencyWaits
+	a.ConcurrencyLimit += b.ConcurrencyLimit
+	a.Shedded += b.Shedded
+	a.DNSHits += b.DNSHits
+	a.DNSMisses += b.DNSMisses
+	a.DroppedEvents += b.DroppedEvents
+	a.Latency = a.Latency.merge(b.Latency)
+	a.TotalRetries += b.TotalRetries
+	a.TotalBackoffTime += b.TotalBackoffTime
+	a.AdaptiveReductions += b.AdaptiveReductions
+	return a
+}
+
+// StatsReport is a fleet view: the statistics of each named client and
+// their sum.
+type StatsReport struct {
+	Total   Stats
+	Clients map[string]Stats
+}
+
+// NewStatsReport snapshots each named client and sums them into Total.
+func NewStatsReport(clients map[string]StatsProvider) StatsReport {
+	r := StatsReport{Clients: make(map[string]Stats, len(clients))}
+	for name, c := range clients {
+		s := c.Stats()
+		r.Clients[name] = s
+		r.Total = addStats(r.Total, s)
+	}
+	return r
+}
+
+// Report returns the statistics of every client in the registry together
+// with their sum.
+func (r *Registry) Report() StatsReport {
+	clients := make(map[string]StatsProvider)
+	r.mu.Lock()
+	for name, c := range r.clients {
+		clients[name] = c
+	}
+	r.mu.Unlock()
+	return NewStatsReport(clients)
+}
@@ -0,0 +1,59 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAggregateStats(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	a := New(WithBaseURL(srv.URL))
+	defer a.Close()
+	b := New(WithBaseURL(srv.URL))
+	defer b.Close()
+	other := New(WithBaseURL(srv.URL), WithLatencyBuckets(time.Second))
+	defer other.Close()
+
+	a.Get(context.Background(), "/")
+	a.Get(context.Background(), "/fail")
+	b.Get(context.Background(), "/")
+
+	s := AggregateStats(a, b)
+	if s.TotalRequests != 3 || s.TotalErrors != 1 {
+		t.Fatalf("expected 3 requests and 1 error, got %d and %d", s.TotalRequests, s.TotalErrors)
+	}
+	if s.Latency.Count != 3 || len(s.Latency.Buckets) != len(DefaultLatencyBuckets)+1 || s.Latency.P50 == 0 {
+		t.Fatalf("expected merged latency buckets, got %+v", s.Latency)
+	}
+
+	other.Get(context.Background(), "/")
+	s = AggregateStats(a, other)
+	if s.Latency.Count != 3 || s.Latency.Buckets != nil || s.Latency.P50 != 0 {
+		t.Fatalf("expected totals without buckets for mismatched bounds, got %+v", s.Latency)
+	}
+}
+
+func TestRegistryReport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	reg := NewRegistry(WithBaseURL(srv.URL))
+	defer reg.Close()
+	reg.Client("a").Get(context.Background(), "/")
+	reg.Client("b").Get(context.Background(), "/")
+	reg.Client("b").Get(context.Background(), "/")
+
+	r := reg.Report()
+	if r.Total.TotalRequests != 3 || r.Clients["a"].TotalRequests != 1 || r.Clients["b"].TotalRequests != 2 {
+		t.Fatalf("unexpected report %+v", r)
+	}
+}
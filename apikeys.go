@@ -0,0 +1,125 @@
+package resilient
+
+import (
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// KeyStrategy selects how WithAPIKeyPool picks a key for each attempt.
+type KeyStrategy int
+
+const (
+	// KeyRoundRobin cycles through the keys on every attempt.
+	KeyRoundRobin KeyStrategy = iota
+	// KeyFailover sticks with one key until it is rate-limited, then
+	// moves on to the next.
+	KeyFailover
+)
+
+// keyCooldown is how long a rate-limited key is skipped when the 429
+// carries no Retry-After.
+const keyCooldown = time.Minute
+
+type apiKeyConfig struct {
+	header   string
+	keys     []string
+	strategy KeyStrategy
+}
+
+// keyPool hands out API keys and benches the ones that get rate-limited.
+type keyPool struct {
+	header   string
+	keys     []string
+	strategy KeyStrategy
+
+	mu          sync.Mutex
+	next        int
+	limited     map[string]time.Time // key -> benched until
+	rateLimited map[string]uint64
+}
+
+func newKeyPool(cfg *config) *keyPool {
+	if len(cfg.apiKeys.keys) == 0 {
+		return nil
+	}
+	return &keyPool{
+		header:      http.CanonicalHeaderKey(cfg.apiKeys.header),
+		keys:        cfg.apiKeys.keys,
+		strategy:    cfg.apiKeys.strategy,
+		limited:     make(map[string]time.Time),
+		rateLimited: make(map[string]uint64),
+	}
+}
+
+// pick returns the key for the next attempt: the next key in turn that
+// isn't benched, or the one whose bench ends first when all of them are.
+func (p *keyPool) pick(now time.Time) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	best := -1
+	for i := range p.keys {
+		idx := (p.next + i) % len(p.keys)
+		until := p.limited[p.keys[idx]]
+		if !now.Before(until) {
+			best = idx
+			break
+		}
+		if best < 0 || until.Before(p.limited[p.keys[best]]) {
+			best = idx
+		}
+	}
+	if p.strategy == KeyRoundRobin {
+		p.next = best + 1
+	} else {
+		p.next = best
+	}
+	return p.keys[best]
+}
+
+// observe benches the key used for resp if it was rate-limited.
+func (p *keyPool) observe(resp *http.Response, now time.Time) {
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Request == nil {
+		return
+	}
+	key := resp.Request.Header.Get(p.header)
+	wait := retryAfterAt(resp.Header.Get("Retry-After"), now)
+	if wait <= 0 {
+		wait = keyCooldown
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !slices.Contains(p.keys, key) {
+		return
+	}
+	p.rateLimited[key]++
+	p.limited[key] = now.Add(wait)
+	if p.strategy == KeyFailover && p.keys[p.next%len(p.keys)] == key {
+		p.next = (p.next + 1) % len(p.keys)
+	}
+}
+
+// setAPIKey sets the pool's header on req unless req already has one.
+func (c *Client) setAPIKey(req *http.Request) {
+	if c.keys != nil && req.Header.Get(c.keys.header) == "" {
+		req.Header.Set(c.keys.header, c.keys.pick(time.Now()))
+	}
+}
+
+// APIKeyStats returns how many 429 responses each key of WithAPIKeyPool
+// has received, or nil without a key pool.
+func (c *Client) APIKeyStats() map[string]uint64 {
+	if c.keys == nil {
+		return nil
+	}
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+	stats := make(map[string]uint64, len(c.keys.keys))
+	for _, k := range c.keys.keys {
+		stats[k] = c.keys.rateLimited[k]
+	}
+	return stats
+}
@@ -0,0 +1,74 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyPoolRoundRobin(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("X-Api-Key"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithAPIKeyPool("X-API-Key", []string{"a", "b", "c"}, KeyRoundRobin))
+	defer c.Close()
+
+	for range 4 {
+		if _, _, err := c.Get(context.Background(), "/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := strings.Join(seen, ""); got != "abca" {
+		t.Fatalf("expected round robin, got %q", got)
+	}
+}
+
+func TestAPIKeyPoolFailover(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-Api-Key")
+		seen = append(seen, key)
+		if key == "a" {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithRetry(1, time.Millisecond),
+		WithMaxRetryAfter(time.Millisecond),
+		WithAdaptiveDisabled(),
+		WithAPIKeyPool("X-API-Key", []string{"a", "b"}, KeyFailover),
+	)
+	defer c.Close()
+
+	for range 3 {
+		if _, _, err := c.Get(context.Background(), "/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := strings.Join(seen, ""); got != "abbb" {
+		t.Fatalf("expected a switch to b after the 429, got %q", got)
+	}
+	if stats := c.APIKeyStats(); stats["a"] != 1 || stats["b"] != 0 {
+		t.Fatalf("unexpected key stats %v", stats)
+	}
+}
+
+func TestKeyPoolAllBenched(t *testing.T) {
+	p := &keyPool{keys: []string{"a", "b"}, limited: map[string]time.Time{}}
+	now := time.Now()
+	p.limited["a"] = now.Add(time.Hour)
+	p.limited["b"] = now.Add(time.Minute)
+	if k := p.pick(now); k != "b" {
+		t.Fatalf("expected the key freed first, got %q", k)
+	}
+}
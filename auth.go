@@ -0,0 +1,95 @@
+package resilient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// authToken holds the Authorization header obtained through
+// WithAuthRefresh. gen counts refreshes, so requests that failed with an
+// already-replaced token don't trigger another refresh. refresh is the
+// refresh in flight, if any; mu is never held while it runs.
+type authToken struct {
+	mu      sync.Mutex
+	header  string
+	gen     uint64
+	refresh *authRefresh
+}
+
+// authRefresh is a refresh shared by every request that hit a 401 with the
+// same token. err is set before done is closed.
+type authRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+func (a *authToken) get() (string, uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.header, a.gen
+}
+
+// reauthenticate prepares the retry of a request answered with 401
+// Unauthorized, by answering a Digest challenge or refreshing the bearer
+// token, and reports whether the request should be retried. seen is the
+// token generation the request was sent with.
+func (c *Client) reauthenticate(ctx context.Context, resp *http.Response, seen uint64) (bool, error) {
+	if c.digest != nil && c.digest.challenge(resp) {
+		return true, nil
+	}
+	if c.cfg.authRefresh == nil {
+		return false, nil
+	}
+	if err := c.refreshAuth(ctx, seen); err != nil {
+		return false, fmt.Errorf("resilient: auth refresh: %w", err)
+	}
+	return true, nil
+}
+
+// refreshAuth fetches a new token unless one newer than seen is already in
+// place. Concurrent callers share one refresh, and the refresh function
+// runs without holding c.auth.mu, so other requests keep reading the
+// current token while it fetches a new one.
+func (c *Client) refreshAuth(ctx context.Context, seen uint64) error {
+	a := &c.auth
+	a.mu.Lock()
+	if a.gen != seen {
+		a.mu.Unlock()
+		return nil
+	}
+	if r := a.refresh; r != nil {
+		a.mu.Unlock()
+		select {
+		case <-r.done:
+			return r.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	r := &authRefresh{done: make(chan struct{})}
+	a.refresh = r
+	a.mu.Unlock()
+
+	token, err := c.cfg.authRefresh(ctx)
+
+	a.mu.Lock()
+	if err == nil {
+		a.header = "Bearer " + token
+		a.gen++
+	}
+	a.refresh = nil
+	a.mu.Unlock()
+	r.err = err
+	close(r.done)
+	return err
+}
+
+// setBearer sets the Authorization header from WithBearerTokenFunc unless
+// req already has one.
+func (c *Client) setBearer(req *http.Request) {
+	if c.cfg.bearerFunc != nil && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.bearerFunc())
+	}
+}
@@ -0,0 +1,158 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAuthRefresh(t *testing.T) {
+	var valid atomic.Value
+	valid.Store("Bearer t1")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != valid.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var refreshes atomic.Int32
+	c := New(WithBaseURL(srv.URL), WithRetry(0, 0), WithAuthRefresh(func(ctx context.Context) (string, error) {
+		return fmt.Sprintf("t%d", refreshes.Add(1)), nil
+	}))
+	defer c.Close()
+
+	body, status, err := c.Get(context.Background(), "/")
+	if err != nil || status != 200 || string(body) != "ok" {
+		t.Fatalf("expected refresh and retry to succeed, got %d %v", status, err)
+	}
+
+	// The token is reused, and concurrent 401s share one refresh.
+	valid.Store("Bearer t2")
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			if _, _, err := c.Get(context.Background(), "/"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if n := refreshes.Load(); n != 2 {
+		t.Fatalf("expected 2 refreshes, got %d", n)
+	}
+}
+
+func TestAuthRefreshGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithAuthRefresh(func(ctx context.Context) (string, error) {
+		return "still-bad", nil
+	}))
+	defer c.Close()
+
+	_, status, err := c.Get(context.Background(), "/")
+	if err == nil || status != 401 || calls.Load() != 2 {
+		t.Fatalf("expected a single retry then 401, got %d after %d calls: %v", status, calls.Load(), err)
+	}
+
+	boom := errors.New("refresh failed")
+	c = New(WithBaseURL(srv.URL), WithAuthRefresh(func(ctx context.Context) (string, error) {
+		return "", boom
+	}))
+	defer c.Close()
+	if _, _, err := c.Get(context.Background(), "/"); !errors.Is(err, boom) {
+		t.Fatalf("expected refresh error, got %v", err)
+	}
+}
+
+func TestBearerAndBasicAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer srv.Close()
+
+	get := func(c *Client, headers ...map[string]string) string {
+		t.Helper()
+		defer c.Close()
+		body, _, err := c.Get(context.Background(), "/", headers...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	if got := get(New(WithBaseURL(srv.URL), WithBearerToken("abc"))); got != "Bearer abc" {
+		t.Fatalf("bearer: got %q", got)
+	}
+	if got := get(New(WithBaseURL(srv.URL), WithBasicAuth("user", "pass"))); got != "Basic dXNlcjpwYXNz" {
+		t.Fatalf("basic: got %q", got)
+	}
+
+	var n atomic.Int32
+	tokenFunc := WithBearerTokenFunc(func() string { return fmt.Sprintf("rot%d", n.Add(1)) })
+	if got := get(New(WithBaseURL(srv.URL), tokenFunc)); got != "Bearer rot1" {
+		t.Fatalf("token func: got %q", got)
+	}
+	if got := get(New(WithBaseURL(srv.URL), tokenFunc), map[string]string{"Authorization": "Custom x"}); got != "Custom x" {
+		t.Fatalf("explicit header should win, got %q", got)
+	}
+}
+
+func TestAuthRefreshDoesNotBlockRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/secure" && r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var refreshes atomic.Int32
+	c := New(WithBaseURL(srv.URL), WithRetry(0, 0), WithAuthRefresh(func(ctx context.Context) (string, error) {
+		if refreshes.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return "fresh", nil
+	}))
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(func() {
+			if _, status, err := c.Get(context.Background(), "/secure"); err != nil || status != http.StatusOK {
+				t.Errorf("expected refreshed request to succeed, got %d %v", status, err)
+			}
+		})
+	}
+	<-started
+
+	// Requests keep flowing while the refresh function is still running.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, status, err := c.Get(ctx, "/open"); err != nil || status != http.StatusOK {
+		t.Fatalf("expected request during refresh to succeed, got %d %v", status, err)
+	}
+
+	close(release)
+	wg.Wait()
+	if n := refreshes.Load(); n != 1 {
+		t.Fatalf("expected 1 shared refresh, got %d", n)
+	}
+}
@@ -0,0 +1,130 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// BatchGet performs a GET request to baseURL+path for every path, at most
+// concurrency at a time (all at once if concurrency <= 0), and returns the
+// results in the order of paths. Each request goes through the rate
+// limiter and retry loop as usual; its error, if any, is in Result.Err.
+// The returned error joins the per-request errors, and is nil when every
+// request succeeded.
+func (c *Client) BatchGet(ctx context.Context, paths []string, concurrency int) ([]Result, error) {
+	results := make([]Result, len(paths))
+	runBatch(len(paths), concurrency, func(i int) {
+		req, err := c.NewRequest(ctx, http.MethodGet, paths[i], nil)
+		if err != nil {
+			results[i].Err = err
+			return
+		}
+		res, err := c.DoResult(req.Context(), req)
+		results[i] = *res
+		results[i].Err = err
+	})
+
+	var errs []error
+	for i, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", paths[i], r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// Batch queues prepared requests and executes them concurrently:
+//
+//	results, err := client.NewBatch().
+//		Add(reqA).
+//		Add(reqB).
+//		Concurrency(8).
+//		StopOnError().
+//		Do(ctx)
+//
+// Each request gets its own retry loop through the client, while all of
+// them share the context passed to Do. A Batch is not safe for concurrent
+// use while it is being built.
+type Batch struct {
+	c           *Client
+	reqs        []*http.Request
+	concurrency int
+	stopOnError bool
+}
+
+// NewBatch returns an empty Batch that executes through c.
+func (c *Client) NewBatch() *Batch {
+	return &Batch{c: c}
+}
+
+// Add queues req. Results are returned in the order requests were added.
+func (b *Batch) Add(req *http.Request) *Batch {
+	b.reqs = append(b.reqs, req)
+	return b
+}
+
+// Len returns the number of queued requests.
+func (b *Batch) Len() int {
+	return len(b.reqs)
+}
+
+// Concurrency bounds how many requests run at once; n <= 0 (the default)
+// runs them all at once.
+func (b *Batch) Concurrency(n int) *Batch {
+	b.concurrency = n
+	return b
+}
+
+// StopOnError cancels the requests still pending or in flight as soon as
+// one fails, instead of running the whole batch.
+func (b *Batch) StopOnError() *Batch {
+	b.stopOnError = true
+	return b
+}
+
+// Do executes the queued requests and returns one Result per request, in
+// order, with its error in Result.Err. The returned error joins the
+// per-request errors and is nil when every request succeeded.
+func (b *Batch) Do(ctx context.Context) ([]Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]Result, len(b.reqs))
+	runBatch(len(b.reqs), b.concurrency, func(i int) {
+		res, err := b.c.DoResult(ctx, b.reqs[i])
+		results[i] = *res
+		results[i].Err = err
+		if err != nil && b.stopOnError {
+			cancel()
+		}
+	})
+
+	var errs []error
+	for i, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", b.reqs[i].Method, b.reqs[i].URL, r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// runBatch calls fn for 0..n-1 with at most concurrency calls in flight,
+// and waits for all of them.
+func runBatch(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 || concurrency > n {
+		concurrency = n
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range n {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			fn(i)
+		})
+	}
+	wg.Wait()
+}
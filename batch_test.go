@@ -0,0 +1,126 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchGet(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r.URL.Path == "/missing" {
+			http.Error(w, "nope", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	paths := []string{"/a", "/b", "/missing", "/c", "/d", "/e"}
+	results, err := c.BatchGet(context.Background(), paths, 2)
+	if err == nil || !strings.Contains(err.Error(), "/missing") {
+		t.Fatalf("expected joined error naming /missing, got %v", err)
+	}
+	for i, p := range paths {
+		r := results[i]
+		if p == "/missing" {
+			if r.Err == nil || r.StatusCode != 404 {
+				t.Fatalf("expected 404 error for %s, got %d %v", p, r.StatusCode, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil || string(r.Body) != p {
+			t.Fatalf("result %d: expected %q, got %q %v", i, p, r.Body, r.Err)
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, saw %d", p)
+	}
+}
+
+func TestBatchGetCanceled(t *testing.T) {
+	c := New()
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := c.BatchGet(ctx, []string{"http://127.0.0.1:1/a"}, 0)
+	if !errors.Is(err, context.Canceled) || !errors.Is(results[0].Err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(r.Method + " " + r.URL.Path))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	get, _ := c.NewRequest(context.Background(), "GET", "/a", nil)
+	post, _ := c.NewRequest(context.Background(), "POST", "/b", strings.NewReader("x"))
+	b := c.NewBatch().Add(get).Add(post).Concurrency(1)
+	if b.Len() != 2 {
+		t.Fatalf("expected 2 queued requests, got %d", b.Len())
+	}
+
+	results, err := b.Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(results[0].Body) != "GET /a" || results[1].StatusCode != 201 || string(results[1].Body) != "POST /b" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestBatchStopOnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "bad", http.StatusBadRequest)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	slow, _ := c.NewRequest(context.Background(), "GET", "/slow", nil)
+	fail, _ := c.NewRequest(context.Background(), "GET", "/fail", nil)
+
+	start := time.Now()
+	results, err := c.NewBatch().Add(slow).Add(fail).StopOnError().Do(context.Background())
+	if time.Since(start) > 2*time.Second {
+		t.Fatal("expected the failure to cancel the slow request")
+	}
+	if err == nil || results[1].StatusCode != 400 || !errors.Is(results[0].Err, context.Canceled) {
+		t.Fatalf("unexpected outcome: %v / %v", err, results[0].Err)
+	}
+}
@@ -0,0 +1,85 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// maxPooledBuffer is the largest buffer kept for reuse, so one huge
+// response doesn't pin its memory in the pool.
+const maxPooledBuffer = 1 << 20
+
+var bufferPool = sync.Pool{New: func() any { return new(Buffer) }}
+
+// Buffer is a response body read into a pooled buffer by DoBuffer. Call
+// Release once done with it to hand the memory back for reuse; neither the
+// Buffer nor slices returned by Bytes may be used afterwards.
+type Buffer struct {
+	bytes.Buffer
+}
+
+// Release returns b to the pool. It is safe to call on a nil Buffer.
+func (b *Buffer) Release() {
+	if b == nil || b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+func getBuffer() *Buffer {
+	return bufferPool.Get().(*Buffer)
+}
+
+// DoBuffer executes req like Do, but reads the response body into a pooled
+// Buffer instead of a freshly allocated slice, which cuts allocations for
+// high-throughput callers. The Buffer is never nil and holds the body of
+// error responses too; call Release when done with it. Like Download, it
+// bypasses the response cache and singleflight.
+func (c *Client) DoBuffer(ctx context.Context, req *http.Request) (*Buffer, int, error) {
+	buf := getBuffer()
+	res, err := c.execute(ctx, c.withDefaultHeaders(req), func(resp *http.Response) error {
+		return readResponse(buf, resp, c.cfg.maxResponseSize)
+	})
+	if res.Body != nil {
+		buf.Write(res.Body)
+	}
+	return buf, res.StatusCode, err
+}
+
+// readBody reads up to limit bytes of resp's body through a pooled buffer
+// and returns them in a slice of exactly the right size. A body over the
+// limit is returned truncated, along with ErrResponseTooLarge.
+func readBody(resp *http.Response, limit int64) ([]byte, error) {
+	buf := getBuffer()
+	defer buf.Release()
+	err := readResponse(buf, resp, limit)
+	if err != nil && !errors.Is(err, ErrResponseTooLarge) {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), err
+}
+
+// readResponse appends up to limit bytes of resp's body to buf, sizing it
+// up front from Content-Length when known. If the body is longer, buf keeps
+// the first limit bytes and ErrResponseTooLarge is returned.
+func readResponse(buf *Buffer, resp *http.Response, limit int64) error {
+	if n := resp.ContentLength; n > 0 && n <= limit {
+		buf.Grow(int(n))
+	}
+	start := buf.Len()
+	n, err := buf.ReadFrom(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		buf.Truncate(start + int(limit))
+		return fmt.Errorf("%w (limit %d bytes)", ErrResponseTooLarge, limit)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDoBuffer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "not here", http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, "pooled")
+	}))
+	defer srv.Close()
+
+	c := New(WithRetry(0, 0))
+	defer c.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	buf, status, err := c.DoBuffer(context.Background(), req)
+	if err != nil || status != 200 || buf.String() != "pooled" {
+		t.Fatalf("expected pooled body, got %q %d %v", buf.String(), status, err)
+	}
+	buf.Release()
+
+	req, _ = http.NewRequest("GET", srv.URL+"/missing", nil)
+	buf, status, err = c.DoBuffer(context.Background(), req)
+	defer buf.Release()
+	if err == nil || status != 404 || !strings.Contains(buf.String(), "not here") {
+		t.Fatalf("expected error body in buffer, got %q %d %v", buf.String(), status, err)
+	}
+}
+
+func TestDoBufferMaxResponseSize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "0123456789")
+	}))
+	defer srv.Close()
+
+	c := New(WithMaxResponseSize(4))
+	defer c.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	buf, _, err := c.DoBuffer(context.Background(), req)
+	defer buf.Release()
+	if !errors.Is(err, ErrResponseTooLarge) || buf.String() != "0123" {
+		t.Fatalf("expected truncated body and ErrResponseTooLarge, got %q %v", buf.String(), err)
+	}
+}
+
+func TestBufferReleaseDropsLarge(t *testing.T) {
+	buf := getBuffer()
+	buf.Grow(maxPooledBuffer + 1)
+	buf.Release() // must not panic or pool the buffer
+	var nilBuf *Buffer
+	nilBuf.Release()
+}
+
+var benchBody = bytes.Repeat([]byte("x"), 64<<10)
+
+func newBenchResponse() *http.Response {
+	return &http.Response{
+		Body:          io.NopCloser(bytes.NewReader(benchBody)),
+		ContentLength: -1,
+	}
+}
+
+func BenchmarkReadAll(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := io.ReadAll(io.LimitReader(newBenchResponse().Body, 10<<20)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadBodyPooled(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := readBody(newBenchResponse(), 10<<20); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkClient(b *testing.B, do func(c *Client, req *http.Request)) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(benchBody)
+	}))
+	defer srv.Close()
+	c := New(WithRetry(0, 0))
+	defer c.Close()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		req, _ := http.NewRequest("GET", srv.URL, nil)
+		do(c, req)
+	}
+}
+
+func BenchmarkDo(b *testing.B) {
+	benchmarkClient(b, func(c *Client, req *http.Request) {
+		if _, _, err := c.Do(context.Background(), req); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkDoBuffer(b *testing.B) {
+	benchmarkClient(b, func(c *Client, req *http.Request) {
+		buf, _, err := c.DoBuffer(context.Background(), req)
+		if err != nil {
+			b.Fatal(err)
+		}
+		buf.Release()
+	})
+}
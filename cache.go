@@ -0,0 +1,335 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStore stores cached responses for WithCache. Implementations must be
+// safe for concurrent use and must treat entries as immutable.
+type CacheStore interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+}
+
+// CacheEntry is a response stored by the cache.
+type CacheEntry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// VaryHeader holds the request header values named by the response's
+	// Vary header, used to match later requests.
+	VaryHeader http.Header
+
+	// StoredAt is when the response was received or last revalidated.
+	StoredAt time.Time
+}
+
+// cacheableStatus lists the status codes that may be stored.
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// httpCache implements the caching layer in front of the retry loop.
+type httpCache struct {
+	store                CacheStore
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+}
+
+type fetchFunc func(ctx context.Context, req *http.Request) (*Result, error)
+
+func (hc *httpCache) do(ctx context.Context, req *http.Request, fetch fetchFunc) (*Result, error) {
+	key := req.URL.String()
+	if req.Method != http.MethodGet {
+		res, err := fetch(ctx, req)
+		if err == nil && !isSafeMethod(req.Method) {
+			hc.store.Delete(key)
+		}
+		return res, err
+	}
+
+	reqCC := parseCacheControl(req.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return fetch(ctx, req)
+	}
+
+	entry, ok := hc.store.Get(key)
+	if ok && !entry.matches(req) {
+		ok = false
+	}
+	if ok {
+		if _, noCache := reqCC["no-cache"]; !noCache {
+			now := time.Now()
+			if entry.fresh(now) {
+				return entry.result(), nil
+			}
+			if entry.staleness(now) <= entry.staleWindow("stale-while-revalidate", hc.staleWhileRevalidate) {
+				hc.revalidate(ctx, key, entry, req, fetch)
+				return entry.result(), nil
+			}
+		}
+		req = entry.conditional(ctx, req)
+	}
+
+	res, err := fetch(ctx, req)
+	if err != nil {
+		if ok && errors.Is(err, ErrOffline) {
+			return entry.result(), nil // any stored entry beats no answer
+		}
+		if ok && (res.StatusCode == 0 || res.StatusCode >= 500) &&
+			entry.staleness(time.Now()) <= entry.staleWindow("stale-if-error", hc.staleIfError) {
+			return entry.result(), nil
+		}
+		return res, err
+	}
+	return hc.update(key, entry, ok, req, res), nil
+}
+
+// update stores the outcome of an upstream fetch and returns the Result to
+// hand to the caller: the cached entry on 304, res otherwise.
+func (hc *httpCache) update(key string, entry *CacheEntry, ok bool, req *http.Request, res *Result) *Result {
+	if ok && res.StatusCode == http.StatusNotModified {
+		entry = entry.revalidated(res.Header)
+		hc.store.Set(key, entry)
+		cached := entry.result()
+		cached.Attempts, cached.Duration = res.Attempts, res.Duration
+		return cached
+	}
+	if e := newCacheEntry(req, res); e != nil {
+		hc.store.Set(key, e)
+	}
+	return res
+}
+
+// revalidate refreshes a stale entry in the background, at most once per
+// key at a time. The refresh goes through the normal pipeline, so it waits
+// for the rate limiter like any other request.
+func (hc *httpCache) revalidate(ctx context.Context, key string, entry *CacheEntry, req *http.Request, fetch fetchFunc) {
+	hc.mu.Lock()
+	if hc.refreshing[key] {
+		hc.mu.Unlock()
+		return
+	}
+	if hc.refreshing == nil {
+		hc.refreshing = make(map[string]bool)
+	}
+	hc.refreshing[key] = true
+	hc.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	req = entry.conditional(ctx, req.WithContext(ctx))
+	go func() {
+		defer func() {
+			hc.mu.Lock()
+			delete(hc.refreshing, key)
+			hc.mu.Unlock()
+		}()
+		if res, err := fetch(ctx, req); err == nil {
+			hc.update(key, entry, true, req, res)
+		}
+	}()
+}
+
+// newCacheEntry returns an entry for res, or nil if it may not be stored.
+func newCacheEntry(req *http.Request, res *Result) *CacheEntry {
+	if !cacheableStatus[res.StatusCode] {
+		return nil
+	}
+	if _, ok := parseCacheControl(req.Header)["no-store"]; ok {
+		return nil
+	}
+	cc := parseCacheControl(res.Header)
+	if _, ok := cc["no-store"]; ok {
+		return nil
+	}
+	_, hasMaxAge := cc["max-age"]
+	hasValidator := res.Header.Get("ETag") != "" || res.Header.Get("Last-Modified") != ""
+	if !hasMaxAge && res.Header.Get("Expires") == "" && !hasValidator {
+		return nil
+	}
+
+	vary := http.Header{}
+	for _, v := range res.Header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil
+			}
+			if name != "" {
+				vary[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+			}
+		}
+	}
+
+	return &CacheEntry{
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Body:       bytes.Clone(res.Body),
+		VaryHeader: vary,
+		StoredAt:   time.Now(),
+	}
+}
+
+// matches reports whether req selects the same Vary header values.
+func (e *CacheEntry) matches(req *http.Request) bool {
+	for name, values := range e.VaryHeader {
+		if strings.Join(req.Header.Values(name), ",") != strings.Join(values, ",") {
+			return false
+		}
+	}
+	return true
+}
+
+// fresh reports whether the entry can be served without revalidation.
+func (e *CacheEntry) fresh(now time.Time) bool {
+	return e.age(now) < e.lifetime()
+}
+
+// lifetime is the freshness lifetime from max-age or Expires.
+func (e *CacheEntry) lifetime() time.Duration {
+	cc := parseCacheControl(e.Header)
+	if _, ok := cc["no-cache"]; ok {
+		return 0
+	}
+	if v, ok := cc["max-age"]; ok {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+		return 0
+	}
+	if exp := e.Header.Get("Expires"); exp != "" {
+		expires, err := http.ParseTime(exp)
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(e.Header.Get("Date"))
+		if err != nil {
+			date = e.StoredAt
+		}
+		return expires.Sub(date)
+	}
+	return 0
+}
+
+// staleness is how long the entry has been stale (negative while fresh).
+func (e *CacheEntry) staleness(now time.Time) time.Duration {
+	return e.age(now) - e.lifetime()
+}
+
+// staleWindow returns how long the entry may be served stale under the
+// given directive: the response's own value if present, otherwise the
+// client default. must-revalidate forbids serving stale at all, and
+// no-cache rules out stale-while-revalidate.
+func (e *CacheEntry) staleWindow(directive string, fallback time.Duration) time.Duration {
+	cc := parseCacheControl(e.Header)
+	if _, ok := cc["must-revalidate"]; ok {
+		return -1
+	}
+	if _, ok := cc["no-cache"]; ok && directive == "stale-while-revalidate" {
+		return -1
+	}
+	if v, ok := cc[directive]; ok {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	if fallback <= 0 {
+		return -1
+	}
+	return fallback
+}
+
+// age is the entry's current age: the upstream Age plus time spent in the cache.
+func (e *CacheEntry) age(now time.Time) time.Duration {
+	var age time.Duration
+	if secs, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil && secs > 0 {
+		age = time.Duration(secs) * time.Second
+	}
+	return age + now.Sub(e.StoredAt)
+}
+
+// conditional returns a copy of req carrying the entry's validators.
+func (e *CacheEntry) conditional(ctx context.Context, req *http.Request) *http.Request {
+	etag, lastModified := e.Header.Get("ETag"), e.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return req
+	}
+	req = req.Clone(ctx)
+	if etag != "" && req.Header.Get("If-None-Match") == "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" && req.Header.Get("If-Modified-Since") == "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	return req
+}
+
+// revalidated returns a copy of the entry updated with the headers of a 304.
+func (e *CacheEntry) revalidated(header http.Header) *CacheEntry {
+	updated := *e
+	updated.Header = e.Header.Clone()
+	for k, v := range header {
+		if k == "Content-Length" {
+			continue
+		}
+		updated.Header[k] = v
+	}
+	updated.StoredAt = time.Now()
+	return &updated
+}
+
+// result converts the entry into a Result for the caller.
+func (e *CacheEntry) result() *Result {
+	return &Result{
+		Body:       bytes.Clone(e.Body),
+		StatusCode: e.StatusCode,
+		Header:     e.Header.Clone(),
+		Cached:     true,
+	}
+}
+
+// parseCacheControl splits a Cache-Control header into lower-cased
+// directives and their (unquoted) values.
+func parseCacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, v := range h.Values("Cache-Control") {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, val, _ := strings.Cut(part, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(val), `"`)
+		}
+	}
+	return cc
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package resilient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheServesFreshResponses(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("fresh"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithCache(NewMemoryCache(10)))
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		body, status, err := c.Get(context.Background(), "/")
+		if err != nil || status != 200 || string(body) != "fresh" {
+			t.Fatalf("unexpected: status=%d body=%s err=%v", status, body, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", n)
+	}
+	if s := c.Stats(); s.TotalRequests != 1 {
+		t.Fatalf("cache hits must not count as upstream requests: %+v", s)
+	}
+}
+
+func TestCacheRevalidatesStaleResponses(t *testing.T) {
+	var calls, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("body"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithCache(NewMemoryCache(10)))
+	defer c.Close()
+
+	c.Get(context.Background(), "/")
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+	res, err := c.DoResult(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || string(res.Body) != "body" || !res.Cached {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if calls.Load() != 2 || notModified.Load() != 1 {
+		t.Fatalf("expected one revalidation, got calls=%d 304s=%d", calls.Load(), notModified.Load())
+	}
+}
+
+func TestCacheInvalidatedByUnsafeMethod(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithCache(NewMemoryCache(10)))
+	defer c.Close()
+
+	c.Get(context.Background(), "/item")
+	c.Post(context.Background(), "/item", "text/plain", strings.NewReader("update"))
+	c.Get(context.Background(), "/item")
+
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("expected POST to invalidate the entry, got %d calls", n)
+	}
+}
+
+func TestCacheFreshness(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		header http.Header
+		fresh  bool
+	}{
+		{"max-age", http.Header{"Cache-Control": {"max-age=60"}}, true},
+		{"max-age with age", http.Header{"Cache-Control": {"max-age=60"}, "Age": {"120"}}, false},
+		{"no-cache", http.Header{"Cache-Control": {"max-age=60, no-cache"}}, false},
+		{"expires", http.Header{
+			"Date":    {now.UTC().Format(http.TimeFormat)},
+			"Expires": {now.Add(time.Hour).UTC().Format(http.TimeFormat)},
+		}, true},
+		{"expired", http.Header{
+			"Date":    {now.UTC().Format(http.TimeFormat)},
+			"Expires": {now.Add(-time.Hour).UTC().Format(http.TimeFormat)},
+		}, false},
+	}
+	for _, tt := range tests {
+		e := &CacheEntry{Header: tt.header, StoredAt: now}
+		if got := e.fresh(now); got != tt.fresh {
+			t.Errorf("%s: fresh = %v, want %v", tt.name, got, tt.fresh)
+		}
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	m := NewMemoryCache(2)
+	m.Set("a", &CacheEntry{})
+	m.Set("b", &CacheEntry{})
+	m.Get("a")
+	m.Set("c", &CacheEntry{})
+
+	if _, ok := m.Get("b"); ok {
+		t.Fatal("expected least recently used entry to be evicted")
+	}
+	if _, ok := m.Get("a"); !ok {
+		t.Fatal("expected recently used entry to be kept")
+	}
+	if m.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", m.Len())
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=0")
+		fmt.Fprintf(w, "v%d", n)
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithCache(NewMemoryCache(10)),
+		WithStaleWhileRevalidate(time.Minute),
+	)
+	defer c.Close()
+
+	c.Get(context.Background(), "/")
+	body, _, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "v1" {
+		t.Fatalf("expected stale body v1, got %s", body)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	body, _, _ = c.Get(context.Background(), "/")
+	if string(body) != "v2" && string(body) != "v3" {
+		t.Fatalf("expected refreshed body, got %s", body)
+	}
+}
+
+func TestCacheStaleIfError(t *testing.T) {
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(500)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Write([]byte("good"))
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithRetry(0, 0),
+		WithCache(NewMemoryCache(10)),
+		WithStaleIfError(time.Minute),
+	)
+	defer c.Close()
+
+	c.Get(context.Background(), "/")
+	failing.Store(true)
+
+	body, status, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != 200 || string(body) != "good" {
+		t.Fatalf("expected stale response, got status=%d body=%s", status, body)
+	}
+
+	// Without stale-if-error the failure surfaces.
+	plain := New(WithBaseURL(srv.URL), WithRetry(0, 0), WithCache(NewMemoryCache(10)))
+	defer plain.Close()
+	if _, _, err := plain.Get(context.Background(), "/"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,250 @@
+// Package chaos injects faults into a resilient.Client's requests, so teams
+// can check in staging that retries, breakers and alerting behave as
+// intended when upstreams misbehave:
+//
+//	inj := chaos.New(
+//	    chaos.WithReset(0.05),
+//	    chaos.WithStatus(http.StatusTooManyRequests, 0.1),
+//	    chaos.WithLatency(0.2, 100*time.Millisecond, 2*time.Second),
+//	)
+//	client := resilient.New(resilient.WithBaseURL(url), inj.Option())
+//
+// Each fault is rolled independently per attempt, in the order latency,
+// reset, statuses, truncation.
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+// Option configures an Injector.
+type Option func(*Injector)
+
+// WithReset fails a fraction p of attempts with a connection reset, before
+// they reach the server.
+func WithReset(p float64) Option {
+	return func(i *Injector) { i.reset = p }
+}
+
+// WithStatus answers a fraction p of attempts with status instead of
+// sending them. It may be given several times for different statuses.
+func WithStatus(status int, p float64) Option {
+	return func(i *Injector) { i.statuses = append(i.statuses, statusFault{status, p}) }
+}
+
+// WithLatency delays a fraction p of attempts by a random duration between
+// min and max.
+func WithLatency(p float64, min, max time.Duration) Option {
+	return func(i *Injector) { i.latency, i.minDelay, i.maxDelay = p, min, max }
+}
+
+// WithTruncation cuts a fraction p of response bodies in half, ending them
+// with io.ErrUnexpectedEOF as a dropped connection would.
+func WithTruncation(p float64) Option {
+	return func(i *Injector) { i.truncate = p }
+}
+
+// WithMatch limits fault injection to requests for which fn returns true.
+func WithMatch(fn func(*http.Request) bool) Option {
+	return func(i *Injector) { i.match = fn }
+}
+
+// WithSeed makes the fault sequence reproducible.
+func WithSeed(seed uint64) Option {
+	return func(i *Injector) { i.rng = rand.New(rand.NewPCG(seed, seed)) }
+}
+
+// statusFault is a status injected with probability p.
+type statusFault struct {
+	status int
+	p      float64
+}
+
+// Stats counts the faults injected so far.
+type Stats struct {
+	Resets      uint64
+	Statuses    uint64
+	Delays      uint64
+	Truncations uint64
+}
+
+// Injector is a transport middleware that injects faults. It is safe for
+// concurrent use.
+type Injector struct {
+	reset    float64
+	statuses []statusFault
+	latency  float64
+	minDelay time.Duration
+	maxDelay time.Duration
+	truncate float64
+	match    func(*http.Request) bool
+
+	disabled atomic.Bool
+
+	mu  sync.Mutex
+	rng *rand.Rand
+
+	resets      atomic.Uint64
+	injected    atomic.Uint64
+	delays      atomic.Uint64
+	truncations atomic.Uint64
+}
+
+// New returns an enabled Injector.
+func New(opts ...Option) *Injector {
+	i := &Injector{rng: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))}
+	for _, o := range opts {
+		o(i)
+	}
+	return i
+}
+
+// Option installs the Injector as a transport middleware.
+func (i *Injector) Option() resilient.Option {
+	return resilient.WithTransportMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return &transport{inj: i, next: next}
+	})
+}
+
+// Enable resumes fault injection after Disable.
+func (i *Injector) Enable() { i.disabled.Store(false) }
+
+// Disable passes every request through untouched until Enable.
+func (i *Injector) Disable() { i.disabled.Store(true) }
+
+// Stats returns the number of faults injected so far.
+func (i *Injector) Stats() Stats {
+	return Stats{
+		Resets:      i.resets.Load(),
+		Statuses:    i.injected.Load(),
+		Delays:      i.delays.Load(),
+		Truncations: i.truncations.Load(),
+	}
+}
+
+// roll reports whether an event of probability p happens.
+func (i *Injector) roll(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rng.Float64() < p
+}
+
+// delay returns a random duration between minDelay and maxDelay.
+func (i *Injector) delay() time.Duration {
+	if i.maxDelay <= i.minDelay {
+		return i.minDelay
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.minDelay + time.Duration(i.rng.Int64N(int64(i.maxDelay-i.minDelay)))
+}
+
+type transport struct {
+	inj  *Injector
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := t.inj
+	if i.disabled.Load() || (i.match != nil && !i.match(req)) {
+		return t.next.RoundTrip(req)
+	}
+
+	if i.roll(i.latency) {
+		i.delays.Add(1)
+		if err := sleep(req.Context(), i.delay()); err != nil {
+			closeBody(req)
+			return nil, err
+		}
+	}
+	if i.roll(i.reset) {
+		i.resets.Add(1)
+		closeBody(req)
+		return nil, fmt.Errorf("chaos: injected connection reset: %w", syscall.ECONNRESET)
+	}
+	for _, f := range i.statuses {
+		if i.roll(f.p) {
+			i.injected.Add(1)
+			closeBody(req)
+			return statusResponse(req, f.status), nil
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || !i.roll(i.truncate) {
+		return resp, err
+	}
+	i.truncations.Add(1)
+	limit := int64(0)
+	if resp.ContentLength > 0 {
+		limit = resp.ContentLength / 2
+	}
+	resp.Body = &truncatedBody{r: io.LimitReader(resp.Body, limit), c: resp.Body}
+	return resp, nil
+}
+
+// statusResponse builds an injected response with a short plain-text body.
+func statusResponse(req *http.Request, status int) *http.Response {
+	body := "chaos: injected " + strconv.Itoa(status)
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// truncatedBody ends a body early with io.ErrUnexpectedEOF.
+type truncatedBody struct {
+	r io.Reader
+	c io.Closer
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (b *truncatedBody) Close() error { return b.c.Close() }
+
+// closeBody closes the body of a request that won't be sent, as a
+// RoundTripper must.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("x", 100))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFaults(t *testing.T) {
+	srv := newServer(t)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name  string
+		opt   Option
+		check func(status int, err error) bool
+	}{
+		{"reset", WithReset(1), func(_ int, err error) bool { return errors.Is(err, syscall.ECONNRESET) }},
+		{"status", WithStatus(http.StatusInternalServerError, 1), func(status int, err error) bool {
+			return status == http.StatusInternalServerError && err != nil
+		}},
+		{"truncation", WithTruncation(1), func(_ int, err error) bool { return errors.Is(err, io.ErrUnexpectedEOF) }},
+	} {
+		inj := New(tc.opt)
+		c := resilient.New(resilient.WithBaseURL(srv.URL), resilient.WithRetry(0, 0), inj.Option())
+		_, status, err := c.Get(ctx, "/")
+		c.Close()
+		if !tc.check(status, err) {
+			t.Fatalf("%s: unexpected outcome %d %v", tc.name, status, err)
+		}
+	}
+}
+
+func TestLatencyAndStats(t *testing.T) {
+	srv := newServer(t)
+	inj := New(WithLatency(1, 30*time.Millisecond, 40*time.Millisecond), WithSeed(1))
+	c := resilient.New(resilient.WithBaseURL(srv.URL), inj.Option())
+	defer c.Close()
+
+	start := time.Now()
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 30*time.Millisecond {
+		t.Fatalf("expected injected latency, took %v", d)
+	}
+
+	// A deadline shorter than the delay fails the attempt.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.Get(ctx, "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if s := inj.Stats(); s.Delays != 2 {
+		t.Fatalf("expected 2 delays, got %+v", s)
+	}
+}
+
+func TestMatchAndDisable(t *testing.T) {
+	srv := newServer(t)
+	inj := New(WithReset(1), WithMatch(func(r *http.Request) bool { return r.URL.Path == "/flaky" }))
+	c := resilient.New(resilient.WithBaseURL(srv.URL), resilient.WithRetry(0, 0), inj.Option())
+	defer c.Close()
+
+	if _, _, err := c.Get(context.Background(), "/stable"); err != nil {
+		t.Fatalf("expected unmatched requests untouched, got %v", err)
+	}
+	if _, _, err := c.Get(context.Background(), "/flaky"); err == nil {
+		t.Fatal("expected an injected failure")
+	}
+	inj.Disable()
+	if _, _, err := c.Get(context.Background(), "/flaky"); err != nil {
+		t.Fatalf("expected no faults while disabled, got %v", err)
+	}
+	if s := inj.Stats(); s.Resets != 1 {
+		t.Fatalf("expected 1 reset, got %+v", s)
+	}
+}
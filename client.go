@@ -3,12 +3,14 @@ package resilient
 import (
 	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/rand"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,6 +25,38 @@ type Stats struct {
 	TotalRequests uint64
 	TotalErrors   uint64
 	RateLimited   uint64
+	Hedged        uint64
+
+	// ConcurrencyWaits counts attempts that had to wait for a free slot
+	// under WithMaxConcurrent.
+	ConcurrencyWaits uint64
+	// ConcurrencyLimit is the current adaptive concurrency limit, or 0 when
+	// WithAdaptiveConcurrency is not set.
+	ConcurrencyLimit int
+
+	// Shedded counts requests rejected with ErrShedded.
+	Shedded uint64
+
+	// DNSHits and DNSMisses count host lookups answered from, or missing
+	// in, the WithDNSCache cache.
+	DNSHits   uint64
+	DNSMisses uint64
+
+	// DroppedEvents counts events not delivered because the Events channel
+	// was full.
+	DroppedEvents uint64
+
+	// Latency is the response-time histogram, as returned by Client.Latency.
+	Latency LatencyStats
+
+	// TotalRetries counts attempts after the first, and TotalBackoffTime
+	// the time spent waiting between them.
+	TotalRetries     uint64
+	TotalBackoffTime time.Duration
+
+	// AdaptiveReductions counts rate-limiter slowdowns after a 429, one per
+	// limiter reduced.
+	AdaptiveReductions uint64
 }
 
 // StatsProvider exposes metrics for external collectors (Prometheus, OTel, etc.).
@@ -33,19 +67,81 @@ type StatsProvider interface {
 // Client is a resilient HTTP client with rate limiting, retry, and adaptive backoff.
 type Client struct {
 	httpClient *http.Client
-	limiter    *rate.Limiter
+	endpoints  *endpointPool
+	service    *servicePool
+	redact     map[string]bool
 	cfg        *config
+	derived    bool // created by With; the parent owns the shared state
+
+	*clientState
+}
+
+// clientState is the part of a Client shared with the clients derived from
+// it by With: limiters, pools, caches and statistics.
+type clientState struct {
+	limiter  RateLimiter
+	keys     *keyPool
+	digest   *digestAuth
+	proxies  *proxyPool
+	dns      *dnsCache
+	flights  *flightGroup
+	slots    chan struct{}
+	adaptive *concurrencyLimiter
+	queue    *priorityQueue
+	cache    *httpCache
+	memo     *memoCache
+	hosts    *hostGuards
 
 	mu            sync.Mutex
 	originalRate  rate.Limit
-	adaptiveTimer *time.Timer
+	adaptiveTimer Timer
+	hostLimiters  map[string]*scopedLimiter
+	hostSweepAt   int // host limiter count that triggers the next idle sweep
+	pathLimiters  []*pathLimiter
+	quotas        map[string]*quotaPacer
+	quotaSweepAt  int // pacer count that triggers the next idle sweep
+	latency       *latencyTracker
+	histogram     *latencyHistogram
+	slo           *sloTracker
+	split         *trafficSplit
+	healthTimer   Timer
+	outbox        *outbox
+	outboxTimer   Timer
+	auth          authToken
+	events        eventStream
+	inflight      *inflightSet
 	closed        bool
+	transportErr  error // options the transport can't honor; fails every call
 
 	totalReqs   atomic.Uint64
 	totalErrors atomic.Uint64
 	rateLimited atomic.Uint64
+	hedged      atomic.Uint64
+	slotWaits   atomic.Uint64
+	queued      atomic.Int64
+	shedded     atomic.Uint64
+	rpcID       atomic.Uint64
+	healthy     atomic.Bool
+	offline     atomic.Bool
+	retries     atomic.Uint64
+	backoffTime atomic.Int64
+	reductions  atomic.Uint64
+}
+
+// scopedLimiter is a token bucket scoped to a single request host or path
+// pattern.
+type scopedLimiter struct {
+	limiter       *rate.Limiter
+	original      rate.Limit
+	adaptiveTimer Timer
+	lastUsed      time.Time // per-host limiters only, for the idle sweep
 }
 
+// idleTTL is how long a per-host limiter or quota pacer may go unused
+// before it is dropped, so calls to many distinct hosts don't grow the
+// client without bound. A host called again gets a fresh one.
+const idleTTL = 10 * time.Minute
+
 // Compile-time interface check.
 var _ StatsProvider = (*Client)(nil)
 
@@ -55,46 +151,240 @@ func New(opts ...Option) *Client {
 	for _, o := range opts {
 		o(cfg)
 	}
+	if cfg.userAgent != "" && cfg.defaultHeaders.Get("User-Agent") == "" {
+		WithHeader("User-Agent", cfg.userAgent)(cfg)
+	}
+	if len(cfg.decoders) > 0 && cfg.defaultHeaders.Get("Accept-Encoding") == "" {
+		encodings := make([]string, 0, len(cfg.decoders))
+		for name := range cfg.decoders {
+			encodings = append(encodings, name)
+		}
+		sort.Strings(encodings)
+		WithHeader("Accept-Encoding", strings.Join(encodings, ", "))(cfg)
+	}
 
 	hc := cfg.httpClient
 	if hc == nil {
 		hc = &http.Client{Timeout: cfg.timeout}
 	}
+	proxies := newProxyPool(cfg)
+	dns := newDNSCache(cfg)
+	hc, transportErr := withTransport(hc, cfg, proxies, dns)
+	if cfg.jar != nil {
+		copied := *hc
+		copied.Jar = cfg.jar
+		hc = &copied
+	}
 
-	var lim *rate.Limiter
-	if cfg.rps > 0 {
+	var lim RateLimiter
+	originalRate := rate.Limit(cfg.rps)
+	switch {
+	case cfg.limiter != nil:
+		lim = cfg.limiter
+		originalRate = cfg.limiter.Limit()
+	case cfg.rps > 0:
 		lim = rate.NewLimiter(rate.Limit(cfg.rps), cfg.burst)
 	}
 
-	return &Client{
-		httpClient:   hc,
-		limiter:      lim,
-		cfg:          cfg,
-		originalRate: rate.Limit(cfg.rps),
+	var slots chan struct{}
+	var adaptive *concurrencyLimiter
+	switch {
+	case cfg.adaptiveInFlight:
+		adaptive = newConcurrencyLimiter(cfg.maxConcurrent)
+	case cfg.maxConcurrent > 0:
+		slots = make(chan struct{}, cfg.maxConcurrent)
+	}
+
+	var queue *priorityQueue
+	if cfg.priorityQueue {
+		queue = &priorityQueue{}
+	}
+
+	var flights *flightGroup
+	if cfg.singleflight {
+		flights = &flightGroup{}
+	}
+
+	var cache *httpCache
+	if cfg.cacheStore != nil {
+		cache = &httpCache{
+			store:                cfg.cacheStore,
+			staleWhileRevalidate: cfg.staleWhileRevalidate,
+			staleIfError:         cfg.staleIfError,
+		}
+	}
+
+	var latency *latencyTracker
+	if cfg.latencyTolerance > 0 {
+		latency = &latencyTracker{tolerance: cfg.latencyTolerance}
+	}
+
+	c := &Client{
+		httpClient: hc,
+		endpoints:  newEndpointPool(cfg),
+		service:    newServicePool(cfg),
+		redact:     redactedHeaders(cfg),
+		cfg:        cfg,
+		clientState: &clientState{
+			flights:      flights,
+			slots:        slots,
+			adaptive:     adaptive,
+			queue:        queue,
+			cache:        cache,
+			memo:         newMemoCache(cfg),
+			hosts:        newHostGuards(cfg),
+			latency:      latency,
+			histogram:    newLatencyHistogram(cfg.latencyBuckets),
+			slo:          newSLOTracker(cfg),
+			split:        newTrafficSplit(cfg),
+			outbox:       newOutbox(cfg),
+			limiter:      lim,
+			keys:         newKeyPool(cfg),
+			digest:       newDigestAuth(cfg),
+			proxies:      proxies,
+			dns:          dns,
+			pathLimiters: newPathLimiters(cfg),
+			originalRate: originalRate,
+			inflight:     newInflightSet(),
+			transportErr: transportErr,
+		},
 	}
+	c.startHealthCheck()
+	c.startOutbox()
+	return c
 }
 
 // Close releases resources held by the client (adaptive timer, etc.).
+// Later calls fail with ErrClientClosed; calls in flight are left to
+// finish, see Shutdown to wait for them.
 func (c *Client) Close() {
+	if c.derived {
+		return
+	}
+	c.inflight.drain()
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
 	c.closed = true
+	c.events.close()
+	if c.queue != nil {
+		c.queue.close()
+	}
+	if len(c.cfg.middleware) > 0 {
+		if cl, ok := c.httpClient.Transport.(io.Closer); ok {
+			cl.Close()
+		}
+	}
 	if c.adaptiveTimer != nil {
 		c.adaptiveTimer.Stop()
 		c.adaptiveTimer = nil
 	}
+	if c.healthTimer != nil {
+		c.healthTimer.Stop()
+		c.healthTimer = nil
+	}
+	if c.outboxTimer != nil {
+		c.outboxTimer.Stop()
+		c.outboxTimer = nil
+	}
+	for _, hl := range c.hostLimiters {
+		if hl.adaptiveTimer != nil {
+			hl.adaptiveTimer.Stop()
+			hl.adaptiveTimer = nil
+		}
+	}
+	for _, pl := range c.pathLimiters {
+		if pl.adaptiveTimer != nil {
+			pl.adaptiveTimer.Stop()
+			pl.adaptiveTimer = nil
+		}
+	}
 }
 
 // Stats returns a snapshot of request statistics.
 func (c *Client) Stats() Stats {
-	return Stats{
+	s := Stats{
 		TotalRequests: c.totalReqs.Load(),
 		TotalErrors:   c.totalErrors.Load(),
 		RateLimited:   c.rateLimited.Load(),
+		Hedged:        c.hedged.Load(),
+
+		ConcurrencyWaits: c.slotWaits.Load(),
+		ConcurrencyLimit: c.concurrencyLimit(),
+		Shedded:          c.shedded.Load(),
+		DroppedEvents:    c.events.dropped.Load(),
+		Latency:          c.histogram.snapshot(),
+
+		TotalRetries:       c.retries.Load(),
+		TotalBackoffTime:   time.Duration(c.backoffTime.Load()),
+		AdaptiveReductions: c.reductions.Load(),
+	}
+	if c.dns != nil {
+		s.DNSHits, s.DNSMisses = c.dns.hits.Load(), c.dns.misses.Load()
 	}
+	return s
 }
 
-// SetRateLimit dynamically adjusts the rate limit.
+// ResetStats zeroes the counters and the latency histogram reported by
+// Stats. Requests in flight may be counted on either side of the reset.
+func (c *Client) ResetStats() {
+	for _, n := range []*atomic.Uint64{
+		&c.totalReqs, &c.totalErrors, &c.rateLimited, &c.hedged,
+		&c.slotWaits, &c.shedded, &c.events.dropped, &c.retries, &c.reductions,
+	} {
+		n.Store(0)
+	}
+	c.backoffTime.Store(0)
+	if c.dns != nil {
+		c.dns.hits.Store(0)
+		c.dns.misses.Store(0)
+	}
+	c.histogram.reset()
+}
+
+// StatsSince returns the counts accumulated since prev, an earlier result
+// of Stats, so periodic reporters can emit per-interval figures:
+//
+//	prev := client.Stats()
+//	for range time.Tick(time.Minute) {
+//		delta := client.StatsSince(prev)
+//		prev = client.Stats()
+//		report(delta)
+//	}
+//
+// ConcurrencyLimit is the current value, and so is Latency.Max, which a
+// histogram cannot subtract. A counter that went down because of
+// ResetStats is reported as counted from zero.
+func (c *Client) StatsSince(prev Stats) Stats {
+	s := c.Stats()
+	s.TotalRequests = delta(s.TotalRequests, prev.TotalRequests)
+	s.TotalErrors = delta(s.TotalErrors, prev.TotalErrors)
+	s.RateLimited = delta(s.RateLimited, prev.RateLimited)
+	s.Hedged = delta(s.Hedged, prev.Hedged)
+	s.ConcurrencyWaits = delta(s.ConcurrencyWaits, prev.ConcurrencyWaits)
+	s.Shedded = delta(s.Shedded, prev.Shedded)
+	s.DNSHits = delta(s.DNSHits, prev.DNSHits)
+	s.DNSMisses = delta(s.DNSMisses, prev.DNSMisses)
+	s.DroppedEvents = delta(s.DroppedEvents, prev.DroppedEvents)
+	s.Latency = s.Latency.since(prev.Latency)
+	s.TotalRetries = delta(s.TotalRetries, prev.TotalRetries)
+	s.TotalBackoffTime = time.Duration(delta(uint64(s.TotalBackoffTime), uint64(prev.TotalBackoffTime)))
+	s.AdaptiveReductions = delta(s.AdaptiveReductions, prev.AdaptiveReductions)
+	return s
+}
+
+// delta returns cur-prev for a counter, or cur if it was reset in between.
+func delta(cur, prev uint64) uint64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// SetRateLimit dynamically adjusts the rate limit. The burst is applied
+// only if the limiter supports it (a SetBurst(int) method).
 func (c *Client) SetRateLimit(rps float64, burst int) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -103,7 +393,9 @@ func (c *Client) SetRateLimit(rps float64, burst int) {
 		c.limiter = rate.NewLimiter(newRate, burst)
 	} else {
 		c.limiter.SetLimit(newRate)
-		c.limiter.SetBurst(burst)
+		if b, ok := c.limiter.(interface{ SetBurst(int) }); ok {
+			b.SetBurst(burst)
+		}
 	}
 	c.originalRate = newRate
 }
@@ -111,78 +403,419 @@ func (c *Client) SetRateLimit(rps float64, burst int) {
 // Do executes an HTTP request with rate limiting, retry, and adaptive backoff.
 // It returns the response body, HTTP status code, and any error.
 func (c *Client) Do(ctx context.Context, req *http.Request) ([]byte, int, error) {
-	if err := c.waitRateLimit(ctx); err != nil {
-		return nil, 0, fmt.Errorf("resilient: rate limit wait: %w", err)
+	res, err := c.do(ctx, req)
+	return res.Body, res.StatusCode, err
+}
+
+// do executes req, consulting the response cache when one is configured
+// and the fallback when it fails. The returned Result is never nil.
+func (c *Client) do(ctx context.Context, req *http.Request) (*Result, error) {
+	req = c.withDefaultHeaders(req)
+	fetch := c.fetch
+	if c.cache != nil {
+		fetch = func(ctx context.Context, req *http.Request) (*Result, error) {
+			return c.cache.do(ctx, req, c.fetch)
+		}
+	}
+	var res *Result
+	var err error
+	if c.memo != nil {
+		res, err = c.memo.do(ctx, req, fetch)
+	} else {
+		res, err = fetch(ctx, req)
+	}
+	if err != nil && c.cfg.fallback != nil && ctx.Err() == nil && unrecoverable(err) {
+		res, err = c.fallback(ctx, req, res, err)
+	}
+	c.complete(req, res, err)
+	return res, err
+}
+
+// complete runs the WithOnComplete callbacks for a finished call.
+func (c *Client) complete(req *http.Request, res *Result, err error) {
+	for _, fn := range c.cfg.onComplete {
+		fn(req, res, err)
+	}
+}
+
+// withDefaultHeaders returns req with any missing default headers added.
+// The caller's request is left untouched.
+func (c *Client) withDefaultHeaders(req *http.Request) *http.Request {
+	var header http.Header
+	for k, v := range c.cfg.defaultHeaders {
+		if _, ok := req.Header[k]; ok {
+			continue
+		}
+		if header == nil {
+			header = req.Header.Clone()
+			if header == nil {
+				header = http.Header{}
+			}
+		}
+		header[k] = v
+	}
+	if header == nil {
+		return req
+	}
+	r := req.WithContext(req.Context())
+	r.Header = header
+	return r
+}
+
+// fetch executes req upstream, collapsing identical concurrent GETs when
+// singleflight is enabled. A caller that joined a call canceled by its
+// first caller's context runs its own while its context is still live.
+func (c *Client) fetch(ctx context.Context, req *http.Request) (*Result, error) {
+	if c.flights != nil && req.Method == http.MethodGet {
+		res, err, shared := c.flights.do(flightKey(req), func() (*Result, error) {
+			return c.execute(ctx, req, nil)
+		})
+		if !shared || ctx.Err() != nil || !(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			return res, err
+		}
+	}
+	return c.execute(ctx, req, nil)
+}
+
+// responseSink consumes the body of a successful response in place of
+// buffering it into Result.Body.
+type responseSink func(resp *http.Response) error
+
+// execute runs the retry loop for req. With a sink, the body of the final
+// successful response is streamed to it instead of being buffered. The
+// returned Result is never nil.
+func (c *Client) execute(ctx context.Context, req *http.Request, sink responseSink) (res *Result, err error) {
+	res = &Result{}
+	ctx, done, err := c.inflight.add(ctx)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return res, err
+	}
+	defer done()
+	if c.transportErr != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return res, c.transportErr
+	}
+	if c.offline.Load() {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return res, ErrOffline
+	}
+	req, route := c.split.route(req)
+	start := c.cfg.clock.Now()
+	var lastReq *http.Request // the last attempt, for WithCurlOnError
+	defer func() {
+		res.Duration = c.since(start)
+		if route != nil {
+			route.observe(res.Duration, err)
+		}
+		if err != nil && c.cfg.curlOnError && lastReq != nil {
+			err = &CurlError{Command: curlCommand(lastReq, c.redact), err: err}
+		}
+		if c.cfg.logger != nil {
+			c.logComplete(req, res, err)
+		}
+		c.observeSLO(err)
+		// Buffered calls are reported by do, after the cache, memo and
+		// fallback have had their say.
+		if sink != nil {
+			c.complete(req, res, err)
+		}
+	}()
+
+	// Bound the whole call, including in-flight attempts, by the elapsed-time budget.
+	parent := ctx
+	if c.cfg.maxElapsedTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.maxElapsedTime)
+		defer cancel()
+	}
+
+	// tokens are the rate-limit tokens of the next attempt, refunded when
+	// it fails before reaching the network.
+	tokens, err := c.waitRateLimit(ctx, req.URL)
+	if err != nil {
+		if c.cfg.maxElapsedTime > 0 && parent.Err() == nil {
+			return res, c.elapsedErr(err)
+		}
+		return res, fmt.Errorf("%w: %w", ErrRateLimitWait, err)
 	}
 
 	c.totalReqs.Add(1)
 
 	var (
-		lastErr    error
-		lastStatus int
-		bodyBytes  []byte
+		lastErr        error
+		lastStatus     int
+		lastRetryAfter time.Duration
 	)
 
-	// Capture the body for retries if it's non-nil.
-	if req.Body != nil && req.Body != http.NoBody {
-		var err error
-		bodyBytes, err = io.ReadAll(req.Body)
+	// Re-create the body for each attempt with req.GetBody when possible;
+	// otherwise buffer it once so it can be replayed.
+	var newBody func() (io.ReadCloser, error)
+	contentLength := req.ContentLength
+	switch {
+	case req.GetBody != nil:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		newBody = req.GetBody
+	case req.Body != nil && req.Body != http.NoBody:
+		bodyBytes, err := io.ReadAll(req.Body)
 		req.Body.Close()
 		if err != nil {
-			return nil, 0, fmt.Errorf("resilient: read request body: %w", err)
+			tokens.refund()
+			return res, fmt.Errorf("resilient: read request body: %w", err)
+		}
+		newBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(bodyBytes)), nil
+		}
+		contentLength = int64(len(bodyBytes))
+	}
+
+	// With failover endpoints or a service, each attempt is sent to the
+	// currently healthy base URL.
+	var ep *endpoint
+	rel, failover := c.endpoints.relative(req.URL.String())
+	var discover bool
+	if !failover {
+		rel, discover = c.service.relative(req.URL.String())
+	}
+
+	// newAttempt clones the request for each attempt (and each hedge).
+	newAttempt := func(ctx context.Context) (*http.Request, error) {
+		clone := req.Clone(ctx)
+		if ep != nil {
+			if u, err := url.Parse(ep.base + rel); err == nil {
+				clone.URL, clone.Host = u, u.Host
+			}
+		}
+		if newBody != nil {
+			body, err := newBody()
+			if err != nil {
+				// Surface the error as a failed send.
+				body = io.NopCloser(errReader{err})
+			}
+			var r io.Reader = body
+			if fn := c.uploadProgress(ctx); fn != nil {
+				// Each attempt starts over, so progress restarts from zero.
+				r = &progressReader{r: body, total: contentLength, fn: fn}
+			}
+			clone.Body = readCloser{r, body}
+			clone.ContentLength = contentLength
+			clone.GetBody = newBody
+		}
+		c.setBearer(clone)
+		c.setAPIKey(clone)
+		if c.cfg.requestHook != nil {
+			c.cfg.requestHook(clone)
+		}
+		if auth, _ := c.auth.get(); auth != "" {
+			clone.Header.Set("Authorization", auth)
+		}
+		if c.digest != nil {
+			c.digest.authorize(clone)
+		}
+		if c.cfg.signer != nil {
+			if err := c.cfg.signer.Sign(clone); err != nil {
+				if clone.Body != nil {
+					clone.Body.Close()
+				}
+				return nil, &signError{err}
+			}
 		}
+		if c.cfg.curlOnError {
+			lastReq = clone.Clone(clone.Context()) // the transport may still touch clone
+		}
+		return clone, nil
+	}
+
+	// history records failed attempts for RetryError.
+	var history []Attempt
+	fail := func(status int, sent time.Time, err error) {
+		history = append(history, Attempt{StatusCode: status, Err: err, Duration: c.since(sent)})
 	}
 
-	for attempt := 0; attempt <= c.cfg.maxRetries; attempt++ {
+	// An auth refresh earns the call one extra, immediate attempt.
+	retries, skipBackoff := c.cfg.maxRetries, false
+	for attempt := 0; attempt <= retries; attempt++ {
 		if attempt > 0 {
-			backoff := c.backoffDuration(attempt, lastStatus)
-			select {
-			case <-ctx.Done():
-				return nil, 0, ctx.Err()
-			case <-time.After(backoff):
+			if c.offline.Load() {
+				return res, ErrOffline // gone offline since the last attempt
+			}
+			res.StatusCode, res.Header, res.Redirects = 0, nil, nil
+			c.retries.Add(1)
+			var backoff time.Duration
+			if !skipBackoff {
+				backoff = c.backoffDuration(attempt, lastRetryAfter)
+			}
+			if n := len(history); n > 0 {
+				history[n-1].Backoff = backoff
+				c.emit(Event{Type: EventRetry, Attempt: attempt + 1, StatusCode: history[n-1].StatusCode, Err: history[n-1].Err, Wait: backoff}, req)
 			}
-			if err := c.waitRateLimit(ctx); err != nil {
-				return nil, 0, fmt.Errorf("resilient: rate limit wait: %w", err)
+			if !skipBackoff {
+				if c.cfg.maxElapsedTime > 0 && c.since(start)+backoff > c.cfg.maxElapsedTime {
+					return res, c.elapsedErr(lastErr)
+				}
+				select {
+				case <-ctx.Done():
+					if parent.Err() == nil {
+						return res, c.elapsedErr(lastErr)
+					}
+					return res, ctx.Err()
+				case <-c.cfg.clock.After(backoff):
+				}
+				c.backoffTime.Add(int64(backoff))
+			}
+			if tokens, err = c.waitRateLimit(ctx, req.URL); err != nil {
+				if c.cfg.maxElapsedTime > 0 && parent.Err() == nil {
+					return res, c.elapsedErr(err)
+				}
+				return res, fmt.Errorf("%w: %w", ErrRateLimitWait, err)
 			}
 		}
+		res.Attempts = attempt + 1
 
-		// Clone the request for each attempt.
-		clone := req.Clone(ctx)
-		if bodyBytes != nil {
-			clone.Body = io.NopCloser(bytes.NewReader(bodyBytes))
-			clone.ContentLength = int64(len(bodyBytes))
+		switch {
+		case failover:
+			ep = c.endpoints.pick()
+		case discover:
+			var err error
+			if ep, err = c.service.pick(ctx); err != nil {
+				tokens.refund()
+				return res, err
+			}
 		}
 
-		if c.cfg.requestHook != nil {
-			c.cfg.requestHook(clone)
+		guard := c.hosts.guard(attemptHost(req, ep))
+		if err := guard.allow(); err != nil {
+			tokens.refund()
+			c.totalErrors.Add(1)
+			return res, err
+		}
+		waited, err := guard.acquire(ctx)
+		if waited {
+			c.slotWaits.Add(1)
+		}
+		if err == nil {
+			if err = c.acquireSlot(ctx); err != nil {
+				guard.release()
+			}
+		}
+		if err != nil {
+			tokens.refund()
+			guard.record(nil, nil, true)
+			if c.cfg.maxElapsedTime > 0 && parent.Err() == nil {
+				return res, c.elapsedErr(err)
+			}
+			return res, fmt.Errorf("resilient: concurrency wait: %w", err)
 		}
 
-		resp, err := c.httpClient.Do(clone)
+		_, authGen := c.auth.get()
+		skipBackoff = false
+		c.emit(Event{Type: EventAttempt, Attempt: attempt + 1}, req)
+		sent := c.cfg.clock.Now()
+		resp, err := c.send(ctx, req.URL, newAttempt)
+		var signErr *signError
+		if errors.As(err, &signErr) {
+			// Nothing was sent: a local failure, not the endpoint's.
+			tokens.refund()
+			guard.record(nil, nil, true)
+			guard.release()
+			c.abandonSlot()
+			c.totalErrors.Add(1)
+			return res, fmt.Errorf("resilient: %w", err)
+		}
+		// An attempt cut short by the caller says nothing about the endpoint.
+		switch {
+		case ctx.Err() != nil:
+		case failover:
+			c.endpoints.report(ep, resp, err)
+		case discover:
+			c.service.report(ep, resp, err)
+		}
+		if guard.record(resp, err, err != nil && ctx.Err() != nil) {
+			c.emit(Event{Type: EventCircuitOpen, Host: guard.host}, req)
+		}
 		if err != nil {
+			guard.release()
+			c.releaseSlot(c.since(sent), ctx.Err() == nil)
 			c.totalErrors.Add(1)
+			lastRetryAfter = 0
 			lastErr = fmt.Errorf("resilient: http request: %w", err)
-			if c.shouldRetry(attempt, nil, err) {
+			fail(0, sent, lastErr)
+			if ctx.Err() != nil && parent.Err() == nil {
+				return res, c.elapsedErr(lastErr)
+			}
+			if c.shouldRetry(req, attempt, nil, err) {
 				continue
 			}
-			return nil, 0, lastErr
+			if c.exhausted(req, attempt, nil, err) {
+				return res, c.maxRetriesErr(lastErr, history)
+			}
+			return res, lastErr
 		}
 
 		if c.cfg.responseHook != nil {
 			c.cfg.responseHook(resp)
 		}
+		c.observeQuota(req, resp.Header)
+		if c.keys != nil {
+			c.keys.observe(resp, c.cfg.clock.Now())
+		}
+		c.observeLatency(req.URL, c.since(sent))
 
-		respBody, err := io.ReadAll(io.LimitReader(resp.Body, c.cfg.maxResponseSize))
+		retry := c.shouldRetry(req, attempt, resp, nil)
+		var respBody []byte
+		if err = c.decompress(resp); err == nil {
+			if sink != nil && !retry && resp.StatusCode < 400 {
+				err = sink(resp)
+			} else {
+				respBody, err = readBody(resp, c.cfg.maxResponseSize)
+				if retry && errors.Is(err, ErrResponseTooLarge) {
+					err = nil // the body is discarded anyway
+				}
+			}
+		}
 		resp.Body.Close()
+		guard.release()
+		c.releaseSlot(c.since(sent), isOverload(resp.StatusCode))
+		res.StatusCode = resp.StatusCode
+		res.Header = resp.Header
+		res.Redirects = RedirectChain(resp)
 		if err != nil {
 			c.totalErrors.Add(1)
-			return nil, resp.StatusCode, fmt.Errorf("resilient: read response: %w", err)
+			res.Body = respBody
+			return res, fmt.Errorf("resilient: read response: %w", err)
 		}
 
 		lastStatus = resp.StatusCode
 
-		if c.shouldRetry(attempt, resp, nil) {
+		if resp.StatusCode == http.StatusUnauthorized && retries == c.cfg.maxRetries {
+			reauth, err := c.reauthenticate(ctx, resp, authGen)
+			if reauth || err != nil {
+				c.totalErrors.Add(1)
+			}
+			if err != nil {
+				return res, err
+			}
+			if reauth {
+				retries++
+				skipBackoff = true
+				lastErr = fmt.Errorf("resilient: HTTP %d on %s %s", resp.StatusCode, req.Method, redactURL(req.URL, c.redact).Redacted())
+				fail(resp.StatusCode, sent, lastErr)
+				continue
+			}
+		}
+
+		if retry {
 			if resp.StatusCode == http.StatusTooManyRequests {
 				c.rateLimited.Add(1)
+				res.RateLimited++
 				if c.cfg.onRateLimited != nil {
 					c.cfg.onRateLimited(req)
 				}
@@ -191,100 +824,507 @@ func (c *Client) Do(ctx context.Context, req *http.Request) ([]byte, int, error)
 			if c.cfg.onError != nil {
 				c.cfg.onError(resp.StatusCode, req)
 			}
-			c.reduceRateLimit()
-			// Store retry-after for next iteration's backoff calc.
-			if ra := parseRetryAfter(resp.Header.Get("Retry-After")); ra > 0 {
-				lastStatus = resp.StatusCode // keep for backoff
+			if c.adaptiveTrigger(resp.StatusCode) {
+				c.reduceRateLimit(req.URL)
 			}
-			lastErr = fmt.Errorf("resilient: HTTP %d on %s %s", resp.StatusCode, req.Method, req.URL)
+			// Store retry-after for next iteration's backoff calc.
+			lastRetryAfter = retryAfterAt(resp.Header.Get("Retry-After"), c.cfg.clock.Now())
+			lastErr = fmt.Errorf("resilient: HTTP %d on %s %s", resp.StatusCode, req.Method, redactURL(req.URL, c.redact).Redacted())
+			fail(resp.StatusCode, sent, lastErr)
 			continue
 		}
 
-		if resp.StatusCode >= 400 {
+		res.Body = respBody
+		if resp.StatusCode >= 400 && !c.cfg.successStatus[resp.StatusCode] {
 			c.totalErrors.Add(1)
 			if resp.StatusCode == http.StatusTooManyRequests {
 				c.rateLimited.Add(1)
+				res.RateLimited++
 			}
 			if c.cfg.onError != nil {
 				c.cfg.onError(resp.StatusCode, req)
 			}
-			return respBody, resp.StatusCode, fmt.Errorf("resilient: HTTP %d: %s", resp.StatusCode, string(respBody))
+			err := c.statusErr(resp, respBody)
+			if c.exhausted(req, attempt, resp, nil) {
+				fail(resp.StatusCode, sent, err)
+				err = c.maxRetriesErr(err, history)
+			}
+			return res, err
+		}
+
+		if c.cfg.responseValidator != nil && sink == nil && resp.StatusCode != http.StatusNotModified {
+			if verr := c.cfg.responseValidator(resp.StatusCode, respBody); verr != nil {
+				c.totalErrors.Add(1)
+				lastRetryAfter = 0
+				lastErr = fmt.Errorf("resilient: invalid response: %w", verr)
+				fail(resp.StatusCode, sent, lastErr)
+				if c.shouldRetry(req, attempt, resp, verr) {
+					continue
+				}
+				if c.exhausted(req, attempt, resp, verr) {
+					return res, c.maxRetriesErr(lastErr, history)
+				}
+				return res, lastErr
+			}
 		}
 
+		c.increaseRateLimit(req.URL)
 		if c.cfg.onSuccess != nil {
 			c.cfg.onSuccess(req, resp)
 		}
-		return respBody, resp.StatusCode, nil
+		return res, nil
 	}
 
-	return nil, lastStatus, fmt.Errorf("resilient: max retries (%d) exceeded: %w", c.cfg.maxRetries, lastErr)
+	res.StatusCode = lastStatus
+	return res, c.maxRetriesErr(lastErr, history)
 }
 
 // Get performs a GET request to baseURL+path.
 func (c *Client) Get(ctx context.Context, path string, headers ...map[string]string) ([]byte, int, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.baseURL+path, nil)
+	req, err := c.NewRequest(ctx, http.MethodGet, path, nil, headers...)
 	if err != nil {
 		return nil, 0, err
 	}
-	applyHeaders(req, headers)
-	return c.Do(ctx, req)
+	return c.Do(req.Context(), req)
 }
 
 // Post performs a POST request to baseURL+path with the given body.
 func (c *Client) Post(ctx context.Context, path string, contentType string, body io.Reader, headers ...map[string]string) ([]byte, int, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.baseURL+path, body)
+	return c.doBody(ctx, http.MethodPost, path, contentType, body, headers)
+}
+
+// PostForm performs a POST request to baseURL+path with data encoded as
+// application/x-www-form-urlencoded. The encoded body is re-sent on retries.
+func (c *Client) PostForm(ctx context.Context, path string, data url.Values, headers ...map[string]string) ([]byte, int, error) {
+	return c.doBody(ctx, http.MethodPost, path, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()), headers)
+}
+
+// Put performs a PUT request to baseURL+path with the given body.
+func (c *Client) Put(ctx context.Context, path string, contentType string, body io.Reader, headers ...map[string]string) ([]byte, int, error) {
+	return c.doBody(ctx, http.MethodPut, path, contentType, body, headers)
+}
+
+// Patch performs a PATCH request to baseURL+path with the given body.
+func (c *Client) Patch(ctx context.Context, path string, contentType string, body io.Reader, headers ...map[string]string) ([]byte, int, error) {
+	return c.doBody(ctx, http.MethodPatch, path, contentType, body, headers)
+}
+
+// Delete performs a DELETE request to baseURL+path.
+func (c *Client) Delete(ctx context.Context, path string, headers ...map[string]string) ([]byte, int, error) {
+	req, err := c.NewRequest(ctx, http.MethodDelete, path, nil, headers...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.Do(req.Context(), req)
+}
+
+// Head performs a HEAD request to baseURL+path and returns the response
+// headers, since a HEAD response has no body.
+func (c *Client) Head(ctx context.Context, path string, headers ...map[string]string) (http.Header, int, error) {
+	req, err := c.NewRequest(ctx, http.MethodHead, path, nil, headers...)
+	if err != nil {
+		return nil, 0, err
+	}
+	res, err := c.DoResult(req.Context(), req)
+	return res.Header, res.StatusCode, err
+}
+
+func (c *Client) doBody(ctx context.Context, method, path, contentType string, body io.Reader, headers []map[string]string) ([]byte, int, error) {
+	req, err := c.NewRequest(ctx, method, path, body, headers...)
 	if err != nil {
 		return nil, 0, err
 	}
-	req.Header.Set("Content-Type", contentType)
-	applyHeaders(req, headers)
-	return c.Do(ctx, req)
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.Do(req.Context(), req)
 }
 
 // DoJSON marshals reqBody as JSON, sends a request, and unmarshals the response into respBody.
 func (c *Client) DoJSON(ctx context.Context, method, path string, reqBody, respBody any) (int, error) {
+	return c.DoWith(ctx, JSONCodec, method, path, reqBody, respBody)
+}
+
+// GetJSON performs a GET request to baseURL+path and unmarshals the JSON
+// response into out.
+func (c *Client) GetJSON(ctx context.Context, path string, out any, headers ...map[string]string) (int, error) {
+	return c.DoWith(ctx, JSONCodec, http.MethodGet, path, nil, out, headers...)
+}
+
+// JSON sends body as JSON and decodes the response into a new T:
+//
+//	user, status, err := resilient.JSON[User](ctx, client, "GET", "/users/1", nil)
+func JSON[T any](ctx context.Context, c *Client, method, path string, body any) (T, int, error) {
+	var out T
+	status, err := c.DoWith(ctx, JSONCodec, method, path, body, &out)
+	return out, status, err
+}
+
+// DoXML marshals reqBody as XML, sends a request, and unmarshals the response into respBody.
+func (c *Client) DoXML(ctx context.Context, method, path string, reqBody, respBody any) (int, error) {
+	return c.DoWith(ctx, XMLCodec, method, path, reqBody, respBody)
+}
+
+// DoWith encodes reqBody with cd, sends a request to baseURL+path, and
+// decodes the response into respBody. DoJSON and DoXML are DoWith with
+// JSONCodec and XMLCodec.
+func (c *Client) DoWith(ctx context.Context, cd Codec, method, path string, reqBody, respBody any, headers ...map[string]string) (int, error) {
 	var body io.Reader
 	if reqBody != nil {
-		data, err := json.Marshal(reqBody)
+		data, err := cd.Marshal(reqBody)
 		if err != nil {
 			return 0, fmt.Errorf("resilient: marshal request: %w", err)
 		}
 		body = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.cfg.baseURL+path, body)
+	req, err := c.NewRequest(ctx, method, path, body, headers...)
 	if err != nil {
 		return 0, err
 	}
-	if reqBody != nil {
-		req.Header.Set("Content-Type", "application/json")
+	if reqBody != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", cd.ContentType())
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", cd.ContentType())
 	}
-	req.Header.Set("Accept", "application/json")
 
-	respData, status, err := c.Do(ctx, req)
+	respData, status, err := c.Do(req.Context(), req)
 	if err != nil {
 		return status, err
 	}
 
-	if respBody != nil && len(respData) > 0 {
-		if err := json.Unmarshal(respData, respBody); err != nil {
+	// Statuses let through by WithSuccessStatuses carry no payload to decode.
+	if respBody != nil && len(respData) > 0 && status < 400 {
+		if err := cd.Unmarshal(respData, respBody); err != nil {
 			return status, fmt.Errorf("resilient: unmarshal response: %w", err)
 		}
 	}
 	return status, nil
 }
 
+// SetBodyFactory makes req's body come from newBody, called once per
+// attempt, so retries re-create the body instead of the client buffering it
+// in memory. Use it for large or streamed uploads such as files. Requests
+// built from bytes or strings already carry a GetBody and need nothing
+// extra. With hedging, newBody may be called concurrently.
+func SetBodyFactory(req *http.Request, newBody func() (io.ReadCloser, error)) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	req.Body = http.NoBody
+	req.GetBody = newBody
+	if req.ContentLength == 0 {
+		req.ContentLength = -1
+	}
+}
+
 // --- internal helpers ---
 
-func (c *Client) waitRateLimit(ctx context.Context) error {
-	if c.limiter == nil {
+// readCloser pairs a (possibly wrapped) reader with the original closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// signError is a Signer failure. It happens before anything is sent, so
+// execute neither retries it nor holds it against the endpoint.
+type signError struct{ err error }
+
+func (e *signError) Error() string { return "sign request: " + e.err.Error() }
+
+func (e *signError) Unwrap() error { return e.err }
+
+// errReader fails every read with err.
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// waitRateLimit waits for a token from every limiter that applies to u and
+// returns the tokens taken from reserving limiters, so that the caller can
+// refund them if the request fails before reaching the network. If a
+// limiter fails, the tokens already taken from the others are refunded.
+func (c *Client) waitRateLimit(ctx context.Context, u *url.URL) (rateTokens, error) {
+	var tokens rateTokens
+	take := func(lim RateLimiter, queue *priorityQueue) error {
+		t, err := c.waitLimiter(ctx, lim, queue)
+		if err != nil {
+			tokens.refund()
+			return err
+		}
+		if t.r != nil {
+			tokens = append(tokens, t)
+		}
+		return nil
+	}
+	if c.limiter != nil {
+		if err := take(c.limiter, c.queue); err != nil {
+			return nil, err
+		}
+	}
+	if c.cfg.perHostRPS > 0 {
+		if err := take(c.hostLimiter(u.Host).limiter, nil); err != nil {
+			return nil, err
+		}
+	}
+	if pl := c.pathLimiter(u.Path); pl != nil {
+		if err := take(pl.limiter, nil); err != nil {
+			return nil, err
+		}
+	}
+	if c.cfg.quotaPacing {
+		if err := c.quotaPacer(u.Host).wait(ctx); err != nil {
+			tokens.refund()
+			return nil, err
+		}
+	}
+	return tokens, nil
+}
+
+// waitLimiter waits for a token from lim, through queue when one is given.
+// With load shedding configured it fails fast with ErrShedded instead of
+// joining a queue that is too deep or too slow. Tokens of reserving
+// limiters outside the queue are returned so that they can be refunded;
+// the zero rateToken stands for one that cannot.
+func (c *Client) waitLimiter(ctx context.Context, lim RateLimiter, queue *priorityQueue) (rateToken, error) {
+	depth := c.queued.Add(1)
+	defer c.queued.Add(-1)
+	if c.cfg.maxQueueDepth > 0 && depth > int64(c.cfg.maxQueueDepth) {
+		c.shedded.Add(1)
+		return rateToken{}, ErrShedded
+	}
+
+	rl, reserving := lim.(reservingLimiter)
+	if queue != nil {
+		if reserving && c.tooSlow(ctx, estimateWait(rl, depth)) {
+			c.shedded.Add(1)
+			return rateToken{}, ErrShedded
+		}
+		return rateToken{}, queue.wait(ctx, lim)
+	}
+	if !reserving {
+		return rateToken{}, lim.Wait(ctx)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return rateToken{}, err
+	}
+	t, delay := reserveToken(rl)
+	if t.r == nil {
+		return rateToken{}, lim.Wait(ctx) // cannot be granted; let Wait report why
+	}
+	if c.tooSlow(ctx, delay) {
+		t.refund()
+		c.shedded.Add(1)
+		return rateToken{}, ErrShedded
+	}
+	// The delay and context deadlines are on the system clock, which the
+	// token buckets refill by, so this wait doesn't follow WithClock.
+	if deadline, ok := ctx.Deadline(); ok && delay > time.Until(deadline) {
+		t.refund()
+		return rateToken{}, errors.New("resilient: rate limit wait would exceed context deadline")
+	}
+	if delay == 0 {
+		return t, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return t, nil
+	case <-ctx.Done():
+		t.refund()
+		return rateToken{}, ctx.Err()
+	}
+}
+
+// tooSlow reports whether waiting d for a token should be shed, either
+// because it exceeds WithMaxQueueWait or the request's own deadline.
+func (c *Client) tooSlow(ctx context.Context, d time.Duration) bool {
+	if c.cfg.maxQueueWait == 0 && c.cfg.maxQueueDepth == 0 {
+		return false
+	}
+	if c.cfg.maxQueueWait > 0 && d > c.cfg.maxQueueWait {
+		return true
+	}
+	if deadline, ok := ctx.Deadline(); ok && d > time.Until(deadline) {
+		return true
+	}
+	return false
+}
+
+// estimateWait approximates how long the depth-th waiter will wait for lim.
+func estimateWait(lim reservingLimiter, depth int64) time.Duration {
+	missing := float64(depth) - lim.Tokens()
+	if missing <= 0 || lim.Limit() <= 0 {
+		return 0
+	}
+	return time.Duration(missing / float64(lim.Limit()) * float64(time.Second))
+}
+
+// allowRateLimit takes a token from every limiter that applies to u without
+// waiting, reporting whether they all had one. Like waitRateLimit, it
+// returns the tokens that can be refunded, and refunds them itself when a
+// limiter has none.
+func (c *Client) allowRateLimit(u *url.URL) (rateTokens, bool) {
+	var tokens rateTokens
+	take := func(lim RateLimiter) bool {
+		t, ok := allowToken(lim)
+		if !ok {
+			tokens.refund()
+			return false
+		}
+		if t.r != nil {
+			tokens = append(tokens, t)
+		}
+		return true
+	}
+	if c.limiter != nil && !take(c.limiter) {
+		return nil, false
+	}
+	if c.cfg.perHostRPS > 0 && !take(c.hostLimiter(u.Host).limiter) {
+		return nil, false
+	}
+	if pl := c.pathLimiter(u.Path); pl != nil && !take(pl.limiter) {
+		return nil, false
+	}
+	return tokens, true
+}
+
+// acquireSlot takes a concurrency slot when WithMaxConcurrent or
+// WithAdaptiveConcurrency is set, waiting until one is free or ctx is done.
+func (c *Client) acquireSlot(ctx context.Context) error {
+	if c.adaptive != nil {
+		waited, err := c.adaptive.acquire(ctx)
+		if waited {
+			c.slotWaits.Add(1)
+		}
+		return err
+	}
+	if c.slots == nil {
+		return nil
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	c.slotWaits.Add(1)
+	select {
+	case c.slots <- struct{}{}:
 		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseSlot returns the slot taken by acquireSlot. The attempt's latency
+// and whether it was dropped feed the adaptive concurrency limit.
+func (c *Client) releaseSlot(rtt time.Duration, dropped bool) {
+	if c.adaptive != nil {
+		c.adaptive.release(rtt, dropped)
+		return
+	}
+	if c.slots != nil {
+		<-c.slots
 	}
-	return c.limiter.Wait(ctx)
 }
 
-func (c *Client) shouldRetry(attempt int, resp *http.Response, err error) bool {
-	if attempt >= c.cfg.maxRetries {
+// abandonSlot returns the slot taken by acquireSlot for an attempt that was
+// never sent, without feeding the adaptive concurrency limit.
+func (c *Client) abandonSlot() {
+	if c.adaptive != nil {
+		c.adaptive.abandon()
+		return
+	}
+	if c.slots != nil {
+		<-c.slots
+	}
+}
+
+func (c *Client) concurrencyLimit() int {
+	if c.adaptive == nil {
+		return 0
+	}
+	return c.adaptive.Limit()
+}
+
+// adaptiveTrigger reports whether a retried status should reduce the rate:
+// any retryable status by default, or only those set with
+// WithAdaptiveTriggers.
+func (c *Client) adaptiveTrigger(status int) bool {
+	if c.cfg.adaptiveDisabled {
+		return false
+	}
+	return c.cfg.adaptiveTriggers == nil || c.cfg.adaptiveTriggers[status]
+}
+
+// isOverload reports whether status signals an overloaded upstream.
+func isOverload(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// hostLimiter returns the token bucket for host, creating it on first use.
+func (c *Client) hostLimiter(host string) *scopedLimiter {
+	now := c.cfg.clock.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hl, ok := c.hostLimiters[host]
+	if !ok {
+		if c.hostLimiters == nil {
+			c.hostLimiters = make(map[string]*scopedLimiter)
+		}
+		hl = &scopedLimiter{
+			limiter:  rate.NewLimiter(rate.Limit(c.cfg.perHostRPS), c.cfg.perHostBurst),
+			original: rate.Limit(c.cfg.perHostRPS),
+		}
+		c.hostLimiters[host] = hl
+	}
+	hl.lastUsed = now
+	if len(c.hostLimiters) >= c.hostSweepAt {
+		c.sweepHostLimiters(now)
+	}
+	return hl
+}
+
+// sweepHostLimiters drops the host limiters idle for idleTTL that a fresh
+// one would replace exactly: a full bucket at the configured rate, with no
+// adaptive reduction pending. It must be called with c.mu held.
+func (c *Client) sweepHostLimiters(now time.Time) {
+	for host, hl := range c.hostLimiters {
+		if now.Sub(hl.lastUsed) >= idleTTL && hl.adaptiveTimer == nil &&
+			hl.limiter.Limit() == hl.original && hl.limiter.Tokens() >= float64(hl.limiter.Burst()) {
+			delete(c.hostLimiters, host)
+		}
+	}
+	c.hostSweepAt = max(64, 2*len(c.hostLimiters))
+}
+
+func (c *Client) shouldRetry(req *http.Request, attempt int, resp *http.Response, err error) bool {
+	return attempt < c.cfg.maxRetries && c.retryable(req, attempt, resp, err)
+}
+
+// exhausted reports whether a failed attempt would have been retried if
+// WithRetry had allowed more, so its error should wrap ErrMaxRetriesExceeded.
+func (c *Client) exhausted(req *http.Request, attempt int, resp *http.Response, err error) bool {
+	return c.cfg.maxRetries > 0 && attempt >= c.cfg.maxRetries && c.retryable(req, attempt, resp, err)
+}
+
+// retryable reports whether a failed attempt is worth retrying, regardless
+// of how many attempts are left.
+func (c *Client) retryable(req *http.Request, attempt int, resp *http.Response, err error) bool {
+	// A failed send may still have reached the server, so only resend
+	// requests that are safe to repeat.
+	if err != nil && c.cfg.idempotentOnly && !isIdempotent(req) {
+		return false
+	}
+	// A pin mismatch won't go away on its own.
+	var pinErr *PinError
+	if errors.As(err, &pinErr) {
+		return false
+	}
+	if resp != nil && c.cfg.successStatus[resp.StatusCode] {
 		return false
 	}
 	if c.cfg.retryPolicy != nil {
@@ -300,57 +1340,205 @@ func (c *Client) shouldRetry(attempt int, resp *http.Response, err error) bool {
 	return false
 }
 
-func (c *Client) backoffDuration(attempt int, lastStatus int) time.Duration {
-	base := c.cfg.initialBackoff * time.Duration(1<<(attempt-1))
+// isIdempotent reports whether req can be safely re-sent, using the same
+// rules as net/http: idempotent methods, or an explicit idempotency key.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, ok := req.Header["Idempotency-Key"]
+	if !ok {
+		_, ok = req.Header["X-Idempotency-Key"]
+	}
+	return ok
+}
+
+// maxBackoffBase bounds the exponential backoff base, leaving room for
+// doubling and jitter without overflowing time.Duration.
+const maxBackoffBase = math.MaxInt64 / 4
 
-	// If we have a Retry-After hint, use it as minimum.
-	// (We re-check in case caller stored it; for simplicity we use base.)
-	_ = lastStatus
+func (c *Client) backoffDuration(attempt int, retryAfter time.Duration) time.Duration {
+	// Stop doubling at maxBackoffBase, so that long retry chains cannot
+	// overflow. It is far above any WithMaxBackoff cap, which is applied
+	// after jitter, so capped waits stay at the cap.
+	base := c.cfg.initialBackoff
+	for i := 1; i < attempt && base > 0 && base < maxBackoffBase; i++ {
+		base *= 2
+	}
 
-	// Add jitter: ±25%
-	jitter := float64(base) * 0.25 * (rand.Float64()*2 - 1) //nolint:gosec
-	d := time.Duration(float64(base) + jitter)
+	d := c.jitter(base)
 	if d < 0 {
 		d = c.cfg.initialBackoff
 	}
+	if c.cfg.maxBackoff > 0 && d > c.cfg.maxBackoff {
+		d = c.cfg.maxBackoff
+	}
+
+	// A Retry-After hint from the server is used as the minimum wait.
+	if retryAfter > 0 {
+		if c.cfg.maxRetryAfter > 0 && retryAfter > c.cfg.maxRetryAfter {
+			retryAfter = c.cfg.maxRetryAfter
+		}
+		if retryAfter > d {
+			d = retryAfter
+		}
+	}
 	return d
 }
 
+// jitter randomizes base according to the configured JitterMode.
+func (c *Client) jitter(base time.Duration) time.Duration {
+	switch c.cfg.jitterMode {
+	case JitterNone:
+		return base
+	case JitterFull:
+		return time.Duration(rand.Float64() * float64(base)) //nolint:gosec
+	case JitterEqual:
+		half := float64(base) / 2
+		return time.Duration(half + rand.Float64()*half) //nolint:gosec
+	default:
+		jitter := float64(base) * c.cfg.jitterFraction * (rand.Float64()*2 - 1) //nolint:gosec
+		return time.Duration(float64(base) + jitter)
+	}
+}
+
+// elapsedErr reports that the WithMaxElapsedTime budget ran out.
+func (c *Client) elapsedErr(cause error) error {
+	return fmt.Errorf("%w (%v): %w", ErrMaxElapsedTime, c.cfg.maxElapsedTime, cause)
+}
+
+// statusErr returns the error for an error response, decoded with
+// WithErrorDecoder when one is set.
+func (c *Client) statusErr(resp *http.Response, body []byte) error {
+	if c.cfg.errorDecoder != nil {
+		if err := c.cfg.errorDecoder(resp, body); err != nil {
+			return fmt.Errorf("resilient: HTTP %d: %w", resp.StatusCode, err)
+		}
+	}
+	return fmt.Errorf("resilient: HTTP %d: %s", resp.StatusCode, string(body))
+}
+
+// maxRetriesErr wraps the error of the last attempt in ErrMaxRetriesExceeded
+// and a RetryError carrying history.
+func (c *Client) maxRetriesErr(cause error, history []Attempt) error {
+	return &RetryError{
+		Attempts: history,
+		err:      fmt.Errorf("%w (%d): %w", ErrMaxRetriesExceeded, c.cfg.maxRetries, cause),
+	}
+}
+
 // BackoffDuration is exported for testing.
 func (c *Client) BackoffDuration(attempt int) time.Duration {
 	return c.backoffDuration(attempt, 0)
 }
 
-func (c *Client) reduceRateLimit() {
+func (c *Client) reduceRateLimit(u *url.URL) {
+	var events []Event
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if c.limiter == nil || c.closed {
-		return
+	if !c.closed {
+		if hl := c.hostLimiters[u.Host]; hl != nil {
+			events = append(events, c.decrease(hl.limiter, &hl.original, &hl.adaptiveTimer, u, u.Host))
+		}
+		if pl := c.pathLimiter(u.Path); pl != nil {
+			events = append(events, c.decrease(pl.limiter, &pl.original, &pl.adaptiveTimer, u, pl.pattern))
+		}
+		if c.limiter != nil {
+			events = append(events, c.decrease(c.limiter, &c.originalRate, &c.adaptiveTimer, u, ""))
+		}
 	}
+	c.mu.Unlock()
+	c.emitAll(events)
+}
 
-	reduced := c.originalRate / 2
+// decrease halves lim and, unless additive increase is enabled, schedules a
+// restore to *original after the cooldown. In additive-increase mode the
+// current rate is halved, so repeated 429s keep backing off. u and name
+// identify the request and limiter in the returned event, which the caller
+// emits once c.mu is released.
+// It must be called with c.mu held.
+func (c *Client) decrease(lim RateLimiter, original *rate.Limit, timer *Timer, u *url.URL, name string) Event {
+	base := *original
+	if c.cfg.additiveIncrease > 0 {
+		base = lim.Limit()
+	}
+	reduced := base / 2
 	if reduced < 0.01 {
 		reduced = 0.01
 	}
-	c.limiter.SetLimit(reduced)
+	lim.SetLimit(reduced)
+	c.reductions.Add(1)
+	ev := Event{Type: EventRateReduced, URL: redactURL(u, c.redact).Redacted(), Limiter: name, Rate: float64(reduced)}
 
-	if c.adaptiveTimer != nil {
-		c.adaptiveTimer.Stop()
+	if *timer != nil {
+		(*timer).Stop()
+		*timer = nil
 	}
-	c.adaptiveTimer = time.AfterFunc(c.cfg.adaptiveCooldown, func() {
+	if c.cfg.additiveIncrease > 0 {
+		return ev
+	}
+	*timer = c.cfg.clock.AfterFunc(c.cfg.adaptiveCooldown, func() {
 		c.mu.Lock()
-		defer c.mu.Unlock()
-		if !c.closed && c.limiter != nil {
-			c.limiter.SetLimit(c.originalRate)
+		restored := !c.closed
+		if restored {
+			lim.SetLimit(*original)
+		}
+		to := float64(*original)
+		c.mu.Unlock()
+		if restored {
+			c.emit(Event{Type: EventRateRestored, Limiter: name, Rate: to}, nil)
 		}
 	})
+	return ev
+}
+
+// increaseRateLimit raises every limiter that applies to u by one additive
+// step after a successful response, up to its configured rate.
+func (c *Client) increaseRateLimit(u *url.URL) {
+	if c.cfg.additiveIncrease <= 0 {
+		return
+	}
+	var events []Event
+	c.mu.Lock()
+	if !c.closed {
+		if hl := c.hostLimiters[u.Host]; hl != nil {
+			events = c.increase(events, hl.limiter, hl.original, u.Host)
+		}
+		if pl := c.pathLimiter(u.Path); pl != nil {
+			events = c.increase(events, pl.limiter, pl.original, pl.pattern)
+		}
+		if c.limiter != nil {
+			events = c.increase(events, c.limiter, c.originalRate, "")
+		}
+	}
+	c.mu.Unlock()
+	c.emitAll(events)
+}
+
+// increase adds a fraction of original to lim's rate, capped at original,
+// and appends an event to events when the rate is restored.
+// It must be called with c.mu held.
+func (c *Client) increase(events []Event, lim RateLimiter, original rate.Limit, name string) []Event {
+	if cur := lim.Limit(); cur < original {
+		next := min(cur+original*rate.Limit(c.cfg.additiveIncrease), original)
+		lim.SetLimit(next)
+		if next == original {
+			events = append(events, Event{Type: EventRateRestored, Limiter: name, Rate: float64(original)})
+		}
+	}
+	return events
 }
 
 // parseRetryAfter parses the Retry-After header value.
 // It supports both seconds (integer) and HTTP-date formats.
 // Returns the duration to wait, or 0 if unparseable.
 func parseRetryAfter(val string) time.Duration {
+	return retryAfterAt(val, time.Now())
+}
+
+// retryAfterAt is parseRetryAfter with HTTP-dates measured from now.
+func retryAfterAt(val string, now time.Time) time.Duration {
 	if val == "" {
 		return 0
 	}
@@ -368,7 +1556,7 @@ func parseRetryAfter(val string) time.Duration {
 		"Mon Jan _2 15:04:05 2006",
 	} {
 		if t, err := time.Parse(layout, val); err == nil {
-			d := time.Until(t)
+			d := t.Sub(now)
 			if d < 0 {
 				return 0
 			}
@@ -381,6 +1569,9 @@ func parseRetryAfter(val string) time.Duration {
 func applyHeaders(req *http.Request, headers []map[string]string) {
 	for _, h := range headers {
 		for k, v := range h {
+			if isPathParam(k) {
+				continue
+			}
 			req.Header.Set(k, v)
 		}
 	}
@@ -3,10 +3,12 @@ package resilient
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -232,6 +234,41 @@ func TestStatsAccuracy(t *testing.T) {
 	}
 }
 
+func TestRetryStats(t *testing.T) {
+	var count atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if count.Add(1) <= 2 {
+			w.WriteHeader(429)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithRateLimit(100, 10),
+		WithRetry(3, 10*time.Millisecond),
+		WithJitter(JitterNone, 0),
+		WithAdaptive(time.Minute),
+	)
+	defer c.Close()
+
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	s := c.Stats()
+	if s.TotalRetries != 2 {
+		t.Fatalf("expected 2 retries, got %d", s.TotalRetries)
+	}
+	if s.TotalBackoffTime != 30*time.Millisecond {
+		t.Fatalf("expected 30ms of backoff, got %v", s.TotalBackoffTime)
+	}
+	if s.AdaptiveReductions != 2 {
+		t.Fatalf("expected 2 adaptive reductions, got %d", s.AdaptiveReductions)
+	}
+}
+
 func TestConcurrentSafety(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("ok"))
@@ -510,5 +547,822 @@ func TestSetRateLimit(t *testing.T) {
 	}
 }
 
+func TestPerHostRateLimit(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	srvA := httptest.NewServer(handler)
+	defer srvA.Close()
+	srvB := httptest.NewServer(handler)
+	defer srvB.Close()
+
+	// 10 rps, burst 1 per host — alternating hosts should not throttle each other.
+	c := New(WithPerHostRateLimit(10, 1))
+	defer c.Close()
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		for _, u := range []string{srvA.URL, srvB.URL} {
+			req, _ := http.NewRequest(http.MethodGet, u, nil)
+			if _, _, err := c.Do(context.Background(), req); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	elapsed := time.Since(start)
+
+	// One wait per host (~100ms) rather than three on a shared bucket (~300ms).
+	if elapsed < 75*time.Millisecond {
+		t.Fatalf("per-host rate limiting too fast: %v", elapsed)
+	}
+	if elapsed > 250*time.Millisecond {
+		t.Fatalf("hosts throttled each other: %v", elapsed)
+	}
+
+	c.mu.Lock()
+	n := len(c.hostLimiters)
+	c.mu.Unlock()
+	if n != 2 {
+		t.Fatalf("expected 2 host limiters, got %d", n)
+	}
+}
+
+func TestPerHostRateLimitEvictsIdleHosts(t *testing.T) {
+	c := New(WithPerHostRateLimit(10, 1))
+	defer c.Close()
+
+	busy := c.hostLimiter("busy.example")
+	reduced := c.hostLimiter("reduced.example")
+	c.reduceRateLimit(&url.URL{Scheme: "https", Host: "reduced.example"})
+	for i := range 1000 {
+		host := fmt.Sprintf("host%d.example", i)
+		c.hostLimiter(host)
+		c.hostLimiter("busy.example")
+		c.mu.Lock()
+		// Age every host but the busy one as if it was last called long ago.
+		for h, hl := range c.hostLimiters {
+			if h != "busy.example" {
+				hl.lastUsed = hl.lastUsed.Add(-idleTTL)
+			}
+		}
+		n := len(c.hostLimiters)
+		c.mu.Unlock()
+		if n > 128 {
+			t.Fatalf("host limiters grew to %d after %d hosts", n, i+1)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hostLimiters["busy.example"] != busy {
+		t.Fatal("busy host limiter was evicted")
+	}
+	if c.hostLimiters["reduced.example"] != reduced {
+		t.Fatal("host limiter with a pending restore was evicted")
+	}
+}
+
+func TestRetryAfterHonored(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(429)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(2, 10*time.Millisecond))
+	defer c.Close()
+
+	start := time.Now()
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("Retry-After not honored: %v", elapsed)
+	}
+}
+
+func TestMaxRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(503)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithRetry(2, 10*time.Millisecond),
+		WithMaxRetryAfter(50*time.Millisecond),
+	)
+	defer c.Close()
+
+	start := time.Now()
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+	if elapsed < 50*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("expected capped Retry-After wait, got %v", elapsed)
+	}
+}
+
+func TestMaxBackoff(t *testing.T) {
+	c := New(WithRetry(10, 100*time.Millisecond), WithMaxBackoff(150*time.Millisecond))
+	defer c.Close()
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		if d := c.BackoffDuration(attempt); d > 150*time.Millisecond {
+			t.Fatalf("attempt %d: backoff %v exceeds cap", attempt, d)
+		}
+	}
+	if d := c.BackoffDuration(8); d != 150*time.Millisecond {
+		t.Fatalf("expected capped backoff of 150ms, got %v", d)
+	}
+}
+
+func TestBackoffLongRetryChain(t *testing.T) {
+	c := New(WithRetry(100, 100*time.Millisecond), WithMaxBackoff(10*time.Second))
+	defer c.Close()
+
+	for _, attempt := range []int{30, 38, 60, 64, 100, 1000} {
+		if d := c.BackoffDuration(attempt); d != 10*time.Second {
+			t.Fatalf("attempt %d: expected capped backoff of 10s, got %v", attempt, d)
+		}
+	}
+
+	uncapped := New(WithRetry(100, time.Second), WithJitter(JitterNone, 0))
+	defer uncapped.Close()
+	prev := time.Duration(0)
+	for attempt := 1; attempt <= 100; attempt++ {
+		d := uncapped.BackoffDuration(attempt)
+		if d < prev {
+			t.Fatalf("attempt %d: backoff went down from %v to %v", attempt, prev, d)
+		}
+		prev = d
+	}
+}
+
+func TestMaxElapsedTime(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(503)
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithRetry(100, 20*time.Millisecond),
+		WithMaxElapsedTime(150*time.Millisecond),
+	)
+	defer c.Close()
+
+	start := time.Now()
+	_, _, err := c.Get(context.Background(), "/")
+	if !errors.Is(err, ErrMaxElapsedTime) {
+		t.Fatalf("expected ErrMaxElapsedTime, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Fatalf("budget not enforced: %v", elapsed)
+	}
+}
+
+func TestMaxElapsedTimeInFlight(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithMaxElapsedTime(50*time.Millisecond))
+	defer c.Close()
+
+	_, _, err := c.Get(context.Background(), "/")
+	if !errors.Is(err, ErrMaxElapsedTime) {
+		t.Fatalf("expected ErrMaxElapsedTime, got %v", err)
+	}
+}
+
+func TestJitterModes(t *testing.T) {
+	tests := []struct {
+		mode     JitterMode
+		fraction float64
+		min, max time.Duration
+	}{
+		{JitterNone, 0, 100 * time.Millisecond, 100 * time.Millisecond},
+		{JitterFull, 0, 0, 100 * time.Millisecond},
+		{JitterEqual, 0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{JitterProportional, 0.5, 50 * time.Millisecond, 150 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		c := New(WithRetry(5, 100*time.Millisecond), WithJitter(tt.mode, tt.fraction))
+		for i := 0; i < 100; i++ {
+			if d := c.BackoffDuration(1); d < tt.min || d > tt.max {
+				t.Fatalf("mode %d: backoff %v outside [%v, %v]", tt.mode, d, tt.min, tt.max)
+			}
+		}
+		c.Close()
+	}
+}
+
+func TestRetryIdempotentOnly(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		// Drop the connection to simulate a network error.
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(2, 10*time.Millisecond), WithRetryIdempotentOnly())
+	defer c.Close()
+
+	if _, _, err := c.Post(context.Background(), "/", "text/plain", strings.NewReader("x")); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Fatalf("expected POST not to be retried, got %d attempts", n)
+	}
+
+	attempts.Store(0)
+	if _, _, err := c.Get(context.Background(), "/"); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := attempts.Load(); n != 3 {
+		t.Fatalf("expected GET to be retried, got %d attempts", n)
+	}
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithMaxConcurrent(2))
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := c.Get(context.Background(), "/"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > 2 {
+		t.Fatalf("expected at most 2 concurrent requests, saw %d", p)
+	}
+	if s := c.Stats(); s.ConcurrencyWaits == 0 {
+		t.Fatal("expected waits to be recorded in stats")
+	}
+}
+
+func TestMaxConcurrentRespectsContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := New(WithBaseURL(srv.URL), WithMaxConcurrent(1))
+	defer c.Close()
+
+	go c.Get(context.Background(), "/")
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.Get(ctx, "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded while waiting for a slot, got %v", err)
+	}
+}
+
+func TestMaxQueueWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	// 2 rps: the second request would wait ~500ms.
+	c := New(WithBaseURL(srv.URL), WithRateLimit(2, 1), WithMaxQueueWait(100*time.Millisecond))
+	defer c.Close()
+
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, _, err := c.Get(context.Background(), "/")
+	if !errors.Is(err, ErrShedded) {
+		t.Fatalf("expected ErrShedded, got %v", err)
+	}
+	if time.Since(start) > 50*time.Millisecond {
+		t.Fatal("shedding should fail fast")
+	}
+	if s := c.Stats(); s.Shedded != 1 {
+		t.Fatalf("expected 1 shed request, got %d", s.Shedded)
+	}
+}
+
+func TestMaxQueueDepth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRateLimit(5, 1), WithMaxQueueDepth(2))
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	var shed atomic.Int32
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := c.Get(context.Background(), "/"); errors.Is(err, ErrShedded) {
+				shed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if shed.Load() == 0 {
+		t.Fatal("expected some requests to be shed")
+	}
+}
+
+func TestAdditiveIncrease(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= 2 {
+			w.WriteHeader(429)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithRateLimit(100, 10),
+		WithRetry(2, time.Millisecond),
+		WithAdditiveIncrease(0.1),
+	)
+	defer c.Close()
+
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	// Two 429s: 100 -> 50 -> 25, then one success adds 10.
+	if got := c.limiter.Limit(); got != 35 {
+		t.Fatalf("expected rate 35 after decrease and one step, got %v", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		c.Get(context.Background(), "/")
+	}
+	if got := c.limiter.Limit(); got != 100 {
+		t.Fatalf("expected rate to ramp back to 100, got %v", got)
+	}
+}
+
+func TestAdaptiveTriggers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithRateLimit(100, 10),
+		WithRetry(1, time.Millisecond),
+		WithAdaptiveTriggers(http.StatusTooManyRequests),
+	)
+	defer c.Close()
+
+	c.Get(context.Background(), "/")
+	if got := c.limiter.Limit(); got != 100 {
+		t.Fatalf("expected 503 not to reduce the rate, got %v", got)
+	}
+}
+
+func TestAdaptiveDisabled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithRateLimit(100, 10),
+		WithRetry(1, time.Millisecond),
+		WithAdaptiveDisabled(),
+	)
+	defer c.Close()
+
+	c.Get(context.Background(), "/")
+	if got := c.limiter.Limit(); got != 100 {
+		t.Fatalf("expected rate to stay at 100, got %v", got)
+	}
+}
+
+func TestRESTMethods(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("Content-Type"), body)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+	ctx := context.Background()
+
+	body, _, err := c.Put(ctx, "/", "text/plain", strings.NewReader("a"))
+	if err != nil || string(body) != "PUT text/plain a" {
+		t.Fatalf("Put: got %q, %v", body, err)
+	}
+	body, _, err = c.Patch(ctx, "/", "text/plain", strings.NewReader("b"))
+	if err != nil || string(body) != "PATCH text/plain b" {
+		t.Fatalf("Patch: got %q, %v", body, err)
+	}
+	body, _, err = c.Delete(ctx, "/")
+	if err != nil || string(body) != "DELETE  " {
+		t.Fatalf("Delete: got %q, %v", body, err)
+	}
+	header, status, err := c.Head(ctx, "/", map[string]string{"X-Test": "1"})
+	if err != nil || status != 200 || header.Get("X-Method") != "HEAD" {
+		t.Fatalf("Head: got %v %d, %v", header, status, err)
+	}
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s|%s", r.Header.Get("Authorization"), r.Header.Get("Accept"), r.Header.Get("X-Extra"))
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithDefaultHeaders(map[string]string{"Authorization": "Bearer t", "Accept": "application/json"}),
+		WithHeader("X-Extra", "1"),
+	)
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/", map[string]string{"Accept": "text/plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "Bearer t|text/plain|1" {
+		t.Fatalf("expected defaults with per-request override, got %q", body)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	c.Do(context.Background(), req)
+	if len(req.Header) != 0 {
+		t.Fatalf("expected caller's request to be left untouched, got %v", req.Header)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.UserAgent()))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+	body, _, _ := c.Get(context.Background(), "/")
+	if string(body) != "resilient-go/"+Version {
+		t.Fatalf("expected default user agent, got %q", body)
+	}
+
+	c2 := New(WithBaseURL(srv.URL), WithUserAgent("my-app/2.0"))
+	defer c2.Close()
+	body, _, _ = c2.Get(context.Background(), "/")
+	if string(body) != "my-app/2.0" {
+		t.Fatalf("expected custom user agent, got %q", body)
+	}
+	body, _, _ = c2.Get(context.Background(), "/", map[string]string{"User-Agent": "override"})
+	if string(body) != "override" {
+		t.Fatalf("expected per-request user agent to win, got %q", body)
+	}
+}
+
+func TestGetJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/json" {
+			t.Errorf("expected JSON Accept header, got %q", r.Header.Get("Accept"))
+		}
+		json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path, "method": r.Method})
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var out map[string]string
+	status, err := c.GetJSON(context.Background(), "/users/{id}", &out, PathParam("id", "7"))
+	if err != nil || status != 200 || out["path"] != "/users/7" {
+		t.Fatalf("GetJSON: got %v %d, %v", out, status, err)
+	}
+
+	type reply struct {
+		Path   string `json:"path"`
+		Method string `json:"method"`
+	}
+	r, status, err := JSON[reply](context.Background(), c, http.MethodPost, "/items", map[string]int{"n": 1})
+	if err != nil || status != 200 || r.Method != "POST" || r.Path != "/items" {
+		t.Fatalf("JSON[T]: got %+v %d, %v", r, status, err)
+	}
+}
+
+func TestPostForm(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		r.ParseForm()
+		fmt.Fprintf(w, "%s|%s|%s", r.Header.Get("Content-Type"), r.PostForm.Get("name"), r.PostForm.Get("q"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(1, time.Millisecond))
+	defer c.Close()
+
+	body, _, err := c.PostForm(context.Background(), "/", url.Values{"name": {"a b"}, "q": {"x&y"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "application/x-www-form-urlencoded|a b|x&y" {
+		t.Fatalf("expected form body to survive the retry, got %q", body)
+	}
+}
+
+func TestBodyFactory(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(b)
+	}))
+	defer srv.Close()
+
+	c := New(WithRetry(1, time.Millisecond))
+	defer c.Close()
+
+	var opened atomic.Int32
+	req, _ := http.NewRequest("PUT", srv.URL, nil)
+	SetBodyFactory(req, func() (io.ReadCloser, error) {
+		opened.Add(1)
+		return io.NopCloser(strings.NewReader("streamed")), nil
+	})
+
+	body, status, err := c.Do(context.Background(), req)
+	if err != nil || status != 200 {
+		t.Fatalf("expected success, got %d %v", status, err)
+	}
+	if string(body) != "streamed" {
+		t.Fatalf("expected re-created body, got %q", body)
+	}
+	if n := opened.Load(); n != 2 {
+		t.Fatalf("expected factory called once per attempt, got %d", n)
+	}
+}
+
+func TestBodyFactoryError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	c := New(WithRetry(0, 0))
+	defer c.Close()
+
+	req, _ := http.NewRequest("POST", srv.URL, nil)
+	req.ContentLength = 4
+	boom := errors.New("open failed")
+	SetBodyFactory(req, func() (io.ReadCloser, error) { return nil, boom })
+
+	if _, _, err := c.Do(context.Background(), req); !errors.Is(err, boom) {
+		t.Fatalf("expected factory error, got %v", err)
+	}
+}
+
+func TestSessionCookies(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			// The session is set on a failed attempt; the retry must carry it.
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/me":
+			if ck, err := r.Cookie("session"); err != nil || ck.Value != "abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		if _, err := r.Cookie("session"); err == nil && r.URL.Path == "/login" {
+			w.Write([]byte("resent"))
+		}
+	}))
+	defer srv.Close()
+
+	hc := &http.Client{}
+	c := New(WithBaseURL(srv.URL), WithHTTPClient(hc), WithRetry(1, time.Millisecond), WithSessionCookies())
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/login")
+	if err != nil || string(body) != "resent" {
+		t.Fatalf("expected cookie on retry, got %q %v", body, err)
+	}
+	if _, status, err := c.Get(context.Background(), "/me"); err != nil || status != 200 {
+		t.Fatalf("expected session on later call, got %d %v", status, err)
+	}
+	if hc.Jar != nil {
+		t.Fatal("expected the caller's http.Client to be left untouched")
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(1, time.Millisecond))
+	defer c.Close()
+	if _, _, err := c.Get(context.Background(), "/"); !errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Fatalf("expected ErrMaxRetriesExceeded, got %v", err)
+	}
+
+	limited := New(WithBaseURL(srv.URL), WithRetry(0, 0), WithRateLimit(0.001, 1))
+	defer limited.Close()
+	limited.Get(context.Background(), "/") // spend the burst
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := limited.Get(ctx, "/")
+	if !errors.Is(err, ErrRateLimitWait) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected ErrRateLimitWait wrapping the cause, got %v", err)
+	}
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string { return e.Code + ": " + e.Message }
+
+func TestErrorDecoder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			http.Error(w, "nope", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error":{"code":"invalid_email","message":"email is malformed"}}`))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithErrorDecoder(func(resp *http.Response, body []byte) error {
+		var env struct{ Error *apiError }
+		if json.Unmarshal(body, &env) != nil || env.Error == nil {
+			return nil
+		}
+		return env.Error
+	}))
+	defer c.Close()
+
+	_, status, err := c.Get(context.Background(), "/")
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.Code != "invalid_email" || status != http.StatusUnprocessableEntity {
+		t.Fatalf("expected decoded apiError, got %d %v", status, err)
+	}
+
+	// Bodies the decoder doesn't recognise keep the generic error.
+	if _, _, err := c.Get(context.Background(), "/plain"); err == nil || errors.As(err, &apiErr) {
+		t.Fatalf("expected generic error, got %v", err)
+	}
+}
+
+func TestResponseValidator(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 || r.URL.Path == "/broken" {
+			w.Write([]byte(`{"items":[`))
+			return
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(1, time.Millisecond), WithResponseValidator(func(status int, body []byte) error {
+		if !json.Valid(body) {
+			return errors.New("truncated JSON")
+		}
+		return nil
+	}))
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/")
+	if err != nil || string(body) != `{"items":[]}` {
+		t.Fatalf("expected retry past the invalid body, got %q %v", body, err)
+	}
+
+	_, _, err = c.Get(context.Background(), "/broken")
+	if !errors.Is(err, ErrMaxRetriesExceeded) || !strings.Contains(err.Error(), "truncated JSON") {
+		t.Fatalf("expected validation error after retries, got %v", err)
+	}
+}
+
+func TestSuccessStatuses(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("missing"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetryableStatus(http.StatusNotFound), WithSuccessStatuses(http.StatusNotFound))
+	defer c.Close()
+
+	body, status, err := c.Get(context.Background(), "/users/42")
+	if err != nil || status != http.StatusNotFound || string(body) != "missing" {
+		t.Fatalf("expected 404 without error, got %d %q %v", status, body, err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected no retries, got %d calls", n)
+	}
+	if s := c.Stats(); s.TotalErrors != 0 {
+		t.Fatalf("expected no errors counted, got %d", s.TotalErrors)
+	}
+}
+
+func TestRetryErrorHistory(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(2, time.Millisecond), WithJitter(JitterNone, 0))
+	defer c.Close()
+
+	_, _, err := c.Get(context.Background(), "/")
+	var re *RetryError
+	if !errors.As(err, &re) || !errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Fatalf("expected RetryError, got %v", err)
+	}
+	if len(re.Attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %+v", re.Attempts)
+	}
+	for i, want := range []int{429, 503, 503} {
+		a := re.Attempts[i]
+		if a.StatusCode != want || a.Err == nil {
+			t.Fatalf("attempt %d: expected %d with an error, got %+v", i+1, want, a)
+		}
+	}
+	if re.Attempts[0].Backoff <= 0 || re.Attempts[2].Backoff != 0 {
+		t.Fatalf("expected backoff between attempts only, got %+v", re.Attempts)
+	}
+}
+
 // Ensure the package imports are used.
 var _ = fmt.Sprintf
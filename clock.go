@@ -0,0 +1,35 @@
+package resilient
+
+import "time"
+
+// Clock is the source of time for backoff waits, adaptive-rate cooldowns,
+// Retry-After dates, the WithMaxElapsedTime budget, attempt and call
+// durations and event timestamps. The default is the system clock; tests
+// can inject a fake one with WithClock, such as resilienttest.FakeClock, to
+// make these waits instant and deterministic. Rate-limit token waits stay
+// on the system clock, which the token buckets refill by.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// since returns the time elapsed since t on the client's clock.
+func (c *Client) since(t time.Time) time.Duration {
+	return c.cfg.clock.Now().Sub(t)
+}
+
+// Timer is a pending Clock.AfterFunc call.
+type Timer interface {
+	// Stop prevents the call from running, reporting whether it did.
+	Stop() bool
+}
+
+// systemClock is the Clock backed by package time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
@@ -0,0 +1,34 @@
+package resilient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+)
+
+// Codec encodes request bodies and decodes responses for one wire format.
+// Use it with DoWith to send any format through the client's pipeline.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	// ContentType is sent as Content-Type and Accept.
+	ContentType() string
+}
+
+// Built-in codecs. MessagePack and Protocol Buffers codecs live in the
+// resilientmsgpack and resilientproto sub-packages.
+var (
+	JSONCodec Codec = jsonCodec{}
+	XMLCodec  Codec = xmlCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) ContentType() string                { return "application/json" }
+
+type xmlCodec struct{}
+
+func (xmlCodec) Marshal(v any) ([]byte, error)      { return xml.Marshal(v) }
+func (xmlCodec) Unmarshal(data []byte, v any) error { return xml.Unmarshal(data, v) }
+func (xmlCodec) ContentType() string                { return "application/xml" }
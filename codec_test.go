@@ -0,0 +1,73 @@
+package resilient
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDoXML(t *testing.T) {
+	type item struct {
+		XMLName xml.Name `xml:"item"`
+		Name    string   `xml:"name"`
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/xml" {
+			t.Errorf("expected XML content type, got %q", ct)
+		}
+		if accept := r.Header.Get("Accept"); accept != "application/xml" {
+			t.Errorf("expected XML Accept header, got %q", accept)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var in item
+		xml.Unmarshal(body, &in)
+		xml.NewEncoder(w).Encode(item{Name: in.Name + "!"})
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var out item
+	status, err := c.DoXML(context.Background(), http.MethodPost, "/items", item{Name: "widget"}, &out)
+	if err != nil || status != 200 {
+		t.Fatalf("unexpected result: %d, %v", status, err)
+	}
+	if out.Name != "widget!" {
+		t.Fatalf("expected decoded XML response, got %+v", out)
+	}
+}
+
+// upperCodec is a toy text codec used to exercise DoWith.
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v any) ([]byte, error) { return []byte(strings.ToUpper(v.(string))), nil }
+func (upperCodec) Unmarshal(data []byte, v any) error {
+	*v.(*string) = string(data)
+	return nil
+}
+func (upperCodec) ContentType() string { return "text/x-upper" }
+
+func TestDoWith(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Header.Get("Content-Type") + " " + string(body)))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var out string
+	if _, err := c.DoWith(context.Background(), upperCodec{}, http.MethodPost, "/", "hi", &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != "text/x-upper HI" {
+		t.Fatalf("unexpected response: %q", out)
+	}
+}
@@ -0,0 +1,142 @@
+package resilient
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// Bounds and starting point of the adaptive concurrency limit.
+	adaptiveConcurrencyInitial = 20
+	adaptiveConcurrencyMin     = 1
+	adaptiveConcurrencyMax     = 1000
+
+	// adaptiveConcurrencyBackoff is the multiplicative decrease applied when
+	// an attempt fails or the upstream signals overload.
+	adaptiveConcurrencyBackoff = 0.9
+)
+
+// concurrencyLimiter is a resizable semaphore whose limit follows the
+// gradient of observed latency, in the style of Netflix's concurrency-limits:
+// it grows while latency stays at its long-term level and shrinks as soon as
+// requests start queueing upstream.
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	limit    float64
+	max      float64
+	inflight int
+	waiters  []chan struct{}
+
+	samples  int
+	shortRTT time.Duration
+	longRTT  time.Duration
+}
+
+func newConcurrencyLimiter(max int) *concurrencyLimiter {
+	if max <= 0 {
+		max = adaptiveConcurrencyMax
+	}
+	return &concurrencyLimiter{
+		limit: math.Min(adaptiveConcurrencyInitial, float64(max)),
+		max:   float64(max),
+	}
+}
+
+// acquire takes a slot, waiting until one is free or ctx is done. It
+// reports whether the caller had to wait.
+func (l *concurrencyLimiter) acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	if l.inflight < int(l.limit) {
+		l.inflight++
+		l.mu.Unlock()
+		return false, nil
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return true, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-ready:
+			// Granted while giving up; pass the slot on.
+			l.inflight--
+			l.grant()
+		default:
+			for i, w := range l.waiters {
+				if w == ready {
+					l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+					break
+				}
+			}
+		}
+		return true, ctx.Err()
+	}
+}
+
+// release frees a slot and adjusts the limit from the attempt's outcome.
+// dropped marks attempts that failed or were rejected for overload.
+func (l *concurrencyLimiter) release(rtt time.Duration, dropped bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.update(rtt, dropped)
+	l.inflight--
+	l.grant()
+}
+
+// abandon frees a slot without adjusting the limit, for an attempt that was
+// never sent.
+func (l *concurrencyLimiter) abandon() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	l.grant()
+}
+
+// grant hands free slots to waiters in FIFO order. It must be called with
+// l.mu held.
+func (l *concurrencyLimiter) grant() {
+	for len(l.waiters) > 0 && l.inflight < int(l.limit) {
+		l.inflight++
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+	}
+}
+
+// update applies the gradient algorithm. It must be called with l.mu held.
+func (l *concurrencyLimiter) update(rtt time.Duration, dropped bool) {
+	if dropped {
+		l.limit = math.Max(adaptiveConcurrencyMin, l.limit*adaptiveConcurrencyBackoff)
+		return
+	}
+
+	l.samples++
+	if l.samples == 1 {
+		l.shortRTT, l.longRTT = rtt, rtt
+		return
+	}
+	l.shortRTT += (rtt - l.shortRTT) / 8
+	l.longRTT += (rtt - l.longRTT) / 128
+
+	// Only grow when the limit is actually being used.
+	if float64(l.inflight)*2 < l.limit {
+		return
+	}
+	gradient := math.Max(0.5, math.Min(1, float64(l.longRTT)/float64(l.shortRTT)))
+	next := l.limit*gradient + math.Sqrt(l.limit)
+	l.limit = math.Max(adaptiveConcurrencyMin, math.Min(l.max, l.limit*0.8+next*0.2))
+}
+
+// Limit returns the current concurrency limit.
+func (l *concurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
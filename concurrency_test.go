@@ -0,0 +1,99 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiterShrinksOnDrops(t *testing.T) {
+	l := newConcurrencyLimiter(0)
+	for i := 0; i < 10; i++ {
+		if _, err := l.acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		l.release(time.Millisecond, true)
+	}
+	if got := l.Limit(); got >= adaptiveConcurrencyInitial {
+		t.Fatalf("expected limit to shrink below %d, got %d", adaptiveConcurrencyInitial, got)
+	}
+}
+
+func TestConcurrencyLimiterGradient(t *testing.T) {
+	l := newConcurrencyLimiter(100)
+	l.inflight = 20 // saturated
+
+	for i := 0; i < 20; i++ {
+		l.update(10*time.Millisecond, false)
+	}
+	grown := l.limit
+	if grown <= adaptiveConcurrencyInitial {
+		t.Fatalf("expected limit to grow at steady latency, got %v", grown)
+	}
+
+	l.inflight = int(grown)
+	for i := 0; i < 20; i++ {
+		l.update(50*time.Millisecond, false)
+	}
+	if l.limit >= grown {
+		t.Fatalf("expected limit to shrink when latency rises, got %v (was %v)", l.limit, grown)
+	}
+}
+
+func TestConcurrencyLimiterCancel(t *testing.T) {
+	l := newConcurrencyLimiter(1)
+	if _, err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if waited, err := l.acquire(ctx); !waited || err == nil {
+		t.Fatalf("expected to wait and time out, got waited=%v err=%v", waited, err)
+	}
+
+	l.release(time.Millisecond, false)
+	if l.inflight != 0 || len(l.waiters) != 0 {
+		t.Fatalf("expected no leaked slots, inflight=%d waiters=%d", l.inflight, len(l.waiters))
+	}
+}
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithAdaptiveConcurrency(), WithMaxConcurrent(4))
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get(context.Background(), "/")
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > 4 {
+		t.Fatalf("expected at most 4 in flight, saw %d", p)
+	}
+	if s := c.Stats(); s.ConcurrencyLimit < 1 || s.ConcurrencyLimit > 4 {
+		t.Fatalf("expected limit within [1, 4], got %d", s.ConcurrencyLimit)
+	}
+}
@@ -0,0 +1,185 @@
+package resilient
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the tunable settings of a Client in a form that can be
+// loaded from JSON or YAML files or from environment variables, so rate
+// limits, retries and timeouts can be changed without code changes:
+//
+//	var cfg resilient.Config
+//	json.Unmarshal(data, &cfg)
+//	if err := cfg.FromEnv("GITHUB"); err != nil { ... }
+//	client := resilient.NewFromConfig(cfg, resilient.WithLogger(logger))
+//
+// Zero fields keep the client's defaults. Durations are written as in
+// time.ParseDuration, e.g. "1.5s".
+type Config struct {
+	BaseURL      string   `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+	FallbackURLs []string `json:"fallback_urls,omitempty" yaml:"fallback_urls,omitempty"`
+
+	RateLimit        float64  `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+	Burst            int      `json:"burst,omitempty" yaml:"burst,omitempty"`
+	AdaptiveCooldown Duration `json:"adaptive_cooldown,omitempty" yaml:"adaptive_cooldown,omitempty"`
+	MaxConcurrent    int      `json:"max_concurrent,omitempty" yaml:"max_concurrent,omitempty"`
+
+	// MaxRetries is a pointer so that 0, which turns retries off, can be
+	// told apart from unset.
+	MaxRetries     *int     `json:"max_retries,omitempty" yaml:"max_retries,omitempty"`
+	RetryBackoff   Duration `json:"retry_backoff,omitempty" yaml:"retry_backoff,omitempty"`
+	MaxBackoff     Duration `json:"max_backoff,omitempty" yaml:"max_backoff,omitempty"`
+	MaxElapsedTime Duration `json:"max_elapsed_time,omitempty" yaml:"max_elapsed_time,omitempty"`
+
+	Timeout         Duration          `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	MaxResponseSize int64             `json:"max_response_size,omitempty" yaml:"max_response_size,omitempty"`
+	UserAgent       string            `json:"user_agent,omitempty" yaml:"user_agent,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
+// Duration is a time.Duration that reads and writes as text such as "30s"
+// in JSON, YAML and environment variables.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("resilient: parse duration: %w", err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// FromEnv overrides the fields of c that are set in the environment. Each
+// variable is named prefix, an underscore and the field's upper-cased JSON
+// name, such as GITHUB_RATE_LIMIT or GITHUB_MAX_RETRIES; an empty prefix
+// drops the underscore. Lists are comma-separated and Headers are written
+// as "Name=value,Other=value".
+func (c *Config) FromEnv(prefix string) error {
+	if prefix != "" {
+		prefix += "_"
+	}
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		key := prefix + strings.ToUpper(name)
+		val, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := setFromEnv(v.Field(i), val); err != nil {
+			return fmt.Errorf("resilient: %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+var durationType = reflect.TypeFor[Duration]()
+
+func setFromEnv(f reflect.Value, val string) error {
+	if f.Type() == durationType {
+		return f.Addr().Interface().(*Duration).UnmarshalText([]byte(val))
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(val)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Pointer:
+		p := reflect.New(f.Type().Elem())
+		if err := setFromEnv(p.Elem(), val); err != nil {
+			return err
+		}
+		f.Set(p)
+	case reflect.Slice:
+		list := strings.Split(val, ",")
+		for i := range list {
+			list[i] = strings.TrimSpace(list[i])
+		}
+		f.Set(reflect.ValueOf(list))
+	case reflect.Map:
+		m := make(map[string]string)
+		for pair := range strings.SplitSeq(val, ",") {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("expected Name=value, got %q", pair)
+			}
+			m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		f.Set(reflect.ValueOf(m))
+	}
+	return nil
+}
+
+// Options returns the options equivalent to c.
+func (c Config) Options() []Option {
+	var opts []Option
+	if c.BaseURL != "" {
+		opts = append(opts, WithBaseURLs(c.BaseURL, c.FallbackURLs...))
+	}
+	if c.RateLimit > 0 {
+		opts = append(opts, WithRateLimit(c.RateLimit, c.Burst))
+	}
+	if c.AdaptiveCooldown > 0 {
+		opts = append(opts, WithAdaptive(time.Duration(c.AdaptiveCooldown)))
+	}
+	if c.MaxConcurrent > 0 {
+		opts = append(opts, WithMaxConcurrent(c.MaxConcurrent))
+	}
+	if c.MaxRetries != nil {
+		n := *c.MaxRetries
+		opts = append(opts, func(cfg *config) { cfg.maxRetries = n })
+	}
+	if c.RetryBackoff > 0 {
+		d := time.Duration(c.RetryBackoff)
+		opts = append(opts, func(cfg *config) { cfg.initialBackoff = d })
+	}
+	if c.MaxBackoff > 0 {
+		opts = append(opts, WithMaxBackoff(time.Duration(c.MaxBackoff)))
+	}
+	if c.MaxElapsedTime > 0 {
+		opts = append(opts, WithMaxElapsedTime(time.Duration(c.MaxElapsedTime)))
+	}
+	if c.Timeout > 0 {
+		opts = append(opts, WithTimeout(time.Duration(c.Timeout)))
+	}
+	if c.MaxResponseSize > 0 {
+		opts = append(opts, WithMaxResponseSize(c.MaxResponseSize))
+	}
+	if c.UserAgent != "" {
+		opts = append(opts, WithUserAgent(c.UserAgent))
+	}
+	if len(c.Headers) > 0 {
+		opts = append(opts, WithDefaultHeaders(c.Headers))
+	}
+	return opts
+}
+
+// NewFromConfig creates a Client from cfg. The options in opts are applied
+// after the configuration, for settings that cannot be expressed in it
+// such as hooks and loggers.
+func NewFromConfig(cfg Config, opts ...Option) *Client {
+	return New(append(cfg.Options(), opts...)...)
+}
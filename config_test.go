@@ -0,0 +1,59 @@
+package resilient
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestConfigJSON(t *testing.T) {
+	var cfg Config
+	data := `{"base_url": "https://api.example.com", "rate_limit": 5, "burst": 2,
+		"max_retries": 0, "retry_backoff": "250ms", "timeout": "3s",
+		"headers": {"X-Team": "payments"}}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewFromConfig(cfg, WithUserAgent("ops"))
+	defer c.Close()
+	got := c.cfg
+	if got.baseURL != "https://api.example.com" || got.rps != 5 || got.burst != 2 {
+		t.Fatalf("unexpected rate settings %+v", got)
+	}
+	if got.maxRetries != 0 || got.initialBackoff != 250*time.Millisecond || c.httpClient.Timeout != 3*time.Second {
+		t.Fatalf("unexpected retry settings: %d retries, %v backoff, %v timeout", got.maxRetries, got.initialBackoff, c.httpClient.Timeout)
+	}
+	if got.defaultHeaders.Get("X-Team") != "payments" || got.defaultHeaders.Get("User-Agent") != "ops" {
+		t.Fatalf("unexpected headers %v", got.defaultHeaders)
+	}
+
+	out, _ := json.Marshal(Config{Timeout: Duration(1500 * time.Millisecond)})
+	if string(out) != `{"timeout":"1.5s"}` {
+		t.Fatalf("unexpected encoding %s", out)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_RATE_LIMIT", "10")
+	t.Setenv("GITHUB_MAX_RETRIES", "5")
+	t.Setenv("GITHUB_MAX_BACKOFF", "1m")
+	t.Setenv("GITHUB_FALLBACK_URLS", "https://a.example.com, https://b.example.com")
+	t.Setenv("GITHUB_HEADERS", "X-A=1,X-B=2")
+
+	cfg := Config{BaseURL: "https://api.github.com", RateLimit: 1}
+	if err := cfg.FromEnv("GITHUB"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL != "https://api.github.com" || cfg.RateLimit != 10 || *cfg.MaxRetries != 5 || cfg.MaxBackoff != Duration(time.Minute) {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if len(cfg.FallbackURLs) != 2 || cfg.FallbackURLs[1] != "https://b.example.com" || cfg.Headers["X-B"] != "2" {
+		t.Fatalf("unexpected lists %v %v", cfg.FallbackURLs, cfg.Headers)
+	}
+
+	t.Setenv("GITHUB_TIMEOUT", "soon")
+	if err := cfg.FromEnv("GITHUB"); err == nil {
+		t.Fatal("expected an error for an invalid duration")
+	}
+}
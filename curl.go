@@ -0,0 +1,101 @@
+package resilient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxCurlBody is the largest request body inlined into a curl command.
+const maxCurlBody = 64 << 10
+
+// CurlError carries a copy-pasteable curl command reproducing the last
+// attempt of a failed call; see WithCurlOnError. Its message is that of the
+// underlying error.
+type CurlError struct {
+	Command string
+	err     error
+}
+
+func (e *CurlError) Error() string { return e.err.Error() }
+
+func (e *CurlError) Unwrap() error { return e.err }
+
+// CurlCommand renders req as a curl command line for reproducing it by
+// hand. The values of Authorization, Proxy-Authorization, Cookie, Set-Cookie
+// and X-Api-Key are redacted, as are those of the extra headers named in
+// redact, the URL's password and query parameters that commonly carry
+// secrets, such as api_key or access_token. The body is included when it
+// can be re-created with GetBody.
+func CurlCommand(req *http.Request, redact ...string) string {
+	cfg := &config{redactHeaders: redact}
+	return curlCommand(req, redactedHeaders(cfg))
+}
+
+// CurlCommand is the package-level CurlCommand with the client's default
+// headers applied and its WithRedactedHeaders and WithAPIKeyPool headers
+// redacted. Headers added per attempt, such as signatures, are only part of
+// the commands attached by WithCurlOnError.
+func (c *Client) CurlCommand(req *http.Request) string {
+	return curlCommand(c.withDefaultHeaders(req), c.redact)
+}
+
+func curlCommand(req *http.Request, names map[string]bool) string {
+	var b strings.Builder
+	b.WriteString("curl")
+	body, hasBody := curlBody(req)
+	if req.Method != http.MethodGet || hasBody {
+		b.WriteString(" -X " + req.Method)
+	}
+	b.WriteString(" " + shellQuote(redactURL(req.URL, names).Redacted()))
+
+	header := redact(req.Header, names)
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			b.WriteString(" -H " + shellQuote(k+": "+v))
+		}
+	}
+	if req.Host != "" && req.Host != req.URL.Host {
+		b.WriteString(" -H " + shellQuote("Host: "+req.Host))
+	}
+	if hasBody {
+		b.WriteString(" --data-binary " + shellQuote(body))
+	}
+	return b.String()
+}
+
+// curlBody returns req's body for a curl command, read through GetBody so
+// req itself is left untouched.
+func curlBody(req *http.Request) (string, bool) {
+	if req.GetBody == nil || req.Body == nil || req.Body == http.NoBody {
+		return "", false
+	}
+	rc, err := req.GetBody()
+	if err != nil {
+		return fmt.Sprintf("<body unavailable: %v>", err), true
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxCurlBody+1))
+	switch {
+	case err != nil:
+		return fmt.Sprintf("<body unavailable: %v>", err), true
+	case len(data) > maxCurlBody:
+		return fmt.Sprintf("<body over %d bytes omitted>", maxCurlBody), true
+	case !utf8.Valid(data):
+		return fmt.Sprintf("<%d bytes of binary data omitted>", len(data)), true
+	}
+	return string(data), true
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,103 @@
+package resilient
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Decoder decodes one HTTP content coding for WithDecompression.
+type Decoder interface {
+	// Encoding is the content-coding token, e.g. "gzip".
+	Encoding() string
+	// NewReader returns a reader of the decoded body.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// Built-in decoders. Brotli and zstd decoders live in the resilientcompress
+// sub-package.
+var (
+	Gzip    Decoder = gzipDecoder{}
+	Deflate Decoder = deflateDecoder{}
+)
+
+type gzipDecoder struct{}
+
+func (gzipDecoder) Encoding() string                             { return "gzip" }
+func (gzipDecoder) NewReader(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+
+type deflateDecoder struct{}
+
+func (deflateDecoder) Encoding() string { return "deflate" }
+
+// NewReader decodes "deflate" as RFC 9110 defines it, zlib-wrapped, and
+// falls back to raw DEFLATE for the servers that send it unwrapped.
+func (deflateDecoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	if h, err := br.Peek(2); err == nil && isZlibHeader(h) {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// isZlibHeader reports whether h starts a zlib stream (RFC 1950): the
+// DEFLATE method with a valid header checksum.
+func isZlibHeader(h []byte) bool {
+	return h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0
+}
+
+// decompress replaces resp.Body with a decoding reader when the response
+// uses one of the configured content codings, as net/http does for gzip.
+// Responses without a body (HEAD, 204, 304 or empty) are left alone.
+func (c *Client) decompress(resp *http.Response) error {
+	if len(c.cfg.decoders) == 0 || !hasBody(resp) {
+		return nil
+	}
+	enc := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	d, ok := c.cfg.decoders[enc]
+	if !ok {
+		return nil
+	}
+	br := bufio.NewReader(resp.Body)
+	if _, err := br.Peek(1); err == io.EOF {
+		return nil // empty body of unknown length
+	}
+	r, err := d.NewReader(br)
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", enc, err)
+	}
+	resp.Body = &decodedBody{ReadCloser: r, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// hasBody reports whether resp may carry a body to decode.
+func hasBody(resp *http.Response) bool {
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusNotModified:
+		return false
+	}
+	return resp.ContentLength != 0
+}
+
+// decodedBody closes both the decoder and the underlying body.
+type decodedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	b.ReadCloser.Close()
+	return b.raw.Close()
+}
@@ -0,0 +1,133 @@
+package resilient
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecompression(t *testing.T) {
+	payload := bytes.Repeat([]byte("a"), 10_000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("unexpected Accept-Encoding %q", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(gzipBytes(payload))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithDecompression())
+	defer c.Close()
+
+	res, err := c.DoResult(context.Background(), mustRequest(t, srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res.Body, payload) {
+		t.Fatalf("expected decoded body, got %d bytes", len(res.Body))
+	}
+	if res.Header.Get("Content-Encoding") != "" {
+		t.Fatal("expected Content-Encoding to be removed after decoding")
+	}
+}
+
+func TestDecompressionSizeLimit(t *testing.T) {
+	// 1 MB of zeros compresses to about 1 KB.
+	bomb := gzipBytes(make([]byte, 1<<20))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(bomb)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithDecompression(Gzip), WithMaxResponseSize(4096))
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/")
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("expected ErrResponseTooLarge, got %v", err)
+	}
+	if len(body) != 4096 {
+		t.Fatalf("expected decoded body capped at 4096 bytes, got %d", len(body))
+	}
+}
+
+func TestDecompressionDeflate(t *testing.T) {
+	payload := bytes.Repeat([]byte("deflate "), 1000)
+	var zlibBody, rawBody bytes.Buffer
+	zw := zlib.NewWriter(&zlibBody)
+	zw.Write(payload)
+	zw.Close()
+	fw, _ := flate.NewWriter(&rawBody, flate.DefaultCompression)
+	fw.Write(payload)
+	fw.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "deflate")
+		if r.URL.Path == "/raw" {
+			w.Write(rawBody.Bytes())
+			return
+		}
+		w.Write(zlibBody.Bytes())
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithDecompression(Deflate))
+	defer c.Close()
+
+	for _, path := range []string{"/zlib", "/raw"} {
+		body, _, err := c.Get(context.Background(), path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if !bytes.Equal(body, payload) {
+			t.Fatalf("%s: expected decoded body, got %d bytes", path, len(body))
+		}
+	}
+}
+
+func TestDecompressionNoBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		switch r.URL.Path {
+		case "/204":
+			w.WriteHeader(http.StatusNoContent)
+		case "/304":
+			w.WriteHeader(http.StatusNotModified)
+		case "/chunked":
+			w.(http.Flusher).Flush() // empty body of unknown length
+		case "/head":
+			w.Header().Set("Content-Length", "120")
+		}
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithDecompression(), WithRetry(0, 0))
+	defer c.Close()
+	ctx := context.Background()
+
+	for _, path := range []string{"/204", "/304", "/empty", "/chunked"} {
+		if _, _, err := c.Get(ctx, path); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	req, _ := c.NewRequest(ctx, http.MethodHead, "/head", nil)
+	if _, _, err := c.Do(ctx, req); err != nil {
+		t.Fatalf("HEAD: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package resilient
+
+import (
+	"slices"
+	"strings"
+)
+
+// With returns a client derived from c with opts applied on top of c's
+// options. The derived client shares c's transport, connection pool, rate
+// limiters, caches, concurrency limits and statistics, so it is cheap to
+// create per use case:
+//
+//	admin := client.With(resilient.WithRetry(0, 0), resilient.WithHeader("X-Admin", "1"))
+//
+// Options that act per call take effect: retries and backoff, default
+// headers, the base URL, timeouts, hooks and callbacks. Options that build
+// shared state (WithRateLimit, WithMaxConcurrent, WithCache, TLS and proxy
+// settings and the like) are ignored. Close on a derived client does
+// nothing; closing c releases the shared state.
+func (c *Client) With(opts ...Option) *Client {
+	cfg := c.cfg.clone()
+	for _, o := range opts {
+		o(cfg)
+	}
+	if cfg.userAgent != c.cfg.userAgent && cfg.userAgent != "" {
+		WithHeader("User-Agent", cfg.userAgent)(cfg)
+	}
+
+	d := &Client{
+		httpClient:  c.httpClient,
+		endpoints:   c.endpoints,
+		service:     c.service,
+		redact:      redactedHeaders(cfg),
+		cfg:         cfg,
+		derived:     true,
+		clientState: c.clientState,
+	}
+	if cfg.timeout != c.cfg.timeout && c.cfg.httpClient == nil {
+		hc := *c.httpClient
+		hc.Timeout = cfg.timeout
+		d.httpClient = &hc
+	}
+	if cfg.baseURL != c.cfg.baseURL || !slices.Equal(cfg.fallbackURLs, c.cfg.fallbackURLs) {
+		d.endpoints = newEndpointPool(cfg)
+	}
+	if cfg.service != c.cfg.service {
+		d.service = newServicePool(cfg)
+	}
+	return d
+}
+
+// Group returns a client derived with With whose convenience methods join
+// their paths onto prefix, like a router group:
+//
+//	admin := client.Group("/v2/admin", resilient.WithHeader("X-Admin-Token", token))
+//	admin.Get(ctx, "/users") // GET {baseURL}/v2/admin/users
+//
+// Groups nest, each prefix adding to its parent's. Requests passed to Do
+// with a full URL are not affected.
+func (c *Client) Group(prefix string, opts ...Option) *Client {
+	g := c.With(opts...)
+	g.cfg.pathPrefix += strings.TrimSuffix(prefix, "/")
+	return g
+}
@@ -0,0 +1,85 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWith(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/v2/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(r.URL.Path + " " + r.Header.Get("X-Team") + " " + r.Header.Get("X-Admin")))
+	}))
+	defer srv.Close()
+
+	parent := New(WithBaseURL(srv.URL), WithHeader("X-Team", "payments"), WithRetry(2, time.Millisecond), WithRateLimit(1000, 10))
+	defer parent.Close()
+	child := parent.With(WithBaseURL(srv.URL+"/v2"), WithHeader("X-Admin", "1"), WithRetry(0, 0))
+
+	body, _, err := child.Get(context.Background(), "/users")
+	if err != nil || string(body) != "/v2/users payments 1" {
+		t.Fatalf("expected the child's base URL and headers, got %q %v", body, err)
+	}
+	body, _, err = parent.Get(context.Background(), "/users")
+	if err != nil || string(body) != "/users payments " {
+		t.Fatalf("expected the parent to be unchanged, got %q %v", body, err)
+	}
+
+	calls.Store(0)
+	if _, _, err := child.Get(context.Background(), "/fail"); err == nil || calls.Load() != 1 {
+		t.Fatalf("expected a single attempt without retries, got %d calls, err %v", calls.Load(), err)
+	}
+
+	if s := parent.Stats(); s.TotalRequests != 3 {
+		t.Fatalf("expected stats shared with the child, got %d requests", s.TotalRequests)
+	}
+	if child.limiter != parent.limiter || child.httpClient.Transport != parent.httpClient.Transport {
+		t.Fatal("expected the limiter and transport to be shared")
+	}
+
+	child.Close()
+	if parent.closed {
+		t.Fatal("expected closing the child to leave the parent open")
+	}
+}
+
+func TestGroup(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path + " " + r.Header.Get("X-Scope")))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+	admin := c.Group("/v2/admin/", WithDefaultHeaders(map[string]string{"X-Scope": "admin"}))
+	users := admin.Group("/users")
+
+	for _, tc := range []struct {
+		client *Client
+		path   string
+		want   string
+	}{
+		{admin, "/stats", "/v2/admin/stats admin"},
+		{users, "/{id}", "/v2/admin/users/42 admin"},
+		{c, "/stats", "/stats "},
+	} {
+		body, _, err := tc.client.Get(context.Background(), tc.path, PathParam("id", "42"))
+		if err != nil || string(body) != tc.want {
+			t.Fatalf("expected %q, got %q %v", tc.want, body, err)
+		}
+	}
+
+	req, _ := users.NewRequest(context.Background(), http.MethodGet, "/{id}", nil, PathParam("id", "42"))
+	if r := Route(req); r != "/v2/admin/users/{id}" {
+		t.Fatalf("expected the route to include the prefix, got %q", r)
+	}
+}
@@ -0,0 +1,208 @@
+package resilient
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// digestAuth tracks the Digest challenge of each host and its nonce count.
+// Requests are only authorized for a host that challenged the client, so
+// the credentials aren't offered to other hosts, such as failover
+// endpoints or absolute URLs.
+type digestAuth struct {
+	username string
+	password string
+
+	mu    sync.Mutex
+	hosts map[string]*digestState // by URL host
+}
+
+// digestState is the last challenge issued by a host and the number of
+// requests sent with its nonce.
+type digestState struct {
+	chal *digestChallenge
+	nc   uint32
+}
+
+// digestChallenge holds the parameters of a "WWW-Authenticate: Digest"
+// header.
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string // "auth" or "" for the legacy RFC 2069 scheme
+}
+
+func newDigestAuth(cfg *config) *digestAuth {
+	if cfg.digestUser == "" {
+		return nil
+	}
+	return &digestAuth{username: cfg.digestUser, password: cfg.digestPass, hosts: make(map[string]*digestState)}
+}
+
+// challenge records the Digest challenge of a 401 response for the host
+// that sent it and reports whether the request is worth retrying with it.
+// The nonce count restarts only when the realm or nonce changes.
+func (d *digestAuth) challenge(resp *http.Response) bool {
+	if resp.Request == nil {
+		return false
+	}
+	for _, v := range resp.Header.Values("WWW-Authenticate") {
+		chal, ok := parseDigestChallenge(v)
+		if !ok {
+			continue
+		}
+		d.mu.Lock()
+		st := d.hosts[resp.Request.URL.Host]
+		if st == nil || st.chal.realm != chal.realm || st.chal.nonce != chal.nonce {
+			d.hosts[resp.Request.URL.Host] = &digestState{chal: chal}
+		} else {
+			st.chal = chal
+		}
+		d.mu.Unlock()
+		return true
+	}
+	return false
+}
+
+// authorize sets the Authorization header for req from the challenge of
+// its host, if one has been received.
+func (d *digestAuth) authorize(req *http.Request) {
+	d.mu.Lock()
+	st := d.hosts[req.URL.Host]
+	if st == nil {
+		d.mu.Unlock()
+		return
+	}
+	chal := st.chal
+	st.nc++
+	nc := st.nc
+	d.mu.Unlock()
+
+	newHash := md5.New
+	if strings.HasPrefix(strings.ToUpper(chal.algorithm), "SHA-256") {
+		newHash = sha256.New
+	}
+	h := func(s string) string {
+		return hashHex(newHash, s)
+	}
+
+	uri := req.URL.RequestURI()
+	cnonce := randomHex(16)
+	ncHex := fmt.Sprintf("%08x", nc)
+
+	ha1 := h(d.username + ":" + chal.realm + ":" + d.password)
+	if strings.HasSuffix(strings.ToLower(chal.algorithm), "-sess") {
+		ha1 = h(ha1 + ":" + chal.nonce + ":" + cnonce)
+	}
+	ha2 := h(req.Method + ":" + uri)
+
+	var response string
+	if chal.qop != "" {
+		response = h(strings.Join([]string{ha1, chal.nonce, ncHex, cnonce, chal.qop, ha2}, ":"))
+	} else {
+		response = h(ha1 + ":" + chal.nonce + ":" + ha2)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username=%q, realm=%q, nonce=%q, uri=%q, response=%q`,
+		d.username, chal.realm, chal.nonce, uri, response)
+	if chal.algorithm != "" {
+		fmt.Fprintf(&b, ", algorithm=%s", chal.algorithm)
+	}
+	if chal.qop != "" {
+		fmt.Fprintf(&b, ", qop=%s, nc=%s, cnonce=%q", chal.qop, ncHex, cnonce)
+	}
+	if chal.opaque != "" {
+		fmt.Fprintf(&b, ", opaque=%q", chal.opaque)
+	}
+	req.Header.Set("Authorization", b.String())
+}
+
+// parseDigestChallenge parses a Digest WWW-Authenticate value. Challenges
+// that only offer qop=auth-int or an unknown algorithm are rejected.
+func parseDigestChallenge(v string) (*digestChallenge, bool) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(v), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return nil, false
+	}
+	params := parseAuthParams(rest)
+	chal := &digestChallenge{
+		realm:     params["realm"],
+		nonce:     params["nonce"],
+		opaque:    params["opaque"],
+		algorithm: params["algorithm"],
+	}
+	if chal.nonce == "" {
+		return nil, false
+	}
+	switch strings.ToUpper(chal.algorithm) {
+	case "", "MD5", "MD5-SESS", "SHA-256", "SHA-256-SESS":
+	default:
+		return nil, false
+	}
+	if qop, ok := params["qop"]; ok {
+		for _, q := range strings.Split(qop, ",") {
+			if strings.TrimSpace(q) == "auth" {
+				chal.qop = "auth"
+			}
+		}
+		if chal.qop == "" {
+			return nil, false
+		}
+	}
+	return chal, true
+}
+
+// parseAuthParams splits comma-separated name=value pairs, where values
+// may be quoted strings containing commas.
+func parseAuthParams(s string) map[string]string {
+	params := map[string]string{}
+	for s != "" {
+		s = strings.TrimLeft(s, " ,")
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		rest = strings.TrimLeft(rest, " ")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var b strings.Builder
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+			value, s = b.String(), rest[min(i+1, len(rest)):]
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		params[name] = value
+	}
+	return params
+}
+
+func hashHex(newHash func() hash.Hash, s string) string {
+	h := newHash()
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
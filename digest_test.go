@@ -0,0 +1,153 @@
+package resilient
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// digestServer requires Digest credentials user/pass and rotates its
+// nonce every 3 authorized requests.
+func digestServer(t *testing.T, algorithm string, challenges *atomic.Int32) *httptest.Server {
+	var served atomic.Int32
+	nonce := func() string { return fmt.Sprintf("nonce-%d", served.Load()/3) }
+	h := func(s string) string {
+		if strings.HasPrefix(algorithm, "SHA-256") {
+			return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+		}
+		return fmt.Sprintf("%x", md5.Sum([]byte(s)))
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		p := parseAuthParams(strings.TrimPrefix(auth, "Digest "))
+		ha1 := h("user:realm@test:pass")
+		ha2 := h(r.Method + ":" + r.URL.RequestURI())
+		want := h(strings.Join([]string{ha1, p["nonce"], p["nc"], p["cnonce"], "auth", ha2}, ":"))
+		if strings.HasPrefix(auth, "Digest ") && p["response"] == want && p["uri"] == r.URL.RequestURI() {
+			if p["nonce"] == nonce() {
+				served.Add(1)
+				w.Write([]byte("ok " + p["nc"]))
+				return
+			}
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="realm@test", nonce=%q, qop="auth,auth-int", algorithm=%s, opaque="xyz", stale=true`, nonce(), algorithm))
+		} else {
+			w.Header().Add("WWW-Authenticate", `Basic realm="other"`)
+			w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Digest realm="realm@test", nonce=%q, qop="auth,auth-int", algorithm=%s, opaque="xyz"`, nonce(), algorithm))
+		}
+		challenges.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+}
+
+func TestDigestAuth(t *testing.T) {
+	for _, alg := range []string{"MD5", "SHA-256"} {
+		t.Run(alg, func(t *testing.T) {
+			var challenges atomic.Int32
+			srv := digestServer(t, alg, &challenges)
+			defer srv.Close()
+
+			c := New(WithBaseURL(srv.URL), WithDigestAuth("user", "pass"))
+			defer c.Close()
+
+			var bodies []string
+			for i := range 4 {
+				body, status, err := c.Get(context.Background(), fmt.Sprintf("/dir/index.html?n=%d", i))
+				if err != nil || status != 200 {
+					t.Fatalf("request %d: %d %v", i, status, err)
+				}
+				bodies = append(bodies, string(body))
+			}
+			// One challenge up front, one stale nonce after 3 requests.
+			if n := challenges.Load(); n != 2 {
+				t.Fatalf("expected 2 challenges, got %d", n)
+			}
+			if got := strings.Join(bodies, ","); got != "ok 00000001,ok 00000002,ok 00000003,ok 00000001" {
+				t.Fatalf("unexpected nonce counts %q", got)
+			}
+		})
+	}
+}
+
+func TestDigestWrongPassword(t *testing.T) {
+	var challenges atomic.Int32
+	srv := digestServer(t, "MD5", &challenges)
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithDigestAuth("user", "wrong"))
+	defer c.Close()
+
+	if _, status, err := c.Get(context.Background(), "/"); err == nil || status != 401 || challenges.Load() != 2 {
+		t.Fatalf("expected a single retry then 401, got %d %v after %d challenges", status, err, challenges.Load())
+	}
+}
+
+func TestParseDigestChallenge(t *testing.T) {
+	chal, ok := parseDigestChallenge(`Digest realm="a, b", nonce="n\"1", qop="auth-int"`)
+	if ok {
+		t.Fatalf("expected auth-int only challenge to be rejected, got %+v", chal)
+	}
+	chal, ok = parseDigestChallenge(`Digest realm="a, b", nonce="n\"1"`)
+	if !ok || chal.realm != "a, b" || chal.nonce != `n"1` || chal.qop != "" {
+		t.Fatalf("unexpected challenge %+v", chal)
+	}
+	if _, ok := parseDigestChallenge(`Basic realm="x"`); ok {
+		t.Fatal("expected Basic challenge to be ignored")
+	}
+}
+
+func TestDigestAuthPerHost(t *testing.T) {
+	var challenges atomic.Int32
+	srv := digestServer(t, "MD5", &challenges)
+	defer srv.Close()
+	var other atomic.Value
+	other.Store("")
+	otherSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		other.Store(r.Header.Get("Authorization"))
+	}))
+	defer otherSrv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithDigestAuth("user", "pass"))
+	defer c.Close()
+
+	if _, status, err := c.Get(context.Background(), "/"); err != nil || status != 200 {
+		t.Fatalf("expected digest auth to succeed, got %d %v", status, err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, otherSrv.URL+"/", nil)
+	if _, _, err := c.Do(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if auth := other.Load().(string); auth != "" {
+		t.Fatalf("expected no credentials for a host that didn't challenge, got %q", auth)
+	}
+}
+
+func TestDigestNonceCountSurvivesRechallenge(t *testing.T) {
+	d := &digestAuth{username: "user", password: "pass", hosts: make(map[string]*digestState)}
+	req := httptest.NewRequest(http.MethodGet, "http://api.test/", nil)
+	resp := func(nonce string) *http.Response {
+		h := http.Header{}
+		h.Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="r", nonce=%q, qop="auth"`, nonce))
+		return &http.Response{Header: h, Request: req}
+	}
+	nc := func() string {
+		d.authorize(req)
+		return parseAuthParams(strings.TrimPrefix(req.Header.Get("Authorization"), "Digest "))["nc"]
+	}
+
+	d.challenge(resp("n1"))
+	nc()
+	d.challenge(resp("n1"))
+	if got := nc(); got != "00000002" {
+		t.Fatalf("expected the count to continue for the same nonce, got %s", got)
+	}
+	d.challenge(resp("n2"))
+	if got := nc(); got != "00000001" {
+		t.Fatalf("expected the count to restart for a new nonce, got %s", got)
+	}
+}
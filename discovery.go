@@ -0,0 +1,182 @@
+package resilient
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serviceRefresh is how long resolved service instances are reused before
+// the resolver is asked again.
+const serviceRefresh = 30 * time.Second
+
+// Resolver finds the instances of a service for WithService. Each URL is
+// the base URL of one instance, such as http://10.0.0.7:8080.
+type Resolver interface {
+	Resolve(ctx context.Context, service string) ([]url.URL, error)
+}
+
+// StaticResolver maps service names to fixed lists of instance base URLs.
+type StaticResolver map[string][]string
+
+// Resolve implements Resolver.
+func (r StaticResolver) Resolve(_ context.Context, service string) ([]url.URL, error) {
+	bases, ok := r[service]
+	if !ok {
+		return nil, errors.New("no such service")
+	}
+	urls := make([]url.URL, 0, len(bases))
+	for _, base := range bases {
+		u, err := url.Parse(base)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, *u)
+	}
+	return urls, nil
+}
+
+// SRVResolver looks services up in DNS SRV records named
+// _service._proto.Domain, in priority order.
+type SRVResolver struct {
+	Domain string
+	// Proto is the SRV protocol label; the default is "tcp".
+	Proto string
+	// Scheme is the scheme of the instance URLs; the default is "http".
+	Scheme string
+	// Resolver is the DNS resolver; the default is net.DefaultResolver.
+	Resolver *net.Resolver
+}
+
+// Resolve implements Resolver.
+func (r SRVResolver) Resolve(ctx context.Context, service string) ([]url.URL, error) {
+	res := cmp.Or(r.Resolver, net.DefaultResolver)
+	_, addrs, err := res.LookupSRV(ctx, service, cmp.Or(r.Proto, "tcp"), r.Domain)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]url.URL, 0, len(addrs))
+	for _, a := range addrs {
+		host := strings.TrimSuffix(a.Target, ".")
+		urls = append(urls, url.URL{
+			Scheme: cmp.Or(r.Scheme, "http"),
+			Host:   net.JoinHostPort(host, strconv.Itoa(int(a.Port))),
+		})
+	}
+	return urls, nil
+}
+
+// servicePool is an endpointPool whose endpoints are the instances of a
+// service, refreshed from its Resolver. Attempts rotate over the healthy
+// instances instead of preferring the first.
+type servicePool struct {
+	*endpointPool
+	name     string
+	base     string // placeholder base URL of requests to the service
+	resolver Resolver
+
+	refreshMu  sync.Mutex
+	resolvedAt time.Time
+	next       int // guarded by endpointPool.mu
+}
+
+// newServicePool returns nil unless WithService is configured.
+func newServicePool(cfg *config) *servicePool {
+	if cfg.resolver == nil {
+		return nil
+	}
+	return &servicePool{
+		endpointPool: &endpointPool{threshold: cfg.failoverAfter, cooldown: cfg.failoverCooldown},
+		name:         cfg.service,
+		base:         serviceBase(cfg.service),
+		resolver:     cfg.resolver,
+	}
+}
+
+func serviceBase(service string) string {
+	return "http://" + service
+}
+
+// relative strips the service's placeholder base URL from rawURL. It
+// reports false when rawURL was not built from it.
+func (p *servicePool) relative(rawURL string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	return trimBase(rawURL, p.base)
+}
+
+// pick returns the next healthy instance, resolving the service first if
+// its instances are missing or stale.
+func (p *servicePool) pick(ctx context.Context) (*endpoint, error) {
+	if err := p.refresh(ctx); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	n := len(p.endpoints)
+	best := p.endpoints[0]
+	for i := range n {
+		e := p.endpoints[(p.next+i)%n]
+		if !now.Before(e.downUntil) {
+			p.next = (p.next + i + 1) % n
+			return e, nil
+		}
+		if e.downUntil.Before(best.downUntil) {
+			best = e
+		}
+	}
+	return best, nil
+}
+
+// refresh re-resolves the service once serviceRefresh has passed. When the
+// resolver fails, the instances resolved before are kept.
+func (p *servicePool) refresh(ctx context.Context) error {
+	p.refreshMu.Lock()
+	defer p.refreshMu.Unlock()
+
+	p.mu.Lock()
+	have := len(p.endpoints) > 0
+	p.mu.Unlock()
+	if have && time.Since(p.resolvedAt) < serviceRefresh {
+		return nil
+	}
+
+	urls, err := p.resolver.Resolve(ctx, p.name)
+	if err == nil && len(urls) == 0 {
+		err = errors.New("no instances")
+	}
+	if err != nil {
+		if have {
+			p.resolvedAt = time.Now()
+			return nil
+		}
+		return fmt.Errorf("resilient: resolve service %q: %w", p.name, err)
+	}
+	p.resolvedAt = time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	endpoints := make([]*endpoint, 0, len(urls))
+	for _, u := range urls {
+		base := strings.TrimSuffix(u.String(), "/")
+		i := slices.IndexFunc(p.endpoints, func(e *endpoint) bool { return e.base == base })
+		if i >= 0 {
+			endpoints = append(endpoints, p.endpoints[i]) // keep its health
+		} else {
+			endpoints = append(endpoints, &endpoint{base: base})
+		}
+	}
+	p.endpoints = endpoints
+	return nil
+}
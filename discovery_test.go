@@ -0,0 +1,112 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithService(t *testing.T) {
+	var hits [2]atomic.Int32
+	var instances [2]*httptest.Server
+	for i := range instances {
+		instances[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[i].Add(1)
+			if r.URL.Path != "/invoices" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+		}))
+		defer instances[i].Close()
+	}
+
+	c := New(WithService("billing", StaticResolver{"billing": {instances[0].URL, instances[1].URL}}), WithRetry(3, time.Millisecond))
+	defer c.Close()
+	for range 4 {
+		if _, _, err := c.Get(context.Background(), "/invoices"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hits[0].Load() != 2 || hits[1].Load() != 2 {
+		t.Fatalf("expected calls spread over both instances, got %d and %d", hits[0].Load(), hits[1].Load())
+	}
+
+	// A dead instance is taken out of rotation after a network error.
+	instances[0].Close()
+	for range 3 {
+		if _, _, err := c.Get(context.Background(), "/invoices"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := hits[1].Load(); n != 5 {
+		t.Fatalf("expected the healthy instance to serve the calls, got %d", n)
+	}
+}
+
+// flakyResolver fails after its first answer.
+type flakyResolver struct {
+	calls atomic.Int32
+	url   string
+}
+
+func (r *flakyResolver) Resolve(ctx context.Context, service string) ([]url.URL, error) {
+	if r.calls.Add(1) > 1 {
+		return nil, errors.New("registry down")
+	}
+	u, _ := url.Parse(r.url)
+	return []url.URL{*u}, nil
+}
+
+func TestServicePoolKeepsInstances(t *testing.T) {
+	r := &flakyResolver{url: "http://10.0.0.1:8080/"}
+	p := newServicePool(&config{service: "billing", resolver: r, failoverAfter: 3, failoverCooldown: time.Second})
+
+	e, err := p.pick(context.Background())
+	if err != nil || e.base != "http://10.0.0.1:8080" {
+		t.Fatalf("unexpected instance %v %v", e, err)
+	}
+	p.resolvedAt = time.Time{} // force a refresh
+	if e, err := p.pick(context.Background()); err != nil || e.base != "http://10.0.0.1:8080" {
+		t.Fatalf("expected the previous instances after a resolver failure, got %v %v", e, err)
+	}
+
+	empty := newServicePool(&config{service: "billing", resolver: StaticResolver{}})
+	if _, err := empty.pick(context.Background()); err == nil {
+		t.Fatal("expected an error for an unknown service")
+	}
+}
+
+func TestServiceIgnoresCanceledAttempts(t *testing.T) {
+	var hits [2]atomic.Int32
+	var instances [2]*httptest.Server
+	for i := range instances {
+		instances[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[i].Add(1)
+			if r.URL.Path == "/slow" {
+				time.Sleep(100 * time.Millisecond)
+			}
+		}))
+		defer instances[i].Close()
+	}
+
+	c := New(WithService("billing", StaticResolver{"billing": {instances[0].URL, instances[1].URL}}), WithRetry(0, 0))
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.Get(ctx, "/slow"); err == nil {
+		t.Fatal("expected the call to time out")
+	}
+	for range 2 {
+		if _, _, err := c.Get(context.Background(), "/fast"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if hits[0].Load() != 2 || hits[1].Load() != 1 {
+		t.Fatalf("expected the canceled instance to stay in rotation, got %d and %d", hits[0].Load(), hits[1].Load())
+	}
+}
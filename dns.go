@@ -0,0 +1,111 @@
+package resilient
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dnsCache caches host lookups for WithDNSCache.
+type dnsCache struct {
+	ttl     time.Duration
+	refresh bool
+	lookup  func(ctx context.Context, host string) ([]string, error)
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+type dnsEntry struct {
+	addrs      []string
+	expires    time.Time
+	refreshing bool
+}
+
+func newDNSCache(cfg *config) *dnsCache {
+	if cfg.dnsTTL <= 0 {
+		return nil
+	}
+	return &dnsCache{
+		ttl:     cfg.dnsTTL,
+		refresh: cfg.dnsRefresh,
+		lookup:  net.DefaultResolver.LookupHost,
+		entries: make(map[string]*dnsEntry),
+	}
+}
+
+// resolve returns the addresses of host, from the cache when fresh. With
+// background refresh, an expired entry is still served while a single
+// refresh runs in the background.
+func (d *dnsCache) resolve(ctx context.Context, host string) ([]string, error) {
+	now := time.Now()
+	d.mu.Lock()
+	e, ok := d.entries[host]
+	if ok && now.Before(e.expires) {
+		d.mu.Unlock()
+		d.hits.Add(1)
+		return e.addrs, nil
+	}
+	if ok && d.refresh {
+		if !e.refreshing {
+			e.refreshing = true
+			go d.fetch(context.WithoutCancel(ctx), host)
+		}
+		d.mu.Unlock()
+		d.hits.Add(1)
+		return e.addrs, nil
+	}
+	d.mu.Unlock()
+
+	d.misses.Add(1)
+	return d.fetch(ctx, host)
+}
+
+// fetch looks host up and stores the result. A failed background refresh
+// keeps the old entry, to be retried on the next request.
+func (d *dnsCache) fetch(ctx context.Context, host string) ([]string, error) {
+	addrs, err := d.lookup(ctx, host)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		if e, ok := d.entries[host]; ok {
+			e.refreshing = false
+		}
+		return nil, err
+	}
+	d.entries[host] = &dnsEntry{addrs: addrs, expires: time.Now().Add(d.ttl)}
+	return addrs, nil
+}
+
+// dialContext wraps dial so that host names are resolved through the
+// cache, trying each address in turn.
+func (d *dnsCache) dialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+		addrs, err := d.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		var lastErr error
+		for _, ip := range addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, lastErr
+	}
+}
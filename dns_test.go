@@ -0,0 +1,79 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDNSCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		w.Write([]byte(r.Host))
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	c := New(WithDNSCache(time.Hour))
+	defer c.Close()
+	var lookups atomic.Int32
+	c.dns.lookup = func(ctx context.Context, host string) ([]string, error) {
+		lookups.Add(1)
+		if host != "api.internal" {
+			return nil, errors.New("no such host")
+		}
+		// The first address refuses connections; the dial falls through.
+		return []string{"127.0.0.2", "127.0.0.1"}, nil
+	}
+
+	for range 3 {
+		body, _, err := c.Get(context.Background(), "http://api.internal:"+port+"/")
+		if err != nil || string(body) != "api.internal:"+port {
+			t.Fatalf("expected the cached address to be dialed, got %q %v", body, err)
+		}
+	}
+	if n := lookups.Load(); n != 1 {
+		t.Fatalf("expected a single lookup, got %d", n)
+	}
+	if s := c.Stats(); s.DNSMisses != 1 || s.DNSHits != 2 {
+		t.Fatalf("expected 1 miss and 2 hits, got %d / %d", s.DNSMisses, s.DNSHits)
+	}
+}
+
+func TestDNSCacheBackgroundRefresh(t *testing.T) {
+	d := &dnsCache{ttl: time.Millisecond, refresh: true, entries: map[string]*dnsEntry{}}
+	var lookups atomic.Int32
+	d.lookup = func(ctx context.Context, host string) ([]string, error) {
+		if lookups.Add(1) > 1 {
+			return []string{"10.0.0.2"}, nil
+		}
+		return []string{"10.0.0.1"}, nil
+	}
+
+	ctx := context.Background()
+	if addrs, _ := d.resolve(ctx, "h"); addrs[0] != "10.0.0.1" {
+		t.Fatalf("unexpected first lookup %v", addrs)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if addrs, _ := d.resolve(ctx, "h"); addrs[0] != "10.0.0.1" {
+		t.Fatalf("expected the stale entry while refreshing, got %v", addrs)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		d.mu.Lock()
+		addr := d.entries["h"].addrs[0]
+		d.mu.Unlock()
+		if addr == "10.0.0.2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the entry to be refreshed, still %s", addr)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -22,3 +22,6 @@
 //
 //	body, status, err := client.Get(ctx, "/users")
 package resilient
+
+// Version is the library version, sent in the default User-Agent.
+const Version = "0.1.0"
@@ -0,0 +1,179 @@
+package resilient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Download performs a GET request to baseURL+path and streams the response
+// body to w without buffering it, returning the number of bytes written.
+// Retries, rate limiting and the rest of the pipeline apply until the body
+// starts streaming; a failure mid-stream is returned as is. The body is
+// checked against Content-Length when the server sends one, and is not
+// subject to WithMaxResponseSize. Note that WithTimeout bounds the whole
+// transfer.
+func (c *Client) Download(ctx context.Context, path string, w io.Writer, headers ...map[string]string) (int64, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, path, nil, headers...)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	_, err = c.execute(req.Context(), c.withDefaultHeaders(req), func(resp *http.Response) error {
+		n, err = io.Copy(w, c.downloadProgress(resp.Body, 0, resp.ContentLength))
+		if err != nil {
+			return err
+		}
+		return checkLength(resp, n)
+	})
+	return n, err
+}
+
+// DownloadFile downloads baseURL+path into filename. The body is written
+// to a temporary file in the same directory and renamed into place once
+// complete, so filename never holds a partial download.
+func (c *Client) DownloadFile(ctx context.Context, path, filename string, headers ...map[string]string) (int64, error) {
+	return writeFileAtomic(filename, func(f *os.File) (int64, error) {
+		return c.Download(ctx, path, f, headers...)
+	})
+}
+
+// DownloadResumable is DownloadFile for large files over flaky links: when
+// the transfer breaks mid-stream, it requests the rest with a Range header
+// instead of starting over. If-Range with the response's ETag (or
+// Last-Modified) guards against the file changing in between; if it did, or
+// the server ignores ranges, the download restarts from zero. Up to the
+// configured number of retries resumptions are made, with the usual backoff.
+func (c *Client) DownloadResumable(ctx context.Context, path, filename string, headers ...map[string]string) (int64, error) {
+	return writeFileAtomic(filename, func(f *os.File) (int64, error) {
+		var written int64
+		var validator string
+		for resume := 0; ; resume++ {
+			req, err := c.NewRequest(ctx, http.MethodGet, path, nil, headers...)
+			if err != nil {
+				return written, err
+			}
+			if written > 0 && validator != "" {
+				req.Header.Set("Range", fmt.Sprintf("bytes=%d-", written))
+				req.Header.Set("If-Range", validator)
+			}
+
+			var streamErr error
+			_, err = c.execute(req.Context(), c.withDefaultHeaders(req), func(resp *http.Response) error {
+				if resp.StatusCode == http.StatusPartialContent && req.Header.Get("Range") != "" {
+					if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != written {
+						return fmt.Errorf("unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+					}
+				} else {
+					// A full body: start over.
+					if err := f.Truncate(0); err != nil {
+						return err
+					}
+					if _, err := f.Seek(0, io.SeekStart); err != nil {
+						return err
+					}
+					written = 0
+					validator = rangeValidator(resp.Header)
+				}
+				total := resp.ContentLength
+				if total >= 0 {
+					total += written
+				}
+				n, err := io.Copy(f, c.downloadProgress(resp.Body, written, total))
+				written += n
+				if err == nil {
+					err = checkLength(resp, n)
+				}
+				streamErr = err
+				return err
+			})
+			if err == nil || streamErr == nil || resume >= c.cfg.maxRetries {
+				return written, err
+			}
+
+			backoff := c.backoffDuration(resume+1, 0)
+			select {
+			case <-ctx.Done():
+				return written, ctx.Err()
+			case <-c.cfg.clock.After(backoff):
+			}
+			c.retries.Add(1)
+			c.backoffTime.Add(int64(backoff))
+		}
+	})
+}
+
+// writeFileAtomic runs write against a temporary file next to filename and
+// renames it into place if write succeeds.
+func writeFileAtomic(filename string, write func(f *os.File) (int64, error)) (int64, error) {
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("resilient: create temp file: %w", err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op after a successful rename
+
+	n, err := write(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return n, err
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		return n, fmt.Errorf("resilient: rename download: %w", err)
+	}
+	return n, nil
+}
+
+// rangeValidator returns a strong validator usable with If-Range, or "".
+func rangeValidator(h http.Header) string {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return h.Get("Last-Modified")
+}
+
+// contentRangeStart parses the first byte position of a Content-Range
+// header such as "bytes 100-199/200".
+func contentRangeStart(v string) (int64, bool) {
+	v, ok := strings.CutPrefix(v, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(v, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	return n, err == nil
+}
+
+// downloadProgress wraps body to report progress when WithProgress is set.
+// offset is the number of bytes already received in earlier attempts.
+func (c *Client) downloadProgress(body io.Reader, offset, total int64) io.Reader {
+	fn := c.cfg.progress
+	if fn == nil {
+		return body
+	}
+	return &progressReader{r: body, total: total, fn: func(n, total int64) {
+		fn(offset+n, total)
+	}}
+}
+
+// checkLength reports a truncated body when fewer bytes than the declared
+// Content-Length arrived.
+func checkLength(resp *http.Response, n int64) error {
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		return fmt.Errorf("got %d of %d bytes: %w", n, resp.ContentLength, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
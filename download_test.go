@@ -0,0 +1,159 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownload(t *testing.T) {
+	var attempts atomic.Int32
+	payload := strings.Repeat("x", 1<<20)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("busy"))
+			return
+		}
+		io.WriteString(w, payload)
+	}))
+	defer srv.Close()
+
+	// A response size limit below the payload shows the body isn't buffered.
+	c := New(WithBaseURL(srv.URL), WithRetry(1, time.Millisecond), WithMaxResponseSize(1024))
+	defer c.Close()
+
+	var buf bytes.Buffer
+	n, err := c.Download(context.Background(), "/file", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(payload)) || buf.String() != payload {
+		t.Fatalf("expected %d bytes, got %d (buffer %d)", len(payload), n, buf.Len())
+	}
+}
+
+func TestDownloadTruncated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		// The server closes the connection after the short body.
+		w.Write([]byte("short"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	_, err := c.Download(context.Background(), "/", io.Discard)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("file contents"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "out.txt")
+	if _, err := c.DownloadFile(context.Background(), "/file", dest); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil || string(data) != "file contents" {
+		t.Fatalf("unexpected file contents %q, %v", data, err)
+	}
+
+	if _, err := c.DownloadFile(context.Background(), "/missing", filepath.Join(dir, "missing.txt")); err == nil {
+		t.Fatal("expected an error for a 404")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected temp files to be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestDownloadResumable(t *testing.T) {
+	payload := []byte(strings.Repeat("0123456789", 1000))
+	var attempts atomic.Int32
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if attempts.Add(1) == 1 {
+			// Break the transfer halfway through.
+			w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+			w.Write(payload[:4000])
+			return
+		}
+		ranges = append(ranges, r.Header.Get("Range")+" "+r.Header.Get("If-Range"))
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(2, time.Millisecond))
+	defer c.Close()
+
+	dest := filepath.Join(t.TempDir(), "big.bin")
+	n, err := c.DownloadResumable(context.Background(), "/big", dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(payload)) {
+		t.Fatalf("expected %d bytes, got %d", len(payload), n)
+	}
+	data, _ := os.ReadFile(dest)
+	if !bytes.Equal(data, payload) {
+		t.Fatal("downloaded file does not match payload")
+	}
+	if len(ranges) != 1 || ranges[0] != `bytes=4000- "v1"` {
+		t.Fatalf("expected one ranged resume, got %q", ranges)
+	}
+}
+
+func TestDownloadResumableChanged(t *testing.T) {
+	v2 := []byte(strings.Repeat("b", 5000))
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", "5000")
+			w.Write([]byte(strings.Repeat("a", 2000)))
+			return
+		}
+		// The file changed, so If-Range fails and the full body is sent.
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(v2))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(2, time.Millisecond))
+	defer c.Close()
+
+	dest := filepath.Join(t.TempDir(), "f.bin")
+	if _, err := c.DownloadResumable(context.Background(), "/", dest); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(dest)
+	if !bytes.Equal(data, v2) {
+		t.Fatalf("expected the new version only, got %d bytes", len(data))
+	}
+}
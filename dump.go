@@ -0,0 +1,175 @@
+package resilient
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRedactedHeaders are always hidden in debug dumps and curl commands.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// defaultRedactedParams are query parameters always hidden in debug dumps
+// and curl commands, as they commonly carry credentials. Query parameters
+// named like a redacted header, such as x-api-key, are hidden too.
+var defaultRedactedParams = []string{
+	"access_token",
+	"api_key",
+	"apikey",
+	"client_secret",
+	"key",
+	"password",
+	"secret",
+	"sig",
+	"signature",
+	"token",
+}
+
+// redactedValue replaces the value of a redacted header.
+const redactedValue = "[REDACTED]"
+
+// redactedURLValue replaces a password or query parameter in a URL, as in
+// url.URL.Redacted.
+const redactedURLValue = "xxxxx"
+
+// maxDumpBody is the most of a response body written by WithDebugDump.
+const maxDumpBody = 64 << 10
+
+// redactedHeaders returns the canonical names of the headers to hide: the
+// defaults, the WithAPIKeyPool header and those added with
+// WithRedactedHeaders.
+func redactedHeaders(cfg *config) map[string]bool {
+	names := make(map[string]bool, len(defaultRedactedHeaders)+len(cfg.redactHeaders)+1)
+	for _, name := range defaultRedactedHeaders {
+		names[name] = true
+	}
+	for _, name := range cfg.redactHeaders {
+		names[http.CanonicalHeaderKey(name)] = true
+	}
+	if cfg.apiKeys.header != "" {
+		names[http.CanonicalHeaderKey(cfg.apiKeys.header)] = true
+	}
+	return names
+}
+
+// redact returns a copy of h with the values of the named headers replaced.
+func redact(h http.Header, names map[string]bool) http.Header {
+	out := h.Clone()
+	for name, values := range out {
+		if names[name] {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+	return out
+}
+
+// redactURL returns a copy of u with its password and the values of its
+// secret query parameters replaced; names are the redacted headers.
+func redactURL(u *url.URL, names map[string]bool) *url.URL {
+	out := *u
+	if _, ok := out.User.Password(); ok {
+		out.User = url.UserPassword(out.User.Username(), redactedURLValue)
+	}
+	if out.RawQuery == "" {
+		return &out
+	}
+	params := strings.Split(out.RawQuery, "&")
+	for i, p := range params {
+		k, _, _ := strings.Cut(p, "=")
+		name, err := url.QueryUnescape(k)
+		if err != nil {
+			continue
+		}
+		if names[http.CanonicalHeaderKey(name)] || slices.Contains(defaultRedactedParams, strings.ToLower(name)) {
+			params[i] = k + "=" + redactedURLValue
+		}
+	}
+	out.RawQuery = strings.Join(params, "&")
+	return &out
+}
+
+// dumpTransport writes every request and response it carries to w.
+type dumpTransport struct {
+	base   http.RoundTripper
+	w      io.Writer
+	redact map[string]bool
+
+	mu sync.Mutex
+}
+
+func (t *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var out bytes.Buffer
+	t.dumpRequest(&out, req)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		fmt.Fprintf(&out, "<<< error after %v: %v\n\n", time.Since(start).Round(time.Millisecond), err)
+	} else {
+		fmt.Fprintf(&out, "<<< response after %v\n", time.Since(start).Round(time.Millisecond))
+		t.dumpResponse(&out, resp)
+	}
+
+	t.mu.Lock()
+	t.w.Write(out.Bytes())
+	t.mu.Unlock()
+	return resp, err
+}
+
+// dumpRequest writes req with redacted headers. The body is included when it
+// can be re-created with GetBody, so the request itself is left untouched.
+func (t *dumpTransport) dumpRequest(out *bytes.Buffer, req *http.Request) {
+	out.WriteString(">>> request\n")
+	dr := req.Clone(req.Context())
+	dr.Header = redact(req.Header, t.redact)
+	// The dump would turn the URL's userinfo into an Authorization header.
+	dr.URL = redactURL(req.URL, t.redact)
+	dr.URL.User = nil
+	withBody := false
+	dr.Body = nil
+	if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+		if body, err := req.GetBody(); err == nil {
+			dr.Body, withBody = body, true
+		}
+	}
+	b, err := httputil.DumpRequestOut(dr, withBody)
+	if err != nil {
+		fmt.Fprintf(out, "(dump failed: %v)\n", err)
+	}
+	out.Write(b)
+	out.WriteString("\n\n")
+}
+
+// dumpResponse writes resp with redacted headers and up to maxDumpBody
+// bytes of its body, which stays readable for the caller.
+func (t *dumpTransport) dumpResponse(out *bytes.Buffer, resp *http.Response) {
+	dr := *resp
+	dr.Header = redact(resp.Header, t.redact)
+	b, _ := httputil.DumpResponse(&dr, false)
+	out.Write(b)
+
+	br := bufio.NewReaderSize(resp.Body, maxDumpBody)
+	peek, _ := br.Peek(maxDumpBody)
+	out.Write(peek)
+	if len(peek) == maxDumpBody {
+		out.WriteString("\n(body truncated)")
+	}
+	out.WriteString("\n\n")
+	resp.Body = readCloser{br, resp.Body}
+}
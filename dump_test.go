@@ -0,0 +1,63 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writers and readers.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDebugDump(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t-cookie"})
+		w.Write(append([]byte("echo:"), body...))
+	}))
+	defer srv.Close()
+
+	var dump syncBuffer
+	c := New(WithBaseURL(srv.URL), WithDebugDump(&dump), WithRedactedHeaders("X-Signature"),
+		WithBearerToken("s3cr3t-token"),
+		WithDefaultHeaders(map[string]string{"X-Signature": "s3cr3t-sig", "X-Trace": "visible"}))
+	defer c.Close()
+
+	body, _, err := c.Post(context.Background(), "/things?access_token=s3cr3t-query", "text/plain", strings.NewReader("payload"))
+	if err != nil || string(body) != "echo:payload" {
+		t.Fatalf("expected the dump to leave bodies intact, got %q %v", body, err)
+	}
+
+	out := dump.String()
+	for _, want := range []string{
+		">>> request", "POST /things?access_token=xxxxx HTTP/1.1", "Authorization: [REDACTED]", "X-Signature: [REDACTED]",
+		"X-Trace: visible", "payload", "<<< response", "HTTP/1.1 200 OK", "Set-Cookie: [REDACTED]", "echo:payload",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected dump to contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "s3cr3t") {
+		t.Fatalf("dump leaked a secret:\n%s", out)
+	}
+}
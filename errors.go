@@ -0,0 +1,80 @@
+package resilient
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrMaxElapsedTime is returned (wrapped) when a call exceeds the budget set
+// with WithMaxElapsedTime.
+var ErrMaxElapsedTime = errors.New("resilient: max elapsed time exceeded")
+
+// ErrShedded is returned (wrapped) when a request is rejected by load
+// shedding instead of waiting for a rate-limit token; see WithMaxQueueWait
+// and WithMaxQueueDepth.
+var ErrShedded = errors.New("resilient: request shed, rate limit queue full")
+
+// ErrMaxRetriesExceeded is returned (wrapped, together with the error of the
+// last attempt) when every attempt allowed by WithRetry has failed.
+var ErrMaxRetriesExceeded = errors.New("resilient: max retries exceeded")
+
+// ErrRateLimitWait is returned (wrapped, together with the cause) when a
+// request gives up waiting for a rate-limit token, e.g. because its context
+// was cancelled or the request was shed.
+var ErrRateLimitWait = errors.New("resilient: rate limit wait")
+
+// ErrResponseTooLarge is returned (wrapped) when a response body exceeds
+// WithMaxResponseSize. The body read so far, truncated to the limit, is
+// still returned.
+var ErrResponseTooLarge = errors.New("resilient: response too large")
+
+// ErrCircuitOpen is returned (wrapped) when a circuit breaker rejects a
+// request without sending it.
+var ErrCircuitOpen = errors.New("resilient: circuit open")
+
+// ErrClientClosed is returned when a call is made after Close or Shutdown.
+var ErrClientClosed = errors.New("resilient: client closed")
+
+// ErrOffline is returned when a call is made while the client is offline;
+// see Client.SetOffline.
+var ErrOffline = errors.New("resilient: offline")
+
+// ErrUnsupportedTransport is returned (wrapped) by every call of a client
+// with TLS options, such as WithPinnedCertificates, whose WithHTTPClient
+// transport is not an *http.Transport they can be applied to.
+var ErrUnsupportedTransport = errors.New("resilient: TLS options need an *http.Transport")
+
+// ErrUnhealthy is returned (wrapped) by Ping when the health-check endpoint
+// answers with an unexpected status.
+var ErrUnhealthy = errors.New("resilient: unhealthy")
+
+// Attempt is the outcome of one failed attempt, as recorded in RetryError.
+type Attempt struct {
+	// StatusCode is the response status, or 0 if no response arrived.
+	StatusCode int
+	// Err describes the failure.
+	Err error
+	// Duration is how long the attempt took.
+	Duration time.Duration
+	// Backoff is the wait before the next attempt; 0 for the last one.
+	Backoff time.Duration
+}
+
+// RetryError is returned when retries are exhausted. It wraps
+// ErrMaxRetriesExceeded and the error of the last attempt, and records
+// every attempt for post-mortems:
+//
+//	var re *resilient.RetryError
+//	if errors.As(err, &re) {
+//		for i, a := range re.Attempts {
+//			log.Printf("attempt %d: %d %v (waited %v)", i+1, a.StatusCode, a.Err, a.Backoff)
+//		}
+//	}
+type RetryError struct {
+	Attempts []Attempt
+	err      error
+}
+
+func (e *RetryError) Error() string { return e.err.Error() }
+
+func (e *RetryError) Unwrap() error { return e.err }
@@ -0,0 +1,162 @@
+package resilient
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType identifies what an Event reports.
+type EventType int
+
+const (
+	// EventAttempt is emitted before each attempt is sent.
+	EventAttempt EventType = iota + 1
+	// EventRetry is emitted when a failed attempt is going to be retried
+	// after Wait.
+	EventRetry
+	// EventRateReduced is emitted when adaptive rate limiting lowers a
+	// limiter to Rate.
+	EventRateReduced
+	// EventRateRestored is emitted when a reduced limiter is back at its
+	// configured Rate.
+	EventRateRestored
+	// EventCircuitOpen is emitted when a circuit breaker opens.
+	EventCircuitOpen
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAttempt:
+		return "attempt"
+	case EventRetry:
+		return "retry"
+	case EventRateReduced:
+		return "rate_reduced"
+	case EventRateRestored:
+		return "rate_restored"
+	case EventCircuitOpen:
+		return "circuit_open"
+	}
+	return "unknown"
+}
+
+// Event is a structured record of client behavior, delivered on the
+// channel returned by Client.Events. Fields that don't apply to the event
+// type are left zero.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	// Method and URL identify the request, when there is one. Rate restores
+	// are timer-driven and carry no request. The URL's password and secret
+	// query parameters are redacted as in WithDebugDump.
+	Method string
+	URL    string
+
+	// Attempt is the 1-based attempt number the event refers to: the one
+	// being sent for EventAttempt, the next one for EventRetry.
+	Attempt int
+
+	// StatusCode and Err describe the failure that caused a retry.
+	StatusCode int
+	Err        error
+
+	// Wait is the backoff before the retry.
+	Wait time.Duration
+
+	// Limiter names the limiter whose rate changed: "" for the client-wide
+	// limiter, otherwise the host or path pattern of a scoped one.
+	Limiter string
+	// Rate is the limiter's new rate in requests per second.
+	Rate float64
+
+	// Host is the host whose circuit opened, for EventCircuitOpen.
+	Host string
+}
+
+// eventBuffer is the capacity of the Events channel.
+const eventBuffer = 256
+
+// eventStream fans client events out to the Events channel.
+type eventStream struct {
+	on      atomic.Bool
+	dropped atomic.Uint64
+
+	mu     sync.RWMutex
+	ch     chan Event
+	closed bool
+}
+
+// Events returns a channel of structured events describing the client's
+// behavior (attempts, retries, rate changes, breaker trips), for monitoring
+// agents that would otherwise wire up a dozen callbacks. Events are only
+// produced once Events has been called, and all calls return the same
+// channel. Sends never block requests: when the buffer of 256 events is
+// full, new events are dropped and counted in Stats.DroppedEvents. The
+// channel is closed by Close.
+func (c *Client) Events() <-chan Event {
+	e := &c.events
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ch == nil {
+		e.ch = make(chan Event, eventBuffer)
+		if e.closed {
+			close(e.ch)
+		}
+		e.on.Store(true)
+	}
+	return e.ch
+}
+
+// emit delivers ev to the Events channel and the WithLogger logger, if
+// either is in use, filling in the time and, when req is given, the
+// request's method and URL.
+func (c *Client) emit(ev Event, req *http.Request) {
+	e := &c.events
+	if !e.on.Load() && c.cfg.logger == nil {
+		return
+	}
+	ev.Time = c.cfg.clock.Now()
+	if req != nil {
+		ev.Method, ev.URL = req.Method, redactURL(req.URL, c.redact).Redacted()
+	}
+	if c.cfg.logger != nil {
+		c.logEvent(ev)
+	}
+	if !e.on.Load() {
+		return
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.ch <- ev:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// emitAll emits events collected while c.mu was held, so that loggers and
+// Events readers never run under it.
+func (c *Client) emitAll(events []Event) {
+	for _, ev := range events {
+		c.emit(ev, nil)
+	}
+}
+
+// close closes the Events channel, if it was ever requested.
+func (e *eventStream) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+	e.closed = true
+	if e.ch != nil {
+		close(e.ch)
+	}
+}
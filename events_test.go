@@ -0,0 +1,74 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRateLimit(1000, 10), WithAdaptive(20*time.Millisecond),
+		WithRetry(1, time.Millisecond), WithJitter(JitterNone, 0))
+	events := c.Events()
+
+	if _, _, err := c.Get(context.Background(), "/items"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(60 * time.Millisecond) // let the cooldown restore the rate
+	c.Close()
+
+	var got []Event
+	for ev := range events {
+		got = append(got, ev)
+	}
+	want := []EventType{EventAttempt, EventRateReduced, EventRetry, EventAttempt, EventRateRestored}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %+v", want, got)
+	}
+	for i, ev := range got {
+		if ev.Type != want[i] {
+			t.Fatalf("event %d: expected %v, got %v", i, want[i], ev.Type)
+		}
+	}
+	if got[0].URL != srv.URL+"/items" || got[0].Method != "GET" || got[0].Attempt != 1 {
+		t.Fatalf("unexpected attempt event %+v", got[0])
+	}
+	if got[1].Rate != 500 {
+		t.Fatalf("expected rate halved to 500, got %+v", got[1])
+	}
+	if got[2].StatusCode != http.StatusTooManyRequests || got[2].Attempt != 2 || got[2].Wait <= 0 {
+		t.Fatalf("unexpected retry event %+v", got[2])
+	}
+	if got[4].Rate != 1000 {
+		t.Fatalf("expected rate restored to 1000, got %+v", got[4])
+	}
+}
+
+func TestEventsDropWhenFull(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+	c.Events() // never drained
+
+	for range eventBuffer + 5 {
+		c.Get(context.Background(), "/")
+	}
+	if d := c.Stats().DroppedEvents; d != 5 {
+		t.Fatalf("expected 5 dropped events, got %d", d)
+	}
+}
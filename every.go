@@ -0,0 +1,91 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Every runs req every interval until the returned stop function is
+// called or the client is closed, passing each outcome to handler. It
+// replaces hand-rolled polling loops:
+//
+//	stop, err := client.Every(30*time.Second, req, func(body []byte, status int, err error) {
+//		...
+//	})
+//	defer stop()
+//
+// Each run goes through Do, so it waits for the rate limiter and retries as
+// usual. The first run starts after a random delay of up to interval, so
+// pollers created together don't fire in lockstep; a tick that comes while
+// the previous run is still going is skipped. The request body, if any, is
+// read once and re-sent on each run. Stop cancels a run in progress and
+// waits for handler to return, so it must not be called from handler.
+func (c *Client) Every(interval time.Duration, req *http.Request, handler func(body []byte, status int, err error)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, errors.New("resilient: every: interval must be positive")
+	}
+	var body []byte
+	if req.Body != nil {
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var (
+		mu      sync.Mutex
+		timer   Timer
+		stopped bool
+		running atomic.Bool
+		wg      sync.WaitGroup
+	)
+	var tick func()
+	tick = func() {
+		mu.Lock()
+		if stopped || c.inflight.closing() {
+			mu.Unlock()
+			return
+		}
+		timer = c.cfg.clock.AfterFunc(interval, tick)
+		if !running.CompareAndSwap(false, true) {
+			mu.Unlock()
+			return // the previous run is still going
+		}
+		wg.Add(1)
+		mu.Unlock()
+		defer wg.Done()
+		defer running.Store(false)
+
+		r := req.Clone(ctx)
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+		}
+		data, status, err := c.Do(ctx, r)
+		if ctx.Err() == nil {
+			handler(data, status, err)
+		}
+	}
+
+	mu.Lock()
+	timer = c.cfg.clock.AfterFunc(rand.N(interval), tick)
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		stopped = true
+		timer.Stop()
+		mu.Unlock()
+		cancel()
+		wg.Wait()
+	}, nil
+}
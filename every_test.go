@@ -0,0 +1,71 @@
+package resilient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvery(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+	req, _ := c.NewRequest(context.Background(), http.MethodPost, "/poll", strings.NewReader("ping"))
+
+	var runs atomic.Int32
+	stop, err := c.Every(10*time.Millisecond, req, func(body []byte, status int, err error) {
+		if err != nil || string(body) != "ping" {
+			t.Errorf("unexpected run %q %d %v", body, status, err)
+		}
+		runs.Add(1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, "three runs", func() bool { return runs.Load() >= 3 })
+	stop()
+	n := calls.Load()
+	time.Sleep(30 * time.Millisecond)
+	if calls.Load() != n {
+		t.Fatal("expected no runs after stop")
+	}
+}
+
+func TestEverySkipsOverlap(t *testing.T) {
+	var active, overlaps, calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if active.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		calls.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		active.Add(-1)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+	req, _ := c.NewRequest(context.Background(), http.MethodGet, "/", nil)
+	stop, _ := c.Every(5*time.Millisecond, req, func([]byte, int, error) {})
+	time.Sleep(100 * time.Millisecond)
+	stop()
+
+	if n := overlaps.Load(); n != 0 {
+		t.Fatalf("expected no overlapping runs, got %d", n)
+	}
+	if n := calls.Load(); n < 2 || n > 4 {
+		t.Fatalf("expected skipped ticks while a run was going, got %d runs", n)
+	}
+}
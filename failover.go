@@ -0,0 +1,102 @@
+package resilient
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// endpoint is one base URL configured with WithBaseURLs.
+type endpoint struct {
+	base      string
+	failures  int
+	downUntil time.Time
+}
+
+// endpointPool tracks the health of the failover endpoints.
+type endpointPool struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	threshold int
+	cooldown  time.Duration
+}
+
+// newEndpointPool returns nil unless fallback URLs are configured.
+func newEndpointPool(cfg *config) *endpointPool {
+	if len(cfg.fallbackURLs) == 0 {
+		return nil
+	}
+	p := &endpointPool{threshold: cfg.failoverAfter, cooldown: cfg.failoverCooldown}
+	for _, base := range append([]string{cfg.baseURL}, cfg.fallbackURLs...) {
+		p.endpoints = append(p.endpoints, &endpoint{base: base})
+	}
+	return p
+}
+
+// relative strips a configured base URL from rawURL. It reports false when
+// rawURL was not built from any of them.
+func (p *endpointPool) relative(rawURL string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	for _, e := range p.endpoints {
+		if rel, ok := trimBase(rawURL, e.base); ok {
+			return rel, true
+		}
+	}
+	return "", false
+}
+
+// trimBase strips base from rawURL when rawURL is base itself or continues
+// it with a path or query, so that https://api.example.com does not match
+// https://api.example.com.evil/.
+func trimBase(rawURL, base string) (string, bool) {
+	rel, ok := strings.CutPrefix(rawURL, base)
+	if !ok {
+		return "", false
+	}
+	if rel == "" || rel[0] == '/' || rel[0] == '?' || strings.HasSuffix(base, "/") {
+		return rel, true
+	}
+	return "", false
+}
+
+// pick returns the first healthy endpoint in priority order, or the one
+// that recovers soonest when all are down.
+func (p *endpointPool) pick() *endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	best := p.endpoints[0]
+	for _, e := range p.endpoints {
+		if !now.Before(e.downUntil) {
+			return e
+		}
+		if e.downUntil.Before(best.downUntil) {
+			best = e
+		}
+	}
+	return best
+}
+
+// report records the outcome of an attempt against e.
+func (p *endpointPool) report(e *endpoint, resp *http.Response, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case err != nil:
+		e.failures = p.threshold
+	case resp.StatusCode >= 500:
+		e.failures++
+	default:
+		e.failures = 0
+		return
+	}
+	if e.failures >= p.threshold {
+		e.failures = 0
+		e.downUntil = time.Now().Add(p.cooldown)
+	}
+}
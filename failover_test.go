@@ -0,0 +1,146 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFailoverOnUnreachable(t *testing.T) {
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	deadURL := dead.URL
+	dead.Close()
+
+	var hits atomic.Int32
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer fallback.Close()
+
+	c := New(WithBaseURLs(deadURL, fallback.URL), WithRetry(2, 10*time.Millisecond))
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "/users" {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	// The primary is now down, so the next call goes straight to the fallback.
+	hits.Store(0)
+	res, err := c.DoResult(context.Background(), mustRequest(t, deadURL+"/again"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Attempts != 1 || hits.Load() != 1 {
+		t.Fatalf("expected direct fallback, got %d attempts", res.Attempts)
+	}
+}
+
+func TestFailoverReturnsToPrimary(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(503)
+			return
+		}
+		w.Write([]byte("primary"))
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fallback"))
+	}))
+	defer fallback.Close()
+
+	c := New(
+		WithBaseURLs(primary.URL, fallback.URL),
+		WithRetry(3, 10*time.Millisecond),
+		WithFailover(2, 100*time.Millisecond),
+	)
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "fallback" {
+		t.Fatalf("expected fallback, got %s", body)
+	}
+
+	failing.Store(false)
+	time.Sleep(150 * time.Millisecond)
+
+	body, _, err = c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "primary" {
+		t.Fatalf("expected return to primary, got %s", body)
+	}
+}
+
+func mustRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestFailoverIgnoresCanceledAttempts(t *testing.T) {
+	var primaryHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Write([]byte("primary"))
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fallback"))
+	}))
+	defer fallback.Close()
+
+	c := New(WithBaseURLs(primary.URL, fallback.URL), WithRetry(0, 0))
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.Get(ctx, "/slow"); err == nil {
+		t.Fatal("expected the call to time out")
+	}
+	body, _, err := c.Get(context.Background(), "/fast")
+	if err != nil || string(body) != "primary" {
+		t.Fatalf("expected the primary to stay up after a canceled call, got %q %v", body, err)
+	}
+}
+
+func TestEndpointRelative(t *testing.T) {
+	p := &endpointPool{endpoints: []*endpoint{{base: "https://api.example.com"}}}
+	for _, tt := range []struct {
+		url  string
+		rel  string
+		want bool
+	}{
+		{"https://api.example.com", "", true},
+		{"https://api.example.com/users", "/users", true},
+		{"https://api.example.com?page=2", "?page=2", true},
+		{"https://api.example.com.evil/users", "", false},
+		{"https://api.example.community/", "", false},
+		{"https://other.example.com/users", "", false},
+	} {
+		rel, ok := p.relative(tt.url)
+		if rel != tt.rel || ok != tt.want {
+			t.Fatalf("relative(%q) = %q, %v; want %q, %v", tt.url, rel, ok, tt.rel, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// FallbackFunc supplies the response of a failed call; see WithFallback.
+// Where a bare lastResp *http.Response would have been closed by the time
+// the function runs, it receives the call's last Result instead, with the
+// response already read: StatusCode, Header and Body of the last attempt.
+// last is never nil, but when no response arrived (a transport error such
+// as a refused connection) its StatusCode is 0 and its Header and Body are
+// nil; a response with an empty body has an empty Body. err is the call's
+// error. Returning a nil error makes the call succeed with the returned
+// body and status; returning an error fails it with that error instead.
+type FallbackFunc func(ctx context.Context, req *http.Request, last *Result, err error) ([]byte, int, error)
+
+// unrecoverable reports whether err means the upstream gave no usable
+// answer: the retries or the time budget ran out, or the request could not
+// be sent at all.
+func unrecoverable(err error) bool {
+	var urlErr *url.Error
+	return errors.Is(err, ErrMaxRetriesExceeded) || errors.Is(err, ErrMaxElapsedTime) || errors.As(err, &urlErr)
+}
+
+// fallback runs the WithFallback function for a call that failed with err.
+func (c *Client) fallback(ctx context.Context, req *http.Request, last *Result, err error) (*Result, error) {
+	body, status, ferr := c.cfg.fallback(ctx, req, last, err)
+	if ferr != nil {
+		return last, ferr
+	}
+	return &Result{
+		Body:       body,
+		StatusCode: status,
+		Attempts:   last.Attempts,
+		Duration:   last.Duration,
+		Fallback:   true,
+	}, nil
+}
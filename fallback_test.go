@@ -0,0 +1,108 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFallback(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/ok" {
+			w.Write([]byte("live"))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	var gotStatus, gotAttempts int
+	var gotErr error
+	c := New(WithBaseURL(srv.URL), WithRetry(2, time.Millisecond),
+		WithFallback(func(ctx context.Context, req *http.Request, last *Result, err error) ([]byte, int, error) {
+			gotStatus, gotAttempts, gotErr = last.StatusCode, last.Attempts, err
+			if req.URL.Path == "/fail" {
+				return nil, 0, errors.New("no fallback")
+			}
+			return []byte("default"), http.StatusOK, nil
+		}))
+	defer c.Close()
+	ctx := context.Background()
+
+	req, _ := c.NewRequest(ctx, http.MethodGet, "/down", nil)
+	res, err := c.DoResult(ctx, req)
+	if err != nil || string(res.Body) != "default" || res.StatusCode != http.StatusOK || !res.Fallback {
+		t.Fatalf("expected fallback result, got %+v %v", res, err)
+	}
+	if res.Attempts != 3 || gotAttempts != 3 || gotStatus != http.StatusServiceUnavailable || gotErr == nil {
+		t.Fatalf("fallback got status=%d attempts=%d err=%v, result attempts=%d", gotStatus, gotAttempts, gotErr, res.Attempts)
+	}
+
+	body, status, err := c.Get(ctx, "/ok")
+	if err != nil || string(body) != "live" || status != http.StatusOK {
+		t.Fatalf("expected live response, got %q %d %v", body, status, err)
+	}
+
+	_, status, err = c.Get(ctx, "/fail")
+	if err == nil || err.Error() != "no fallback" || status != http.StatusServiceUnavailable {
+		t.Fatalf("expected fallback error with last status, got %d %v", status, err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	gotErr = nil
+	if _, _, err := c.Get(canceled, "/down"); !errors.Is(err, context.Canceled) || gotErr != nil {
+		t.Fatalf("expected no fallback for canceled context, got %v (fallback err %v)", err, gotErr)
+	}
+}
+
+func TestFallbackOnlyWhenUnrecoverable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close()
+
+	var fallbacks atomic.Int32
+	var lastRes *Result
+	fb := WithFallback(func(ctx context.Context, req *http.Request, last *Result, err error) ([]byte, int, error) {
+		fallbacks.Add(1)
+		lastRes = last
+		return []byte("default"), http.StatusOK, nil
+	})
+	ctx := context.Background()
+
+	c := New(WithRetry(2, time.Millisecond), fb)
+	if _, status, err := c.Get(ctx, srv.URL+"/missing"); err == nil || status != http.StatusNotFound || fallbacks.Load() != 0 {
+		t.Fatalf("expected 404 without fallback, got %d %v (fallbacks %d)", status, err, fallbacks.Load())
+	}
+
+	c.SetOffline(true)
+	if _, _, err := c.Get(ctx, srv.URL); !errors.Is(err, ErrOffline) || fallbacks.Load() != 0 {
+		t.Fatalf("expected ErrOffline without fallback, got %v (fallbacks %d)", err, fallbacks.Load())
+	}
+	c.SetOffline(false)
+
+	c.Close()
+	if _, _, err := c.Get(ctx, srv.URL); !errors.Is(err, ErrClientClosed) || fallbacks.Load() != 0 {
+		t.Fatalf("expected ErrClientClosed without fallback, got %v (fallbacks %d)", err, fallbacks.Load())
+	}
+
+	c = New(WithRetry(0, 0), fb)
+	defer c.Close()
+	body, status, err := c.Get(ctx, deadURL)
+	if err != nil || string(body) != "default" || status != http.StatusOK || fallbacks.Load() != 1 {
+		t.Fatalf("expected fallback for unreachable host, got %q %d %v (fallbacks %d)", body, status, err, fallbacks.Load())
+	}
+	if lastRes == nil || lastRes.StatusCode != 0 || lastRes.Header != nil || lastRes.Body != nil {
+		t.Fatalf("expected an empty last result without a response, got %+v", lastRes)
+	}
+}
@@ -0,0 +1,89 @@
+package resilient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FileOutbox is an OutboxStore keeping one JSON file per message in a
+// directory. Files are replaced atomically, so a crash never leaves a
+// partial message behind.
+type FileOutbox struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileOutbox returns a FileOutbox in dir, creating it if needed.
+func NewFileOutbox(dir string) (*FileOutbox, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("resilient: outbox: %w", err)
+	}
+	return &FileOutbox{dir: dir}, nil
+}
+
+func (s *FileOutbox) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Put implements OutboxStore.
+func (s *FileOutbox) Put(msg *OutboxMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("resilient: outbox: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = writeFileAtomic(s.path(msg.ID), func(f *os.File) (int64, error) {
+		n, err := f.Write(data)
+		return int64(n), err
+	})
+	return err
+}
+
+// List implements OutboxStore.
+func (s *FileOutbox) List() ([]*OutboxMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("resilient: outbox: %w", err)
+	}
+	var msgs []*OutboxMessage
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resilient: outbox: %w", err)
+		}
+		var msg OutboxMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return nil, fmt.Errorf("resilient: outbox: %s: %w", name, err)
+		}
+		msgs = append(msgs, &msg)
+	}
+	slices.SortStableFunc(msgs, func(a, b *OutboxMessage) int { return a.Created.Compare(b.Created) })
+	return msgs, nil
+}
+
+// Delete implements OutboxStore.
+func (s *FileOutbox) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("resilient: outbox: %w", err)
+	}
+	return nil
+}
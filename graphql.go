@@ -0,0 +1,104 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLError is one entry of a GraphQL response's errors array.
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Code returns extensions.code, or "" if the server didn't set one.
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// GraphQLErrors is returned by GraphQL when the response carries errors.
+// Any partial data is still decoded into out.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Message
+	}
+	return "resilient: graphql: " + strings.Join(msgs, "; ")
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+// GraphQL posts query and variables to the base URL and decodes the
+// response's data field into out. Errors reported in the response body are
+// returned as GraphQLErrors; with WithGraphQLRetryCodes, errors carrying
+// one of the configured codes are retried first.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]any, out any) error {
+	payload, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("resilient: marshal request: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := c.NewRequest(ctx, http.MethodPost, "", bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", JSONCodec.ContentType())
+		req.Header.Set("Accept", JSONCodec.ContentType())
+
+		data, _, err := c.Do(req.Context(), req)
+		if err != nil {
+			return err
+		}
+		var resp graphQLResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return fmt.Errorf("resilient: unmarshal response: %w", err)
+		}
+		if len(resp.Errors) > 0 && attempt < c.cfg.maxRetries && c.graphQLRetryable(resp.Errors) {
+			backoff := c.Backoff(attempt + 1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-c.cfg.clock.After(backoff):
+			}
+			c.retries.Add(1)
+			c.backoffTime.Add(int64(backoff))
+			continue
+		}
+
+		if out != nil && len(resp.Data) > 0 && string(resp.Data) != "null" {
+			if err := json.Unmarshal(resp.Data, out); err != nil {
+				return fmt.Errorf("resilient: unmarshal response: %w", err)
+			}
+		}
+		if len(resp.Errors) > 0 {
+			return resp.Errors
+		}
+		return nil
+	}
+}
+
+func (c *Client) graphQLRetryable(errs GraphQLErrors) bool {
+	for _, e := range errs {
+		if c.cfg.graphQLRetry[e.Code()] {
+			return true
+		}
+	}
+	return false
+}
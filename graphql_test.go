@@ -0,0 +1,78 @@
+package resilient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGraphQL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.Method != "POST" || req.Query != "query($id: ID!) { user(id: $id) { name } }" || req.Variables["id"] != "1" {
+			t.Errorf("unexpected request: %s %+v", r.Method, req)
+		}
+		w.Write([]byte(`{"data":{"user":{"name":"ada"}}}`))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var out struct {
+		User struct{ Name string } `json:"user"`
+	}
+	err := c.GraphQL(context.Background(), "query($id: ID!) { user(id: $id) { name } }", map[string]any{"id": "1"}, &out)
+	if err != nil || out.User.Name != "ada" {
+		t.Fatalf("expected ada, got %+v %v", out, err)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"a":1,"b":null},"errors":[{"message":"b failed","path":["b"],"extensions":{"code":"INTERNAL"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithGraphQLRetryCodes("RATE_LIMITED"))
+	defer c.Close()
+
+	var out struct{ A int }
+	err := c.GraphQL(context.Background(), "{ a b }", nil, &out)
+	var gqlErrs GraphQLErrors
+	if !errors.As(err, &gqlErrs) || gqlErrs[0].Code() != "INTERNAL" {
+		t.Fatalf("expected GraphQLErrors, got %v", err)
+	}
+	if out.A != 1 {
+		t.Fatalf("expected partial data to be decoded, got %+v", out)
+	}
+}
+
+func TestGraphQLRetryCodes(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Write([]byte(`{"errors":[{"message":"slow down","extensions":{"code":"RATE_LIMITED"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(2, time.Millisecond), WithGraphQLRetryCodes("RATE_LIMITED"))
+	defer c.Close()
+
+	var out struct{ OK bool }
+	if err := c.GraphQL(context.Background(), "{ ok }", nil, &out); err != nil || !out.OK {
+		t.Fatalf("expected retry to succeed, got %+v %v", out, err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,76 @@
+package resilient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// Ping sends a single GET to the WithHealthCheck path ("/" by default),
+// without retries or caching, and reports whether the upstream is healthy:
+// a nil error means it answered with one of the expected statuses. The
+// result also updates Healthy.
+func (c *Client) Ping(ctx context.Context) error {
+	err := c.ping(ctx)
+	c.healthy.Store(err == nil)
+	return err
+}
+
+func (c *Client) ping(ctx context.Context) error {
+	path := c.cfg.healthPath
+	if path == "" {
+		path = "/"
+	}
+	req, err := c.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.Transport().RoundTrip(req)
+	if err != nil {
+		return fmt.Errorf("resilient: health check: %w", err)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if len(c.cfg.healthStatuses) > 0 {
+		ok = slices.Contains(c.cfg.healthStatuses, resp.StatusCode)
+	}
+	if !ok {
+		return fmt.Errorf("%w: HTTP %d", ErrUnhealthy, resp.StatusCode)
+	}
+	return nil
+}
+
+// Healthy reports whether the most recent Ping, manual or from the
+// WithHealthCheck background prober, succeeded. It is false until the
+// first Ping completes.
+func (c *Client) Healthy() bool {
+	return c.healthy.Load()
+}
+
+// startHealthCheck probes the upstream now and then every interval until
+// the client is closed.
+func (c *Client) startHealthCheck() {
+	interval := c.cfg.healthInterval
+	if interval <= 0 {
+		return
+	}
+	var probe func()
+	probe = func() {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		c.Ping(ctx)
+		cancel()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if !c.closed {
+			c.healthTimer = c.cfg.clock.AfterFunc(interval, probe)
+		}
+	}
+	c.mu.Lock()
+	c.healthTimer = c.cfg.clock.AfterFunc(0, probe)
+	c.mu.Unlock()
+}
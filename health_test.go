@@ -0,0 +1,69 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPing(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithHealthCheck("/healthz", 0, http.StatusOK, http.StatusNoContent))
+	defer c.Close()
+
+	if c.Healthy() {
+		t.Fatal("expected unhealthy before the first ping")
+	}
+	if err := c.Ping(context.Background()); err != nil || !c.Healthy() {
+		t.Fatalf("expected healthy, got %v", err)
+	}
+	status.Store(http.StatusAccepted)
+	if err := c.Ping(context.Background()); !errors.Is(err, ErrUnhealthy) || c.Healthy() {
+		t.Fatalf("expected ErrUnhealthy for an unexpected status, got %v", err)
+	}
+	if s := c.Stats(); s.TotalRequests != 2 {
+		t.Fatalf("expected a single attempt per ping, got %d", s.TotalRequests)
+	}
+}
+
+func TestHealthCheckProber(t *testing.T) {
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithHealthCheck("/", 10*time.Millisecond))
+	defer c.Close()
+
+	waitFor := func(healthy bool) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for c.Healthy() != healthy {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected Healthy() == %v", healthy)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor(true)
+	down.Store(true)
+	waitFor(false)
+	down.Store(false)
+	waitFor(true)
+}
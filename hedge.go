@@ -0,0 +1,124 @@
+package resilient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// hedgeResult is the outcome of one hedged request.
+type hedgeResult struct {
+	id     int
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+// cancelOnClose cancels the winning hedge's context once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// send performs a single attempt, hedging it when WithHedging is enabled.
+func (c *Client) send(ctx context.Context, u *url.URL, newReq func(context.Context) (*http.Request, error)) (*http.Response, error) {
+	if c.cfg.maxHedges <= 0 {
+		r, err := newReq(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c.httpClient.Do(r)
+	}
+
+	results := make(chan hedgeResult, c.cfg.maxHedges+1)
+	var cancels []context.CancelFunc
+	// launch sends one more copy of the request, unless it cannot be
+	// built.
+	launch := func() error {
+		hctx, cancel := context.WithCancel(ctx)
+		r, err := newReq(hctx)
+		if err != nil {
+			cancel()
+			return err
+		}
+		id := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := c.httpClient.Do(r)
+			results <- hedgeResult{id: id, resp: resp, err: err, cancel: cancel}
+		}()
+		return nil
+	}
+
+	if err := launch(); err != nil {
+		return nil, err
+	}
+	inFlight, hedges := 1, 0
+	timer := time.NewTimer(c.cfg.hedgeDelay)
+	defer timer.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case <-timer.C:
+			if hedges < c.cfg.maxHedges {
+				if tokens, ok := c.allowRateLimit(u); ok {
+					// A hedge that cannot be built is dropped and its
+					// tokens refunded; the requests in flight still
+					// decide the attempt.
+					hedges++
+					if launch() == nil {
+						inFlight++
+						c.hedged.Add(1)
+					} else {
+						tokens.refund()
+					}
+				}
+			}
+			if hedges < c.cfg.maxHedges {
+				timer.Reset(c.cfg.hedgeDelay)
+			}
+
+		case r := <-results:
+			inFlight--
+			if r.err != nil {
+				r.cancel()
+				lastErr = r.err
+				if inFlight == 0 {
+					return nil, lastErr
+				}
+				continue
+			}
+
+			// Cancel the losers and release whatever they return.
+			for id, cancel := range cancels {
+				if id != r.id {
+					cancel()
+				}
+			}
+			go drainHedges(results, inFlight)
+
+			r.resp.Body = &cancelOnClose{ReadCloser: r.resp.Body, cancel: r.cancel}
+			return r.resp, nil
+		}
+	}
+}
+
+// drainHedges closes the responses of n cancelled hedges.
+func drainHedges(results <-chan hedgeResult, n int) {
+	for i := 0; i < n; i++ {
+		r := <-results
+		if r.resp != nil {
+			r.resp.Body.Close()
+		}
+		r.cancel()
+	}
+}
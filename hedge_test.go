@@ -0,0 +1,107 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestHedging(t *testing.T) {
+	var calls, cancelled atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// The first request stalls until it is cancelled.
+			<-r.Context().Done()
+			cancelled.Add(1)
+			return
+		}
+		w.Write([]byte("hedge"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithHedging(30*time.Millisecond, 1))
+	defer c.Close()
+
+	start := time.Now()
+	body, status, err := c.Get(context.Background(), "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != 200 || string(body) != "hedge" {
+		t.Fatalf("unexpected: status=%d body=%s", status, body)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("hedge did not win: %v", elapsed)
+	}
+	if s := c.Stats(); s.Hedged != 1 || s.TotalRequests != 1 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for cancelled.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cancelled.Load() != 1 {
+		t.Fatal("losing request was not cancelled")
+	}
+}
+
+func TestHedgingFastResponse(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithHedging(200*time.Millisecond, 2))
+	defer c.Close()
+
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected no hedges for a fast response, got %d calls", n)
+	}
+}
+
+func TestHedgingSignerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(60 * time.Millisecond)
+		w.Write([]byte("primary"))
+	}))
+	defer srv.Close()
+
+	// The first attempt is signed; the hedge fails to sign.
+	var signed atomic.Int32
+	lim := rate.NewLimiter(0.001, 3)
+	c := New(WithBaseURL(srv.URL), WithLimiter(lim), WithHedging(10*time.Millisecond, 1),
+		WithSigner(SignerFunc(func(*http.Request) error {
+			if signed.Add(1) > 1 {
+				return errors.New("no key")
+			}
+			return nil
+		})))
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/")
+	if err != nil || string(body) != "primary" {
+		t.Fatalf("expected the primary response, got %q %v", body, err)
+	}
+	if n := signed.Load(); n != 2 {
+		t.Fatalf("expected a hedge to be attempted, got %d signings", n)
+	}
+	if s := c.Stats(); s.Hedged != 0 || s.TotalErrors != 0 {
+		t.Fatalf("expected the unsigned hedge to be dropped, got %+v", s)
+	}
+	// Only the primary's token is spent: the hedge's is refunded.
+	if got := lim.Tokens(); got < 1.99 || got > 2.01 {
+		t.Fatalf("expected 2 tokens left, have %.3f", got)
+	}
+}
@@ -0,0 +1,180 @@
+package resilient
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the latency histogram unless
+// WithLatencyBuckets replaces them.
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyBucket is one histogram bucket: the number of responses that took
+// at most UpperBound, and longer than the previous bucket's bound. The last
+// bucket's UpperBound is 0 and counts everything slower than the others.
+type LatencyBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// LatencyStats summarizes the response times of attempts, from sending the
+// request to receiving the response headers. Percentiles are estimated from
+// the histogram by linear interpolation within a bucket.
+type LatencyStats struct {
+	Count   uint64
+	Sum     time.Duration
+	Max     time.Duration
+	P50     time.Duration
+	P95     time.Duration
+	P99     time.Duration
+	Buckets []LatencyBucket
+}
+
+// Mean returns the average latency, or 0 without samples.
+func (s LatencyStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / time.Duration(s.Count)
+}
+
+// Percentile estimates the latency below which a fraction q (0 to 1) of
+// responses fall.
+func (s LatencyStats) Percentile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := q * float64(s.Count)
+	var seen float64
+	var lower time.Duration
+	for _, b := range s.Buckets {
+		upper := b.UpperBound
+		if upper == 0 {
+			upper = s.Max
+		}
+		if n := float64(b.Count); n > 0 && seen+n >= rank {
+			frac := (rank - seen) / n
+			return min(lower+time.Duration(frac*float64(upper-lower)), s.Max)
+		}
+		seen += float64(b.Count)
+		lower = upper
+	}
+	return s.Max
+}
+
+// latencyHistogram counts response times into fixed buckets.
+type latencyHistogram struct {
+	bounds []time.Duration
+	counts []atomic.Uint64 // len(bounds)+1, the last for overflow
+	count  atomic.Uint64
+	sum    atomic.Int64
+	max    atomic.Int64
+}
+
+func newLatencyHistogram(bounds []time.Duration) *latencyHistogram {
+	bounds = slices.Sorted(slices.Values(bounds))
+	return &latencyHistogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	i, _ := slices.BinarySearch(h.bounds, d)
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(int64(d))
+	for {
+		cur := h.max.Load()
+		if int64(d) <= cur || h.max.CompareAndSwap(cur, int64(d)) {
+			break
+		}
+	}
+}
+
+// snapshot returns the histogram's current state with percentiles filled in.
+func (h *latencyHistogram) snapshot() LatencyStats {
+	s := LatencyStats{
+		Count:   h.count.Load(),
+		Sum:     time.Duration(h.sum.Load()),
+		Max:     time.Duration(h.max.Load()),
+		Buckets: make([]LatencyBucket, len(h.counts)),
+	}
+	for i := range h.counts {
+		s.Buckets[i].Count = h.counts[i].Load()
+		if i < len(h.bounds) {
+			s.Buckets[i].UpperBound = h.bounds[i]
+		}
+	}
+	s.fillPercentiles()
+	return s
+}
+
+// reset zeroes the histogram.
+func (h *latencyHistogram) reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+	h.count.Store(0)
+	h.sum.Store(0)
+	h.max.Store(0)
+}
+
+// since returns the samples recorded after prev was taken from the same
+// histogram. Max is kept as is.
+func (s LatencyStats) since(prev LatencyStats) LatencyStats {
+	if s.Count < prev.Count || len(s.Buckets) != len(prev.Buckets) {
+		return s // reset in between
+	}
+	out := s
+	out.Count -= prev.Count
+	out.Sum -= prev.Sum
+	out.Buckets = make([]LatencyBucket, len(s.Buckets))
+	for i, b := range s.Buckets {
+		out.Buckets[i] = LatencyBucket{UpperBound: b.UpperBound, Count: delta(b.Count, prev.Buckets[i].Count)}
+	}
+	out.fillPercentiles()
+	return out
+}
+
+func (s *LatencyStats) fillPercentiles() {
+	s.P50, s.P95, s.P99 = s.Percentile(0.5), s.Percentile(0.95), s.Percentile(0.99)
+}
+
+// Latency returns the response-time histogram and its P50/P95/P99
+// estimates. The same figures are in Stats().Latency.
+func (c *Client) Latency() LatencyStats {
+	return c.histogram.snapshot()
+}
+
+// merge combines the samples of two histograms. Buckets are only kept when
+// both use the same bounds; otherwise percentiles cannot be estimated and
+// are left at 0.
+func (s LatencyStats) merge(o LatencyStats) LatencyStats {
+	if o.Count == 0 && len(o.Buckets) == 0 {
+		return s
+	}
+	if s.Count == 0 && len(s.Buckets) == 0 {
+		return o
+	}
+	out := LatencyStats{Count: s.Count + o.Count, Sum: s.Sum + o.Sum, Max: max(s.Max, o.Max)}
+	if !slices.EqualFunc(s.Buckets, o.Buckets, func(a, b LatencyBucket) bool { return a.UpperBound == b.UpperBound }) {
+		return out
+	}
+	out.Buckets = make([]LatencyBucket, len(s.Buckets))
+	for i, b := range s.Buckets {
+		out.Buckets[i] = LatencyBucket{UpperBound: b.UpperBound, Count: b.Count + o.Buckets[i].Count}
+	}
+	out.fillPercentiles()
+	return out
+}
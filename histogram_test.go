@@ -0,0 +1,101 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLatencyPercentiles(t *testing.T) {
+	h := newLatencyHistogram([]time.Duration{100 * time.Millisecond, 10 * time.Millisecond})
+	for range 90 {
+		h.observe(5 * time.Millisecond)
+	}
+	for range 9 {
+		h.observe(50 * time.Millisecond)
+	}
+	h.observe(300 * time.Millisecond)
+
+	s := h.snapshot()
+	if s.Count != 100 || s.Max != 300*time.Millisecond || s.Mean() != (450+450+300)*time.Millisecond/100 {
+		t.Fatalf("unexpected totals %+v", s)
+	}
+	if len(s.Buckets) != 3 || s.Buckets[0].UpperBound != 10*time.Millisecond || s.Buckets[2].UpperBound != 0 {
+		t.Fatalf("expected sorted buckets plus overflow, got %+v", s.Buckets)
+	}
+	// 50 of the 90 samples in (0, 10ms] puts P50 at 5.6ms.
+	if s.P50 < 5*time.Millisecond || s.P50 > 6*time.Millisecond {
+		t.Fatalf("expected P50 around 5.6ms, got %v", s.P50)
+	}
+	if s.P95 <= 10*time.Millisecond || s.P95 > 100*time.Millisecond {
+		t.Fatalf("expected P95 in the (10ms, 100ms] bucket, got %v", s.P95)
+	}
+	if s.P99 > 100*time.Millisecond {
+		t.Fatalf("expected P99 within 100ms, got %v", s.P99)
+	}
+	if p := s.Percentile(1); p != 300*time.Millisecond {
+		t.Fatalf("expected P100 to be the max, got %v", p)
+	}
+}
+
+func TestClientLatency(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithLatencyBuckets(10*time.Millisecond, 30*time.Millisecond, time.Second))
+	defer c.Close()
+	for range 3 {
+		c.Get(context.Background(), "/")
+	}
+
+	l := c.Latency()
+	if l.Count != 3 || l.Buckets[1].Count != 3 {
+		t.Fatalf("expected 3 samples in the (10ms, 30ms] bucket, got %+v", l)
+	}
+	if l.P50 < 10*time.Millisecond || l.P50 > 30*time.Millisecond {
+		t.Fatalf("expected P50 between 10ms and 30ms, got %v", l.P50)
+	}
+	if c.Stats().Latency.Count != 3 {
+		t.Fatal("expected Stats to carry the latency histogram")
+	}
+}
+
+func TestStatsSince(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+	for range 3 {
+		c.Get(context.Background(), "/")
+	}
+	prev := c.Stats()
+	for range 2 {
+		c.Get(context.Background(), "/")
+	}
+
+	d := c.StatsSince(prev)
+	if d.TotalRequests != 2 || d.Latency.Count != 2 {
+		t.Fatalf("expected 2 requests in the interval, got %d (%d samples)", d.TotalRequests, d.Latency.Count)
+	}
+	var n uint64
+	for _, b := range d.Latency.Buckets {
+		n += b.Count
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 samples across the buckets, got %d", n)
+	}
+
+	c.ResetStats()
+	if s := c.Stats(); s.TotalRequests != 0 || s.Latency.Count != 0 || s.Latency.Max != 0 {
+		t.Fatalf("expected zeroed stats, got %+v", s)
+	}
+	c.Get(context.Background(), "/")
+	if d := c.StatsSince(prev); d.TotalRequests != 1 || d.Latency.Count != 1 {
+		t.Fatalf("expected counting from zero after a reset, got %d (%d samples)", d.TotalRequests, d.Latency.Count)
+	}
+}
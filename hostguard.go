@@ -0,0 +1,196 @@
+package resilient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaultBreakerCooldown is how long an open circuit rejects attempts when
+// HostPolicy.BreakerCooldown is unset.
+const defaultBreakerCooldown = 30 * time.Second
+
+// HostPolicy configures the circuit breaker and bulkhead of a request host,
+// for WithPerHostPolicy and WithHostPolicy. Zero fields leave the feature
+// off.
+type HostPolicy struct {
+	// MaxConcurrent caps the attempts in flight to the host; further
+	// attempts wait for a slot.
+	MaxConcurrent int
+
+	// BreakerThreshold opens the host's circuit after this many consecutive
+	// failed attempts (network errors and 5xx responses). While open,
+	// calls to the host fail at once with ErrCircuitOpen.
+	BreakerThreshold int
+	// BreakerCooldown is how long the circuit stays open before a single
+	// trial attempt is let through; its success closes the circuit and its
+	// failure opens it again. The default is 30s.
+	BreakerCooldown time.Duration
+}
+
+// breakerState is the state of a host's circuit.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen // a trial attempt is in flight
+)
+
+// hostGuard is the circuit breaker and bulkhead of one host.
+type hostGuard struct {
+	host     string
+	policy   HostPolicy
+	slots    chan struct{} // nil without MaxConcurrent
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// hostGuards holds the hostGuard of every host called, created on first
+// use from the host's policy.
+type hostGuards struct {
+	defaults  *HostPolicy
+	overrides map[string]HostPolicy
+
+	mu     sync.Mutex
+	guards map[string]*hostGuard
+}
+
+// newHostGuards returns nil unless WithPerHostPolicy or WithHostPolicy is
+// configured.
+func newHostGuards(cfg *config) *hostGuards {
+	if cfg.hostPolicy == nil && len(cfg.hostPolicies) == 0 {
+		return nil
+	}
+	return &hostGuards{defaults: cfg.hostPolicy, overrides: cfg.hostPolicies, guards: make(map[string]*hostGuard)}
+}
+
+// guard returns the hostGuard of host, or nil if no policy applies to it.
+func (g *hostGuards) guard(host string) *hostGuard {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if hg, ok := g.guards[host]; ok {
+		return hg
+	}
+	p, ok := g.overrides[host]
+	if !ok {
+		if g.defaults == nil {
+			g.guards[host] = nil
+			return nil
+		}
+		p = *g.defaults
+	}
+	if p.BreakerCooldown <= 0 {
+		p.BreakerCooldown = defaultBreakerCooldown
+	}
+	hg := &hostGuard{host: host, policy: p}
+	if p.MaxConcurrent > 0 {
+		hg.slots = make(chan struct{}, p.MaxConcurrent)
+	}
+	g.guards[host] = hg
+	return hg
+}
+
+// allow reports whether an attempt may be sent, moving an open circuit
+// whose cooldown has passed to half-open for a trial attempt.
+func (hg *hostGuard) allow() error {
+	if hg == nil || hg.policy.BreakerThreshold <= 0 {
+		return nil
+	}
+	hg.mu.Lock()
+	defer hg.mu.Unlock()
+	switch hg.state {
+	case breakerOpen:
+		if time.Since(hg.openedAt) < hg.policy.BreakerCooldown {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, hg.host)
+		}
+		hg.state = breakerHalfOpen
+	case breakerHalfOpen:
+		return fmt.Errorf("%w: %s", ErrCircuitOpen, hg.host)
+	}
+	return nil
+}
+
+// record feeds an attempt's outcome to the breaker and reports whether it
+// opened the circuit. Attempts abandoned by the caller count neither way,
+// but end a trial so that the next one can be made.
+func (hg *hostGuard) record(resp *http.Response, err error, abandoned bool) bool {
+	if hg == nil || hg.policy.BreakerThreshold <= 0 {
+		return false
+	}
+	hg.mu.Lock()
+	defer hg.mu.Unlock()
+	switch {
+	case abandoned:
+		if hg.state == breakerHalfOpen {
+			hg.state = breakerOpen // keep openedAt: the cooldown has passed
+		}
+		return false
+	case err == nil && resp.StatusCode < 500:
+		hg.state, hg.failures = breakerClosed, 0
+		return false
+	}
+	hg.failures++
+	if hg.state == breakerHalfOpen || hg.failures >= hg.policy.BreakerThreshold {
+		hg.state, hg.failures, hg.openedAt = breakerOpen, 0, time.Now()
+		return true
+	}
+	return false
+}
+
+// acquire takes one of the host's slots, waiting until one is free or ctx
+// is done. It reports whether the caller had to wait.
+func (hg *hostGuard) acquire(ctx context.Context) (bool, error) {
+	if hg == nil || hg.slots == nil {
+		return false, nil
+	}
+	select {
+	case hg.slots <- struct{}{}:
+		return false, nil
+	default:
+	}
+	select {
+	case hg.slots <- struct{}{}:
+		return true, nil
+	case <-ctx.Done():
+		return true, ctx.Err()
+	}
+}
+
+// release returns the slot taken by acquire.
+func (hg *hostGuard) release() {
+	if hg != nil && hg.slots != nil {
+		<-hg.slots
+	}
+}
+
+// attemptHost returns the host an attempt of req is sent to: that of the
+// picked endpoint with failover or discovery, otherwise req's own.
+func attemptHost(req *http.Request, ep *endpoint) string {
+	if ep != nil {
+		if u, err := url.Parse(ep.base); err == nil {
+			return u.Host
+		}
+	}
+	return req.URL.Host
+}
+
+// CircuitOpen reports whether the circuit breaker of host, as configured
+// with WithPerHostPolicy or WithHostPolicy, is rejecting calls.
+func (c *Client) CircuitOpen(host string) bool {
+	hg := c.hosts.guard(host)
+	if hg == nil {
+		return false
+	}
+	hg.mu.Lock()
+	defer hg.mu.Unlock()
+	return hg.state == breakerOpen && time.Since(hg.openedAt) < hg.policy.BreakerCooldown
+}
@@ -0,0 +1,124 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostCircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	var calls atomic.Int32
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer bad.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer good.Close()
+
+	c := New(WithRetry(0, 0), WithPerHostPolicy(HostPolicy{BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond}))
+	defer c.Close()
+	events := c.Events()
+	ctx := context.Background()
+	get := func(base string) error {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, base+"/x", nil)
+		_, _, err := c.Do(ctx, req)
+		return err
+	}
+
+	get(bad.URL)
+	get(bad.URL)
+	badHost := mustHost(t, bad.URL)
+	if !c.CircuitOpen(badHost) {
+		t.Fatal("expected circuit to open after 2 failures")
+	}
+	if err := get(bad.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected open circuit not to send, got %d calls", n)
+	}
+	if err := get(good.URL); err != nil {
+		t.Fatalf("other host must not be affected: %v", err)
+	}
+
+	var opened bool
+	for len(events) > 0 {
+		if ev := <-events; ev.Type == EventCircuitOpen && ev.Host == badHost {
+			opened = true
+		}
+	}
+	if !opened {
+		t.Fatal("expected EventCircuitOpen for the failing host")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	failing.Store(false)
+	if err := get(bad.URL); err != nil {
+		t.Fatalf("expected trial attempt to succeed, got %v", err)
+	}
+	if c.CircuitOpen(badHost) {
+		t.Fatal("expected circuit to close after a successful trial")
+	}
+}
+
+func TestHostBulkhead(t *testing.T) {
+	var mu sync.Mutex
+	inflight, peak := map[string]int{}, map[string]int{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inflight[r.Host]++
+		peak[r.Host] = max(peak[r.Host], inflight[r.Host])
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inflight[r.Host]--
+		mu.Unlock()
+	})
+	a := httptest.NewServer(handler)
+	defer a.Close()
+	b := httptest.NewServer(handler)
+	defer b.Close()
+
+	hostA, hostB := mustHost(t, a.URL), mustHost(t, b.URL)
+	c := New(WithPerHostPolicy(HostPolicy{MaxConcurrent: 3}), WithHostPolicy(hostA, HostPolicy{MaxConcurrent: 1}))
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		for _, base := range []string{a.URL, b.URL} {
+			wg.Go(func() {
+				req, _ := http.NewRequest(http.MethodGet, base, nil)
+				if _, _, err := c.Do(context.Background(), req); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+	wg.Wait()
+	if peak[hostA] != 1 {
+		t.Fatalf("expected at most 1 call in flight to %s, got %d", hostA, peak[hostA])
+	}
+	if peak[hostB] < 2 || peak[hostB] > 3 {
+		t.Fatalf("expected 2-3 calls in flight to %s, got %d", hostB, peak[hostB])
+	}
+}
+
+func mustHost(t *testing.T, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host
+}
@@ -0,0 +1,134 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// RPCError is a JSON-RPC 2.0 error object returned by the server.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("resilient: jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// RPCCall is one call of a DoJSONRPCBatch batch. After the batch, Result
+// holds the decoded result and Err the call's error, if any.
+type RPCCall struct {
+	Method string
+	Params any
+	Result any
+	Err    error
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	ID     *uint64         `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// DoJSONRPC calls a JSON-RPC 2.0 method at the base URL and decodes its
+// result into result, which may be nil. Request ids are generated by the
+// client. An error object in the response is returned as *RPCError;
+// transport failures go through the usual retry pipeline first, so only
+// use retries with methods that are safe to repeat.
+func (c *Client) DoJSONRPC(ctx context.Context, method string, params, result any) error {
+	call := &RPCCall{Method: method, Params: params, Result: result}
+	if err := c.DoJSONRPCBatch(ctx, []*RPCCall{call}); err != nil {
+		return err
+	}
+	return call.Err
+}
+
+// DoJSONRPCBatch sends calls as a single JSON-RPC 2.0 batch and matches the
+// responses back by id, setting each call's Result and Err. The returned
+// error reports failures of the batch as a whole.
+func (c *Client) DoJSONRPCBatch(ctx context.Context, calls []*RPCCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	reqs := make([]rpcRequest, len(calls))
+	byID := make(map[uint64]*RPCCall, len(calls))
+	for i, call := range calls {
+		id := c.rpcID.Add(1)
+		reqs[i] = rpcRequest{JSONRPC: "2.0", ID: id, Method: call.Method, Params: call.Params}
+		byID[id] = call
+	}
+
+	// A single call is sent as a plain object, not a batch of one.
+	var payload []byte
+	var err error
+	if len(reqs) == 1 {
+		payload, err = json.Marshal(reqs[0])
+	} else {
+		payload, err = json.Marshal(reqs)
+	}
+	if err != nil {
+		return fmt.Errorf("resilient: marshal request: %w", err)
+	}
+
+	req, err := c.NewRequest(ctx, http.MethodPost, "", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", JSONCodec.ContentType())
+	req.Header.Set("Accept", JSONCodec.ContentType())
+	data, _, err := c.Do(req.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	var resps []rpcResponse
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &resps)
+	} else {
+		resps = make([]rpcResponse, 1)
+		err = json.Unmarshal(data, &resps[0])
+	}
+	if err != nil {
+		return fmt.Errorf("resilient: unmarshal response: %w", err)
+	}
+
+	for _, resp := range resps {
+		if resp.ID == nil {
+			// The server couldn't attribute the error to a call, e.g. a
+			// parse error: it applies to the whole batch.
+			if resp.Error != nil {
+				return resp.Error
+			}
+			continue
+		}
+		call, ok := byID[*resp.ID]
+		if !ok {
+			continue
+		}
+		delete(byID, *resp.ID)
+		switch {
+		case resp.Error != nil:
+			call.Err = resp.Error
+		case call.Result != nil && len(resp.Result) > 0:
+			if err := json.Unmarshal(resp.Result, call.Result); err != nil {
+				call.Err = fmt.Errorf("resilient: unmarshal result: %w", err)
+			}
+		}
+	}
+	for id, call := range byID {
+		call.Err = fmt.Errorf("resilient: jsonrpc: no response for %s (id %d)", call.Method, id)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package resilient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// rpcServer answers "add" with the sum of its params, "fail" with an error
+// object and drops "lost". Batches are answered in reverse order.
+func rpcServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var reqs []map[string]any
+		batch := body[0] == '['
+		if batch {
+			json.Unmarshal(body, &reqs)
+		} else {
+			reqs = make([]map[string]any, 1)
+			json.Unmarshal(body, &reqs[0])
+		}
+
+		var resps []map[string]any
+		for _, req := range reqs {
+			if req["jsonrpc"] != "2.0" {
+				t.Errorf("missing jsonrpc version: %v", req)
+			}
+			resp := map[string]any{"jsonrpc": "2.0", "id": req["id"]}
+			switch req["method"] {
+			case "add":
+				sum := 0.0
+				for _, p := range req["params"].([]any) {
+					sum += p.(float64)
+				}
+				resp["result"] = sum
+			case "fail":
+				resp["error"] = map[string]any{"code": -32601, "message": "method not found"}
+			case "lost":
+				continue
+			}
+			resps = append(resps, resp)
+		}
+		slices.Reverse(resps)
+		if batch {
+			json.NewEncoder(w).Encode(resps)
+		} else {
+			json.NewEncoder(w).Encode(resps[0])
+		}
+	}))
+}
+
+func TestDoJSONRPC(t *testing.T) {
+	srv := rpcServer(t)
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var sum int
+	if err := c.DoJSONRPC(context.Background(), "add", []int{1, 2}, &sum); err != nil || sum != 3 {
+		t.Fatalf("expected 3, got %d %v", sum, err)
+	}
+
+	var rpcErr *RPCError
+	if err := c.DoJSONRPC(context.Background(), "fail", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Fatalf("expected RPCError, got %v", err)
+	}
+}
+
+func TestDoJSONRPCBatch(t *testing.T) {
+	srv := rpcServer(t)
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var a, b int
+	calls := []*RPCCall{
+		{Method: "add", Params: []int{1, 1}, Result: &a},
+		{Method: "fail"},
+		{Method: "add", Params: []int{2, 3}, Result: &b},
+		{Method: "lost"},
+	}
+	if err := c.DoJSONRPCBatch(context.Background(), calls); err != nil {
+		t.Fatal(err)
+	}
+	if a != 2 || b != 5 || calls[0].Err != nil || calls[2].Err != nil {
+		t.Fatalf("expected results matched by id, got %d %d", a, b)
+	}
+	if calls[1].Err == nil || calls[3].Err == nil {
+		t.Fatalf("expected per-call errors, got %v / %v", calls[1].Err, calls[3].Err)
+	}
+}
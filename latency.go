@@ -0,0 +1,68 @@
+package resilient
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// latencyCutInterval is the minimum time between two latency-triggered rate
+// reductions, giving the upstream a chance to recover before cutting again.
+const latencyCutInterval = time.Second
+
+// latencyMinSamples is how many responses are observed before the tracker
+// starts judging latency.
+const latencyMinSamples = 10
+
+// latencyTracker detects congestion from response times, Vegas style: it
+// compares a smoothed latency against the best latency seen recently.
+type latencyTracker struct {
+	tolerance float64
+
+	mu       sync.Mutex
+	baseline time.Duration
+	smoothed time.Duration
+	samples  int
+	lastCut  time.Time
+}
+
+// observe records one response time and reports whether the rate should be
+// reduced.
+func (lt *latencyTracker) observe(d time.Duration, now time.Time) bool {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	lt.samples++
+	if lt.samples == 1 {
+		lt.baseline, lt.smoothed = d, d
+		return false
+	}
+	// EWMA with alpha 1/8, as TCP uses for its RTT estimate.
+	lt.smoothed += (d - lt.smoothed) / 8
+	if d < lt.baseline {
+		lt.baseline = d
+	} else {
+		// Let the baseline drift up slowly so a permanent shift in the
+		// upstream's latency doesn't throttle forever.
+		lt.baseline += (d - lt.baseline) / 256
+	}
+
+	if lt.samples < latencyMinSamples || now.Sub(lt.lastCut) < latencyCutInterval {
+		return false
+	}
+	if float64(lt.smoothed) > float64(lt.baseline)*lt.tolerance {
+		lt.lastCut = now
+		return true
+	}
+	return false
+}
+
+// observeLatency records an attempt's response time in the histogram and
+// feeds it to the latency tracker, reducing the rate when the upstream is
+// slowing down.
+func (c *Client) observeLatency(u *url.URL, d time.Duration) {
+	c.histogram.observe(d)
+	if c.latency != nil && c.latency.observe(d, time.Now()) {
+		c.reduceRateLimit(u)
+	}
+}
@@ -0,0 +1,55 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	lt := &latencyTracker{tolerance: 2}
+	now := time.Now()
+	for i := 0; i < latencyMinSamples; i++ {
+		if lt.observe(10*time.Millisecond, now) {
+			t.Fatal("expected no congestion at steady latency")
+		}
+	}
+
+	cut := false
+	for i := 0; i < 20 && !cut; i++ {
+		cut = lt.observe(100*time.Millisecond, now)
+	}
+	if !cut {
+		t.Fatal("expected congestion once latency degrades")
+	}
+	if lt.observe(100*time.Millisecond, now) {
+		t.Fatal("expected cuts to be spaced by latencyCutInterval")
+	}
+	if !lt.observe(100*time.Millisecond, now.Add(latencyCutInterval)) {
+		t.Fatal("expected another cut after the interval")
+	}
+}
+
+func TestLatencyThrottling(t *testing.T) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.Add(1) > latencyMinSamples {
+			time.Sleep(30 * time.Millisecond)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRateLimit(1000, 10), WithLatencyThrottling(2))
+	defer c.Close()
+
+	for i := 0; i < latencyMinSamples+10; i++ {
+		c.Get(context.Background(), "/")
+	}
+	if got := c.limiter.Limit(); got >= 1000 {
+		t.Fatalf("expected slow responses to reduce the rate, got %v", got)
+	}
+}
@@ -0,0 +1,136 @@
+package resilient
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter paces outgoing requests. *rate.Limiter from
+// golang.org/x/time/rate satisfies it, and custom implementations can be
+// plugged in with WithLimiter.
+type RateLimiter interface {
+	// Wait blocks until a request may proceed or ctx is done.
+	Wait(ctx context.Context) error
+	// Allow reports whether a request may proceed now, consuming a token if so.
+	Allow() bool
+	// Limit returns the current rate in requests per second.
+	Limit() rate.Limit
+	// SetLimit changes the rate; used by adaptive reduction and SetRateLimit.
+	SetLimit(newLimit rate.Limit)
+}
+
+// reservingLimiter is implemented by limiters that support reservations,
+// such as *rate.Limiter. It enables exact load shedding and lets the
+// priority queue return unused tokens.
+type reservingLimiter interface {
+	Reserve() *rate.Reservation
+	Tokens() float64
+	Limit() rate.Limit
+}
+
+// Compile-time interface check.
+var _ RateLimiter = (*rate.Limiter)(nil)
+
+// rateToken is a token taken from a reserving limiter, which refund hands
+// back. The zero rateToken holds nothing.
+type rateToken struct {
+	r  *rate.Reservation
+	at time.Time // when the reservation takes effect
+}
+
+// reserveToken reserves a token from lim and returns how long to wait
+// before using it. The token is zero if lim cannot grant one.
+func reserveToken(lim reservingLimiter) (rateToken, time.Duration) {
+	now := time.Now()
+	r := lim.Reserve()
+	if !r.OK() {
+		return rateToken{}, 0
+	}
+	// Reserve reads the clock after now, so the reservation takes effect
+	// exactly DelayFrom(now) after it; the wait is counted from the
+	// present, and is zero for a token available at once.
+	return rateToken{r: r, at: now.Add(r.DelayFrom(now))}, r.Delay()
+}
+
+// refund hands the token back to its limiter. Reservation.Cancel only
+// restores tokens whose time has not come yet, so the token is cancelled
+// as of the moment it took effect; tokens reserved after it since are
+// accounted for by the limiter.
+func (t rateToken) refund() {
+	if t.r != nil {
+		t.r.CancelAt(t.at)
+	}
+}
+
+// allowToken takes a token from lim if one is available now.
+func allowToken(lim RateLimiter) (rateToken, bool) {
+	rl, ok := lim.(reservingLimiter)
+	if !ok {
+		return rateToken{}, lim.Allow()
+	}
+	t, delay := reserveToken(rl)
+	if t.r == nil {
+		return rateToken{}, false
+	}
+	if delay > 0 {
+		t.refund()
+		return rateToken{}, false
+	}
+	return t, true
+}
+
+// rateTokens are the tokens one attempt took from the limiters that apply
+// to it.
+type rateTokens []rateToken
+
+func (ts rateTokens) refund() {
+	for _, t := range ts {
+		t.refund()
+	}
+}
+
+// pathLimiter is a token bucket for requests whose path matches pattern,
+// configured with WithEndpointRateLimit.
+type pathLimiter struct {
+	pattern string
+	scopedLimiter
+}
+
+func newPathLimiters(cfg *config) []*pathLimiter {
+	var pls []*pathLimiter
+	for _, r := range cfg.endpointLimits {
+		pls = append(pls, &pathLimiter{
+			pattern: r.pattern,
+			scopedLimiter: scopedLimiter{
+				limiter:  rate.NewLimiter(rate.Limit(r.rps), r.burst),
+				original: rate.Limit(r.rps),
+			},
+		})
+	}
+	return pls
+}
+
+// pathLimiter returns the limiter of the first rule matching p, or nil.
+func (c *Client) pathLimiter(p string) *pathLimiter {
+	for _, pl := range c.pathLimiters {
+		if matchPath(pl.pattern, p) {
+			return pl
+		}
+	}
+	return nil
+}
+
+// matchPath reports whether p matches pattern. A trailing "/*" matches any
+// path below the prefix; otherwise pattern uses path.Match syntax, where
+// "*" matches a single segment.
+func matchPath(pattern, p string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(p, prefix+"/") {
+		return true
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}
@@ -0,0 +1,236 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// countingLimiter is a RateLimiter without reservation support.
+type countingLimiter struct {
+	mu    sync.Mutex
+	waits int
+	limit rate.Limit
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.waits++
+	l.mu.Unlock()
+	return ctx.Err()
+}
+
+func (l *countingLimiter) Allow() bool { return true }
+
+func (l *countingLimiter) Limit() rate.Limit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+func (l *countingLimiter) SetLimit(newLimit rate.Limit) {
+	l.mu.Lock()
+	l.limit = newLimit
+	l.mu.Unlock()
+}
+
+func TestWithLimiter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	lim := &countingLimiter{limit: 10}
+	c := New(WithBaseURL(srv.URL), WithLimiter(lim), WithRateLimit(1, 1))
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, _, err := c.Get(context.Background(), "/"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if lim.waits != 3 {
+		t.Fatalf("expected custom limiter to be used 3 times, got %d", lim.waits)
+	}
+
+	c.SetRateLimit(4, 2)
+	if lim.Limit() != 4 {
+		t.Fatalf("expected SetRateLimit to reach custom limiter, got %v", lim.Limit())
+	}
+}
+
+func TestWithLimiterAdaptive(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	lim := &countingLimiter{limit: 10}
+	c := New(WithBaseURL(srv.URL), WithLimiter(lim), WithRetry(1, time.Millisecond))
+	defer c.Close()
+
+	c.Get(context.Background(), "/")
+	if lim.Limit() != 5 {
+		t.Fatalf("expected adaptive reduction to halve custom limiter, got %v", lim.Limit())
+	}
+}
+
+func TestWithLimiterPriorityQueue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	lim := &countingLimiter{limit: 10}
+	c := New(WithBaseURL(srv.URL), WithLimiter(lim), WithPriorityQueue())
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := c.Get(context.Background(), "/"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWithPacing(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRateLimit(100, 10), WithPacing(30*time.Millisecond))
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get(context.Background(), "/")
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(times) != 4 {
+		t.Fatalf("expected 4 requests, got %d", len(times))
+	}
+	first, last := times[0], times[0]
+	for _, ts := range times {
+		if ts.Before(first) {
+			first = ts
+		}
+		if ts.After(last) {
+			last = ts
+		}
+	}
+	if spread := last.Sub(first); spread < 80*time.Millisecond {
+		t.Fatalf("expected requests to be paced ~30ms apart, spread was %v", spread)
+	}
+}
+
+func TestEndpointRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithEndpointRateLimit("/search/*", 10, 1))
+	defer c.Close()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		c.Get(context.Background(), "/users/1")
+	}
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Fatalf("expected unmatched paths to be unlimited, took %v", d)
+	}
+
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		c.Get(context.Background(), "/search/repos")
+	}
+	if d := time.Since(start); d < 150*time.Millisecond {
+		t.Fatalf("expected /search/* to be limited to 10 rps, took %v", d)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/search/*", "/search/repos", true},
+		{"/search/*", "/search/repos/deep", true},
+		{"/search/*", "/searching", false},
+		{"/users/*/repos", "/users/42/repos", true},
+		{"/users/*/repos", "/users/42/gists", false},
+		{"/rate_limit", "/rate_limit", true},
+	}
+	for _, tt := range tests {
+		if got := matchPath(tt.pattern, tt.path); got != tt.want {
+			t.Fatalf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitRefund(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	var failSign atomic.Bool
+	lim := rate.NewLimiter(0.001, 2)
+	c := New(WithBaseURL(srv.URL), WithLimiter(lim), WithRetry(0, 0),
+		WithEndpointRateLimit("/slow", 0.001, 1),
+		WithSigner(SignerFunc(func(*http.Request) error {
+			if failSign.Load() {
+				return errors.New("no key")
+			}
+			return nil
+		})))
+	defer c.Close()
+	ctx := context.Background()
+
+	if _, _, err := c.Get(ctx, "/slow"); err != nil {
+		t.Fatal(err)
+	}
+	if got := lim.Tokens(); got > 1.01 {
+		t.Fatalf("expected a token to be spent, have %.2f", got)
+	}
+
+	// The /slow limiter is empty: the client-wide token must come back.
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.Get(short, "/slow"); !errors.Is(err, ErrRateLimitWait) {
+		t.Fatalf("expected ErrRateLimitWait, got %v", err)
+	}
+	if got := lim.Tokens(); got < 0.99 {
+		t.Fatalf("expected the token to be refunded after a failed wait, have %.2f", got)
+	}
+
+	failSign.Store(true)
+	if _, _, err := c.Get(ctx, "/other"); err == nil {
+		t.Fatal("expected signing to fail")
+	}
+	if got := lim.Tokens(); got < 0.99 {
+		t.Fatalf("expected the token to be refunded after a signing error, have %.2f", got)
+	}
+}
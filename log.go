@@ -0,0 +1,80 @@
+package resilient
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// LogLevels sets the level of each kind of record written by WithLogger.
+type LogLevels struct {
+	// Request is used for completed calls.
+	Request slog.Level
+	// Retry is used for retries.
+	Retry slog.Level
+	// RateLimit is used for adaptive rate reductions and restores.
+	RateLimit slog.Level
+	// Error is used for failed calls.
+	Error slog.Level
+}
+
+// DefaultLogLevels are the levels WithLogger uses unless WithLogLevels
+// overrides them.
+var DefaultLogLevels = LogLevels{
+	Request:   slog.LevelDebug,
+	Retry:     slog.LevelInfo,
+	RateLimit: slog.LevelWarn,
+	Error:     slog.LevelError,
+}
+
+// logEvent writes ev to the WithLogger logger, if it is one worth logging.
+func (c *Client) logEvent(ev Event) {
+	l, levels := c.cfg.logger, c.cfg.logLevels
+	switch ev.Type {
+	case EventRetry:
+		attrs := []slog.Attr{
+			slog.String("method", ev.Method),
+			slog.String("url", ev.URL),
+			slog.Int("attempt", ev.Attempt),
+			slog.Duration("wait", ev.Wait),
+		}
+		if ev.StatusCode != 0 {
+			attrs = append(attrs, slog.Int("status", ev.StatusCode))
+		}
+		if ev.Err != nil {
+			attrs = append(attrs, slog.Any("error", ev.Err))
+		}
+		l.LogAttrs(context.Background(), levels.Retry, "resilient: retrying request", attrs...)
+	case EventRateReduced, EventRateRestored:
+		msg := "resilient: rate limit reduced"
+		if ev.Type == EventRateRestored {
+			msg = "resilient: rate limit restored"
+		}
+		attrs := []slog.Attr{slog.Float64("rate", ev.Rate)}
+		if ev.Limiter != "" {
+			attrs = append(attrs, slog.String("limiter", ev.Limiter))
+		}
+		if ev.URL != "" {
+			attrs = append(attrs, slog.String("url", ev.URL))
+		}
+		l.LogAttrs(context.Background(), levels.RateLimit, msg, attrs...)
+	}
+}
+
+// logComplete writes the outcome of a call to the WithLogger logger.
+func (c *Client) logComplete(req *http.Request, res *Result, err error) {
+	l, levels := c.cfg.logger, c.cfg.logLevels
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL, c.redact).Redacted()),
+		slog.Int("status", res.StatusCode),
+		slog.Int("attempts", res.Attempts),
+		slog.Duration("duration", res.Duration),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		l.LogAttrs(req.Context(), levels.Error, "resilient: request failed", attrs...)
+		return
+	}
+	l.LogAttrs(req.Context(), levels.Request, "resilient: request", attrs...)
+}
@@ -0,0 +1,148 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := New(WithBaseURL(srv.URL), WithRateLimit(100, 1), WithRetry(1, time.Millisecond),
+		WithLogger(logger), WithLogLevels(LogLevels{Request: slog.LevelInfo, Retry: slog.LevelWarn, RateLimit: slog.LevelWarn, Error: slog.LevelError}))
+	defer c.Close()
+
+	c.Get(context.Background(), "/ok")
+	c.Get(context.Background(), "/missing")
+
+	type record struct {
+		Level    string
+		Msg      string
+		URL      string
+		Status   int
+		Attempt  int
+		Attempts int
+		Rate     float64
+		Error    string
+	}
+	var got []record
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	want := []record{
+		{Level: "WARN", Msg: "resilient: rate limit reduced", URL: srv.URL + "/ok", Rate: 50},
+		{Level: "WARN", Msg: "resilient: retrying request", URL: srv.URL + "/ok", Status: 429, Attempt: 2},
+		{Level: "INFO", Msg: "resilient: request", URL: srv.URL + "/ok", Status: 200, Attempts: 2},
+		{Level: "ERROR", Msg: "resilient: request failed", URL: srv.URL + "/missing", Status: 404, Attempts: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d records, got %+v", len(want), got)
+	}
+	for i := range want {
+		g := got[i]
+		g.Error = ""
+		if g != want[i] {
+			t.Fatalf("record %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+	if got[1].Error == "" || got[3].Error == "" {
+		t.Fatalf("expected errors on retry and failure records, got %+v", got)
+	}
+}
+
+// reentrantHandler records log lines and calls back into the client from
+// Handle, as a handler reporting client state might.
+type reentrantHandler struct {
+	c     *atomic.Pointer[Client]
+	mu    *sync.Mutex
+	lines *[]string
+}
+
+func (h reentrantHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h reentrantHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h reentrantHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h reentrantHandler) Handle(_ context.Context, r slog.Record) error {
+	if c := h.c.Load(); c != nil {
+		c.SetRateLimit(100, 1)
+	}
+	line := r.Message
+	r.Attrs(func(a slog.Attr) bool {
+		line += " " + a.String()
+		return true
+	})
+	h.mu.Lock()
+	*h.lines = append(*h.lines, line)
+	h.mu.Unlock()
+	return nil
+}
+
+func TestLoggerReentrantAndRedacted(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	var client atomic.Pointer[Client]
+	var mu sync.Mutex
+	var lines []string
+	c := New(WithRateLimit(100, 1), WithRetry(1, time.Millisecond),
+		WithLogger(slog.New(reentrantHandler{c: &client, mu: &mu, lines: &lines})))
+	defer c.Close()
+	client.Store(c)
+
+	u := strings.Replace(srv.URL, "http://", "http://user:s3cr3t@", 1) + "/?api_key=s3cr3t&page=1"
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := c.Get(context.Background(), u)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a handler calling back into the client deadlocked")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	out := strings.Join(lines, "\n")
+	if !strings.Contains(out, "rate limit reduced") || !strings.Contains(out, "api_key=xxxxx&page=1") {
+		t.Fatalf("expected redacted rate and request records, got:\n%s", out)
+	}
+	if strings.Contains(out, "s3cr3t") {
+		t.Fatalf("log leaked a secret:\n%s", out)
+	}
+}
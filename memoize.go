@@ -0,0 +1,93 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// memoCache holds successful GET results for a fixed TTL, whatever their
+// cache headers say.
+type memoCache struct {
+	ttl   time.Duration
+	clock Clock
+
+	mu      sync.Mutex
+	entries map[string]memoEntry
+	sweepAt int // entry count that triggers the next sweep of expired entries
+}
+
+type memoEntry struct {
+	res     *Result
+	expires time.Time
+}
+
+// newMemoCache returns nil unless WithMemoize is configured.
+func newMemoCache(cfg *config) *memoCache {
+	if cfg.memoizeTTL <= 0 {
+		return nil
+	}
+	return &memoCache{ttl: cfg.memoizeTTL, clock: cfg.clock, entries: make(map[string]memoEntry), sweepAt: 64}
+}
+
+func (m *memoCache) do(ctx context.Context, req *http.Request, fetch fetchFunc) (*Result, error) {
+	if req.Method != http.MethodGet {
+		return fetch(ctx, req)
+	}
+	key := req.URL.String()
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	m.mu.Unlock()
+	if ok && m.clock.Now().Before(e.expires) {
+		return memoized(e.res), nil
+	}
+
+	res, err := fetch(ctx, req)
+	if ok && errors.Is(err, ErrOffline) {
+		return memoized(e.res), nil // expired, but better than nothing offline
+	}
+	if err != nil || res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res, err
+	}
+	now := m.clock.Now()
+	m.mu.Lock()
+	m.entries[key] = memoEntry{res: memoized(res), expires: now.Add(m.ttl)}
+	if len(m.entries) >= m.sweepAt {
+		for k, e := range m.entries {
+			if !now.Before(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		m.sweepAt = max(64, 2*len(m.entries))
+	}
+	m.mu.Unlock()
+	return res, nil
+}
+
+// forget drops every memoized result.
+func (m *memoCache) forget() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.entries)
+}
+
+// memoized returns a copy of res marked as cached.
+func memoized(res *Result) *Result {
+	return &Result{
+		Body:       bytes.Clone(res.Body),
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Cached:     true,
+	}
+}
+
+// ForgetMemoized drops the results memoized by WithMemoize, so the next
+// calls reach the upstream.
+func (c *Client) ForgetMemoized() {
+	if c.memo != nil {
+		c.memo.forget()
+	}
+}
@@ -0,0 +1,67 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoize(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithMemoize(50*time.Millisecond), WithRetry(0, 0))
+	defer c.Close()
+	ctx := context.Background()
+
+	get := func(path string) *Result {
+		t.Helper()
+		req, _ := c.NewRequest(ctx, http.MethodGet, path, nil)
+		res, _ := c.DoResult(ctx, req)
+		return res
+	}
+
+	if res := get("/a"); res.Cached || string(res.Body) != "/a" {
+		t.Fatalf("first call: cached=%v body=%q", res.Cached, res.Body)
+	}
+	if res := get("/a"); !res.Cached || string(res.Body) != "/a" {
+		t.Fatalf("second call: cached=%v body=%q", res.Cached, res.Body)
+	}
+	get("/b")
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected 2 upstream calls, got %d", n)
+	}
+
+	get("/missing")
+	get("/missing")
+	if n := calls.Load(); n != 4 {
+		t.Fatalf("failures must not be memoized, got %d calls", n)
+	}
+
+	req, _ := c.NewRequest(ctx, http.MethodPost, "/a", nil)
+	c.DoResult(ctx, req)
+	if n := calls.Load(); n != 5 {
+		t.Fatalf("POST must not be memoized, got %d calls", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if res := get("/a"); res.Cached {
+		t.Fatal("expected expired entry to be fetched again")
+	}
+
+	c.ForgetMemoized()
+	if res := get("/a"); res.Cached {
+		t.Fatal("expected ForgetMemoized to drop the entry")
+	}
+}
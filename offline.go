@@ -0,0 +1,18 @@
+package resilient
+
+// SetOffline switches the client in or out of offline mode, for agents that
+// learn about connectivity changes from the platform. While offline, calls
+// fail at once with ErrOffline instead of spending their retries, unless
+// WithCache or WithMemoize holds a response for them, which is then served
+// however stale; calls waiting to retry give up at their next attempt. The
+// outbox keeps accepting messages and delivers them once back online.
+func (c *Client) SetOffline(offline bool) {
+	if c.offline.Swap(offline) && !offline && c.outbox != nil {
+		go c.deliverOutbox()
+	}
+}
+
+// Offline reports whether the client is in offline mode.
+func (c *Client) Offline() bool {
+	return c.offline.Load()
+}
@@ -0,0 +1,84 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOffline(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Cache-Control", "max-age=0")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithCache(NewMemoryCache(10)))
+	defer c.Close()
+	ctx := context.Background()
+
+	if _, _, err := c.Get(ctx, "/cached"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetOffline(true)
+	if !c.Offline() {
+		t.Fatal("expected Offline to report true")
+	}
+	start := time.Now()
+	if _, _, err := c.Get(ctx, "/other"); !errors.Is(err, ErrOffline) {
+		t.Fatalf("expected ErrOffline, got %v", err)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Fatalf("offline call took %v, expected to fail fast", d)
+	}
+	body, _, err := c.Get(ctx, "/cached")
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("expected stale cache entry offline, got %q %v", body, err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected no upstream calls while offline, got %d", n)
+	}
+	if s := c.Stats(); s.TotalRetries != 0 {
+		t.Fatalf("expected no retries while offline, got %d", s.TotalRetries)
+	}
+
+	c.SetOffline(false)
+	if _, _, err := c.Get(ctx, "/other"); err != nil {
+		t.Fatalf("expected success back online, got %v", err)
+	}
+}
+
+func TestOfflineOutbox(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	store, err := NewFileOutbox(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(WithBaseURL(srv.URL), WithOutbox(store, time.Hour))
+	defer c.Close()
+	c.SetOffline(true)
+
+	req, _ := c.NewRequest(context.Background(), http.MethodPost, "/events", nil)
+	if err := c.Enqueue(req); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("expected no delivery while offline, got %d", n)
+	}
+
+	c.SetOffline(false)
+	waitUntil(t, "delivery", func() bool { return calls.Load() == 1 })
+}
@@ -1,7 +1,16 @@
 package resilient
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"maps"
 	"net/http"
+	"net/http/cookiejar"
+	"slices"
 	"time"
 )
 
@@ -10,11 +19,39 @@ type Option func(*config)
 
 type config struct {
 	baseURL          string
+	pathPrefix       string // set by Client.Group
+	fallbackURLs     []string
+	service          string
+	resolver         Resolver
+	splitCanary      string
+	splitPercent     float64
+	failoverAfter    int
+	failoverCooldown time.Duration
 	rps              float64
 	burst            int
+	limiter          RateLimiter
+	perHostRPS       float64
+	perHostBurst     int
+	endpointLimits   []endpointLimit
+	quotaPacing      bool
+	rateLimitHook    func(req *http.Request, info RateLimitInfo)
+	maxConcurrent    int
+	adaptiveInFlight bool
+	priorityQueue    bool
+	maxQueueWait     time.Duration
+	maxQueueDepth    int
 	maxRetries       int
 	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	maxElapsedTime   time.Duration
+	maxRetryAfter    time.Duration
+	jitterMode       JitterMode
+	jitterFraction   float64
 	adaptiveCooldown time.Duration
+	adaptiveTriggers map[int]bool
+	adaptiveDisabled bool
+	additiveIncrease float64
+	latencyTolerance float64
 	maxResponseSize  int64
 	timeout          time.Duration
 	retryableStatus  map[int]bool
@@ -23,11 +60,88 @@ type config struct {
 	onError       func(statusCode int, req *http.Request)
 	onSuccess     func(req *http.Request, resp *http.Response)
 	onRateLimited func(req *http.Request)
+	onComplete    []func(req *http.Request, res *Result, err error)
+	fallback      FallbackFunc
 
 	requestHook  func(req *http.Request)
 	responseHook func(resp *http.Response)
 
-	retryPolicy RetryPolicy
+	retryPolicy    RetryPolicy
+	idempotentOnly bool
+
+	hedgeDelay time.Duration
+	maxHedges  int
+
+	singleflight bool
+	cacheStore   CacheStore
+
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+
+	defaultHeaders http.Header
+	userAgent      string
+	progress       func(transferred, total int64)
+	decoders       map[string]Decoder
+
+	graphQLRetry map[string]bool
+
+	authRefresh func(ctx context.Context) (token string, err error)
+	bearerFunc  func() string
+	apiKeys     apiKeyConfig
+	signer      Signer
+	digestUser  string
+	digestPass  string
+
+	pins      []string
+	tlsConfig *tls.Config
+	certFile  string
+	keyFile   string
+	rootCAs   *x509.CertPool
+	minTLS    uint16
+
+	proxyURLs     []string
+	proxyStrategy ProxyStrategy
+	dnsTTL        time.Duration
+	dnsRefresh    bool
+	protocols     *http.Protocols
+	middleware    []func(http.RoundTripper, TransportConfig) http.RoundTripper
+
+	jar http.CookieJar
+
+	errorDecoder      func(resp *http.Response, body []byte) error
+	responseValidator func(status int, body []byte) error
+	successStatus     map[int]bool
+
+	logger    *slog.Logger
+	logLevels LogLevels
+
+	debugDump     io.Writer
+	redactHeaders []string
+	curlOnError   bool
+
+	clock Clock
+
+	simMinDelay time.Duration
+	simMaxDelay time.Duration
+	bandwidth   int
+
+	latencyBuckets []time.Duration
+
+	sloTarget   float64
+	sloWindow   time.Duration
+	sloOnBreach func(SLOReport)
+
+	healthPath     string
+	healthInterval time.Duration
+	healthStatuses []int
+
+	outboxStore    OutboxStore
+	outboxInterval time.Duration
+
+	memoizeTTL time.Duration
+
+	hostPolicy   *HostPolicy
+	hostPolicies map[string]HostPolicy
 }
 
 // RetryPolicy decides whether a request should be retried.
@@ -35,14 +149,56 @@ type config struct {
 // network errors), and the error. Return true to retry, false to stop.
 type RetryPolicy func(attempt int, resp *http.Response, err error) bool
 
+// JitterMode selects how backoff durations are randomized.
+type JitterMode int
+
+const (
+	// JitterProportional adds a random offset of up to ±fraction of the
+	// backoff. This is the default, with a fraction of 0.25.
+	JitterProportional JitterMode = iota
+	// JitterNone uses the exact exponential backoff.
+	JitterNone
+	// JitterFull picks a random duration between zero and the backoff.
+	JitterFull
+	// JitterEqual keeps half of the backoff and randomizes the other half.
+	JitterEqual
+)
+
+// clone returns a copy of c that options can modify without affecting c.
+func (c *config) clone() *config {
+	cp := *c
+	cp.defaultHeaders = c.defaultHeaders.Clone()
+	cp.retryableStatus = maps.Clone(c.retryableStatus)
+	cp.adaptiveTriggers = maps.Clone(c.adaptiveTriggers)
+	cp.successStatus = maps.Clone(c.successStatus)
+	cp.decoders = maps.Clone(c.decoders)
+	cp.graphQLRetry = maps.Clone(c.graphQLRetry)
+	cp.hostPolicies = maps.Clone(c.hostPolicies)
+	cp.endpointLimits = slices.Clip(c.endpointLimits)
+	cp.pins = slices.Clip(c.pins)
+	cp.middleware = slices.Clip(c.middleware)
+	cp.redactHeaders = slices.Clip(c.redactHeaders)
+	cp.onComplete = slices.Clip(c.onComplete)
+	return &cp
+}
+
 func defaultConfig() *config {
 	return &config{
 		rps:              0, // no rate limiting by default
 		burst:            1,
+		perHostBurst:     1,
 		maxRetries:       3,
 		initialBackoff:   2 * time.Second,
+		jitterMode:       JitterProportional,
+		jitterFraction:   0.25,
 		adaptiveCooldown: 5 * time.Minute,
+		logLevels:        DefaultLogLevels,
+		clock:            systemClock{},
+		latencyBuckets:   DefaultLatencyBuckets,
+		failoverAfter:    3,
+		failoverCooldown: 30 * time.Second,
 		maxResponseSize:  10 * 1024 * 1024, // 10 MB
+		userAgent:        "resilient-go/" + Version,
 		timeout:          30 * time.Second,
 		retryableStatus: map[int]bool{
 			http.StatusTooManyRequests:     true,
@@ -56,6 +212,55 @@ func WithBaseURL(url string) Option {
 	return func(c *config) { c.baseURL = url }
 }
 
+// WithBaseURLs sets a primary base URL and fallbacks to fail over to.
+// Requests built from the base URL are sent to the first healthy endpoint in
+// order; an endpoint is taken out of rotation after a network error or
+// repeated 5xx responses and retried once its cooldown expires, so traffic
+// returns to the primary automatically. See WithFailover for the thresholds.
+func WithBaseURLs(primary string, fallbacks ...string) Option {
+	return func(c *config) {
+		c.baseURL = primary
+		c.fallbackURLs = fallbacks
+	}
+}
+
+// WithService sends the convenience methods' requests to the instances of
+// service found by resolver, instead of a fixed base URL. Instances are
+// re-resolved every 30 seconds, keeping the previous ones if the resolver
+// fails; each attempt goes to the next healthy instance in turn, and an
+// instance is taken out of rotation like a failover endpoint (see
+// WithFailover). WithService replaces WithBaseURL and WithBaseURLs.
+func WithService(service string, resolver Resolver) Option {
+	return func(c *config) {
+		c.service, c.resolver = service, resolver
+		c.baseURL, c.fallbackURLs = serviceBase(service), nil
+	}
+}
+
+// WithTrafficSplit sets primary as the base URL and sends percent (0 to
+// 100) of the calls made against it to canary instead, chosen at random
+// per call; retries stay on the same side. Each side's requests, errors and
+// latency are reported by Client.TrafficSplit, for comparing API versions
+// or regions during a migration.
+func WithTrafficSplit(primary, canary string, percent float64) Option {
+	return func(c *config) {
+		c.baseURL = primary
+		c.splitCanary, c.splitPercent = canary, percent
+	}
+}
+
+// WithFailover tunes endpoint health tracking for WithBaseURLs: an endpoint
+// is marked down after threshold consecutive 5xx responses (or a single
+// network error) and stays down for cooldown. Defaults are 3 and 30s.
+func WithFailover(threshold int, cooldown time.Duration) Option {
+	return func(c *config) {
+		if threshold > 0 {
+			c.failoverAfter = threshold
+		}
+		c.failoverCooldown = cooldown
+	}
+}
+
 // WithRateLimit sets the token bucket rate limit in requests per second and burst size.
 func WithRateLimit(rps float64, burst int) Option {
 	return func(c *config) {
@@ -66,6 +271,138 @@ func WithRateLimit(rps float64, burst int) Option {
 	}
 }
 
+// WithPacing spaces requests evenly, at most one per interval, instead of
+// allowing token-bucket bursts. It behaves like a leaky bucket and is
+// equivalent to WithRateLimit(1/interval, 1); whichever of the two is
+// applied last wins.
+func WithPacing(interval time.Duration) Option {
+	return func(c *config) {
+		if interval > 0 {
+			c.rps = float64(time.Second) / float64(interval)
+			c.burst = 1
+		}
+	}
+}
+
+// WithLimiter replaces the built-in token bucket with a custom RateLimiter,
+// e.g. a sliding-window, GCRA or externally coordinated limiter. It takes
+// precedence over WithRateLimit; adaptive reduction and SetRateLimit adjust
+// it through SetLimit.
+func WithLimiter(l RateLimiter) Option {
+	return func(c *config) { c.limiter = l }
+}
+
+// WithPerHostRateLimit maintains a separate token bucket for every request
+// host, so traffic to one API does not consume another's budget. It may be
+// combined with WithRateLimit, in which case both limits apply. Adaptive
+// reduction halves only the limiter of the host that was rate limited.
+// The bucket of a host left idle for ten minutes is dropped once it has
+// refilled and any reduction has been restored.
+func WithPerHostRateLimit(rps float64, burst int) Option {
+	return func(c *config) {
+		c.perHostRPS = rps
+		if burst > 0 {
+			c.perHostBurst = burst
+		}
+	}
+}
+
+// WithPerHostPolicy gives every request host its own circuit breaker and
+// bulkhead configured by p, so that a failing or slow host does not trip
+// or starve calls to the others when the client talks to many hosts. It
+// may be combined with WithMaxConcurrent, in which case both limits apply.
+// WithHostPolicy overrides p for individual hosts.
+func WithPerHostPolicy(p HostPolicy) Option {
+	return func(c *config) { c.hostPolicy = &p }
+}
+
+// WithHostPolicy sets the circuit breaker and bulkhead of one host, given
+// as in URL.Host (with the port, if any), replacing the WithPerHostPolicy
+// defaults for it.
+func WithHostPolicy(host string, p HostPolicy) Option {
+	return func(c *config) {
+		if c.hostPolicies == nil {
+			c.hostPolicies = make(map[string]HostPolicy)
+		}
+		c.hostPolicies[host] = p
+	}
+}
+
+// WithEndpointRateLimit gives requests whose URL path matches pattern their
+// own token bucket, e.g. WithEndpointRateLimit("/search/*", 0.5, 1). A
+// trailing "/*" matches everything below the prefix; other patterns use
+// path.Match syntax. Rules are tried in the order given and the first match
+// applies, in addition to WithRateLimit and WithPerHostRateLimit.
+func WithEndpointRateLimit(pattern string, rps float64, burst int) Option {
+	return func(c *config) {
+		if burst < 1 {
+			burst = 1
+		}
+		c.endpointLimits = append(c.endpointLimits, endpointLimit{pattern: pattern, rps: rps, burst: burst})
+	}
+}
+
+// endpointLimit is a rule registered with WithEndpointRateLimit.
+type endpointLimit struct {
+	pattern string
+	rps     float64
+	burst   int
+}
+
+// WithQuotaPacing paces requests per host from the X-RateLimit-Remaining and
+// X-RateLimit-Reset response headers, or the IETF RateLimit fields,
+// spreading the remaining quota evenly until the reset time. When the quota
+// is exhausted, requests wait for the reset instead of provoking a 429. It
+// applies on top of any other limits. The pacer of a host left idle for ten
+// minutes is dropped once its window has reset.
+func WithQuotaPacing() Option {
+	return func(c *config) { c.quotaPacing = true }
+}
+
+// WithRateLimitHook calls fn with the parsed IETF RateLimit and
+// RateLimit-Policy headers of every response that carries them.
+func WithRateLimitHook(fn func(req *http.Request, info RateLimitInfo)) Option {
+	return func(c *config) { c.rateLimitHook = fn }
+}
+
+// WithAdaptiveConcurrency limits requests in flight with a limit that
+// adapts to the upstream: it grows while latency holds steady and shrinks
+// when latency rises or attempts fail with errors, 429 or 503. It starts at
+// 20 and is capped by WithMaxConcurrent if set (1000 otherwise). The current
+// limit is reported in Stats.ConcurrencyLimit.
+func WithAdaptiveConcurrency() Option {
+	return func(c *config) { c.adaptiveInFlight = true }
+}
+
+// WithPriorityQueue puts a priority queue in front of the rate limiter
+// configured with WithRateLimit. Whenever a token becomes available it goes
+// to the most urgent waiting request, as tagged with WithPriority; requests
+// of equal priority are served in arrival order.
+func WithPriorityQueue() Option {
+	return func(c *config) { c.priorityQueue = true }
+}
+
+// WithMaxQueueWait sheds load: a request that would wait longer than d for
+// a rate-limit token, or past its own context deadline, fails immediately
+// with ErrShedded instead of queueing.
+func WithMaxQueueWait(d time.Duration) Option {
+	return func(c *config) { c.maxQueueWait = d }
+}
+
+// WithMaxQueueDepth sheds load: when n requests are already waiting for a
+// rate-limit token, further requests fail immediately with ErrShedded.
+// Requests that would wait past their context deadline are shed as well.
+func WithMaxQueueDepth(n int) Option {
+	return func(c *config) { c.maxQueueDepth = n }
+}
+
+// WithMaxConcurrent limits the number of requests in flight at once,
+// independently of the rate limit. Attempts beyond the limit wait for a free
+// slot (respecting their context); backoff waits do not hold a slot.
+func WithMaxConcurrent(n int) Option {
+	return func(c *config) { c.maxConcurrent = n }
+}
+
 // WithRetry sets the maximum number of retries and initial backoff duration.
 // Backoff doubles on each attempt with jitter added.
 func WithRetry(maxRetries int, initialBackoff time.Duration) Option {
@@ -75,6 +412,102 @@ func WithRetry(maxRetries int, initialBackoff time.Duration) Option {
 	}
 }
 
+// WithJitter sets how backoff durations are randomized. The fraction is
+// only used by JitterProportional, where it bounds the offset relative to
+// the backoff (0.25 means ±25%).
+func WithJitter(mode JitterMode, fraction float64) Option {
+	return func(c *config) {
+		c.jitterMode = mode
+		c.jitterFraction = fraction
+	}
+}
+
+// WithMaxBackoff caps the wait between attempts. The cap is applied after
+// jitter, so no computed backoff exceeds d. A Retry-After hint may still
+// ask for longer; see WithMaxRetryAfter.
+func WithMaxBackoff(d time.Duration) Option {
+	return func(c *config) { c.maxBackoff = d }
+}
+
+// WithMaxElapsedTime bounds the whole call — every attempt, backoff wait and
+// rate-limit wait — by a wall-clock budget, independent of the retry count.
+// When the budget runs out the call fails with an error wrapping
+// ErrMaxElapsedTime. A retry is not started if its backoff alone would
+// overrun the budget.
+func WithMaxElapsedTime(d time.Duration) Option {
+	return func(c *config) { c.maxElapsedTime = d }
+}
+
+// WithMaxRetryAfter caps how long a Retry-After response header may delay
+// the next attempt. By default the server's hint is honoured in full
+// whenever it is longer than the computed backoff.
+func WithMaxRetryAfter(d time.Duration) Option {
+	return func(c *config) { c.maxRetryAfter = d }
+}
+
+// WithRetryIdempotentOnly stops network errors from being retried for
+// non-idempotent requests (POST, PATCH, ...), since the failed attempt may
+// already have had side effects upstream. Requests carrying an
+// Idempotency-Key or X-Idempotency-Key header are still retried, as are
+// retryable status codes, which the server answered without processing.
+func WithRetryIdempotentOnly() Option {
+	return func(c *config) { c.idempotentOnly = true }
+}
+
+// WithHedging sends up to maxHedges duplicate requests when an attempt has
+// not completed within delay, one more after each further delay. The first
+// response wins and the remaining requests are cancelled. Hedges only go out
+// when the rate limiter has a token available immediately, so they never
+// exceed the configured rate. Only hedge requests that are safe to repeat.
+func WithHedging(delay time.Duration, maxHedges int) Option {
+	return func(c *config) {
+		c.hedgeDelay = delay
+		c.maxHedges = maxHedges
+	}
+}
+
+// WithSingleflight collapses concurrent identical GET requests (same URL
+// and headers) into a single upstream call whose result is shared by all
+// callers. The shared call runs under the first caller's context; if that
+// context ends it, the other callers whose contexts are still live send the
+// request themselves.
+func WithSingleflight() Option {
+	return func(c *config) { c.singleflight = true }
+}
+
+// WithCache enables an RFC 9111 private response cache backed by store.
+// Fresh GET responses are served without touching the network or the rate
+// limiter, stale ones are revalidated with ETag/Last-Modified, and
+// successful unsafe requests invalidate the entry for their URL.
+// Use NewMemoryCache for an in-process LRU store.
+func WithCache(store CacheStore) Option {
+	return func(c *config) { c.cacheStore = store }
+}
+
+// WithMemoize keeps the result of each successful (2xx) GET for ttl and
+// answers repeated calls to the same URL from memory, ignoring cache
+// headers. It suits internal APIs that send no Cache-Control but change
+// rarely; see WithCache for HTTP caching. Results are keyed by URL only,
+// so it should not be used for responses that depend on per-request
+// headers. Memoized results have Result.Cached set.
+func WithMemoize(ttl time.Duration) Option {
+	return func(c *config) { c.memoizeTTL = ttl }
+}
+
+// WithStaleWhileRevalidate lets the cache serve a stale response for up to
+// d past its expiry while refreshing it in the background. A
+// stale-while-revalidate directive on the response takes precedence.
+func WithStaleWhileRevalidate(d time.Duration) Option {
+	return func(c *config) { c.staleWhileRevalidate = d }
+}
+
+// WithStaleIfError lets the cache serve a stale response for up to d past
+// its expiry when the upstream fails with a network error or 5xx. A
+// stale-if-error directive on the response takes precedence.
+func WithStaleIfError(d time.Duration) Option {
+	return func(c *config) { c.staleIfError = d }
+}
+
 // WithAdaptive sets the cooldown duration for adaptive rate reduction.
 // When a rate-limit response is received, the rate is halved and restored
 // after this duration.
@@ -82,12 +515,49 @@ func WithAdaptive(cooldown time.Duration) Option {
 	return func(c *config) { c.adaptiveCooldown = cooldown }
 }
 
+// WithAdaptiveTriggers limits adaptive rate reduction to the given status
+// codes, e.g. WithAdaptiveTriggers(429) to retry 503s without halving the
+// rate. By default every retryable status triggers a reduction.
+func WithAdaptiveTriggers(codes ...int) Option {
+	return func(c *config) {
+		c.adaptiveTriggers = make(map[int]bool, len(codes))
+		for _, code := range codes {
+			c.adaptiveTriggers[code] = true
+		}
+	}
+}
+
+// WithAdaptiveDisabled turns off adaptive rate reduction on retryable
+// statuses; the configured rate is never lowered automatically.
+func WithAdaptiveDisabled() Option {
+	return func(c *config) { c.adaptiveDisabled = true }
+}
+
+// WithAdditiveIncrease switches adaptive rate reduction to AIMD: a
+// rate-limit response halves the current rate, and every successful
+// response then raises it by fraction of the configured rate (e.g. 0.05 for
+// 5%) until it is fully restored. The cooldown from WithAdaptive is not used
+// in this mode.
+func WithAdditiveIncrease(fraction float64) Option {
+	return func(c *config) { c.additiveIncrease = fraction }
+}
+
+// WithLatencyThrottling reduces the rate when the upstream slows down, not
+// only when it rejects requests. The client tracks a smoothed response time
+// against the best recently observed one and, once it exceeds tolerance
+// times that baseline (e.g. 2.0), applies the same reduction as a 429, at
+// most once per second. It needs a rate limit to act on.
+func WithLatencyThrottling(tolerance float64) Option {
+	return func(c *config) { c.latencyTolerance = tolerance }
+}
+
 // WithTimeout sets the HTTP client timeout.
 func WithTimeout(d time.Duration) Option {
 	return func(c *config) { c.timeout = d }
 }
 
-// WithMaxResponseSize sets the maximum response body size in bytes.
+// WithMaxResponseSize sets the maximum response body size in bytes. Longer
+// bodies are truncated and the call fails with ErrResponseTooLarge.
 func WithMaxResponseSize(n int64) Option {
 	return func(c *config) { c.maxResponseSize = n }
 }
@@ -104,11 +574,276 @@ func WithRetryableStatus(codes ...int) Option {
 }
 
 // WithHTTPClient sets a custom underlying *http.Client.
-// The timeout option is ignored when a custom client is provided.
+// The timeout option is ignored when a custom client is provided. TLS
+// options need its Transport to be nil or an *http.Transport; see
+// ErrUnsupportedTransport.
 func WithHTTPClient(hc *http.Client) Option {
 	return func(c *config) { c.httpClient = hc }
 }
 
+// WithTLSConfig sets the TLS configuration of the client's transport. The
+// config is cloned; the other TLS options are applied on top of it. Like
+// them, it keeps the default transport's pooling and timeouts instead of
+// requiring a whole custom http.Client.
+func WithTLSConfig(tc *tls.Config) Option {
+	return func(c *config) { c.tlsConfig = tc }
+}
+
+// WithClientCertificate presents the PEM key pair in certFile and keyFile
+// to servers that ask for a client certificate (mutual TLS). The files are
+// read on each handshake, so a renewed certificate is used by new
+// connections; a load failure fails the handshake.
+func WithClientCertificate(certFile, keyFile string) Option {
+	return func(c *config) { c.certFile, c.keyFile = certFile, keyFile }
+}
+
+// WithRootCAs verifies server certificates against pool instead of the
+// system roots, for services behind a private PKI.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *config) { c.rootCAs = pool }
+}
+
+// WithMinTLSVersion sets the minimum TLS version, e.g. tls.VersionTLS13.
+func WithMinTLSVersion(v uint16) Option {
+	return func(c *config) { c.minTLS = v }
+}
+
+// WithPinnedCertificates rejects TLS connections unless a certificate in
+// the server's chain has one of the given SPKI SHA-256 pins, written as
+// "sha256/<base64>" (see SPKIPin) or bare base64. Normal certificate
+// verification still applies. A mismatch fails the handshake with a
+// *PinError. It applies to the default transport, or to a custom
+// client's *http.Transport, which is copied rather than modified. With any
+// other custom transport the pins can't be enforced, so every call fails
+// with ErrUnsupportedTransport; the same goes for the other TLS options.
+func WithPinnedCertificates(sha256Pins ...string) Option {
+	return func(c *config) { c.pins = append(c.pins, sha256Pins...) }
+}
+
+// WithProxyPool sends requests through the given proxies (http, https or
+// socks5 URLs), rotating per request or pinning each host to one proxy.
+// A proxy that fails 3 times in a row is benched for 30 seconds, then
+// rejoins the pool; see Client.ProxyStats. Invalid URLs are ignored.
+func WithProxyPool(urls []string, strategy ProxyStrategy) Option {
+	return func(c *config) { c.proxyURLs, c.proxyStrategy = urls, strategy }
+}
+
+// WithDNSCache caches host lookups for ttl, cutting resolver latency and
+// load under high request volume. Hits and misses are reported in Stats.
+// It applies to the default transport, or to a copy of a custom client's
+// *http.Transport.
+func WithDNSCache(ttl time.Duration) Option {
+	return func(c *config) { c.dnsTTL = ttl }
+}
+
+// WithDNSBackgroundRefresh makes WithDNSCache keep serving an expired
+// entry while it is refreshed in the background, so requests never wait on
+// the resolver for a host seen before.
+func WithDNSBackgroundRefresh() Option {
+	return func(c *config) { c.dnsRefresh = true }
+}
+
+// WithHTTP2 enables or disables HTTP/2 over TLS. HTTP/2 is negotiated by
+// default; disabling it forces HTTP/1.1, e.g. for servers with broken h2
+// support.
+func WithHTTP2(enabled bool) Option {
+	return func(c *config) {
+		p := new(http.Protocols)
+		p.SetHTTP1(true)
+		p.SetHTTP2(enabled)
+		c.protocols = p
+	}
+}
+
+// WithH2C speaks cleartext HTTP/2 with prior knowledge to http:// URLs,
+// as internal gRPC-gateway and Envoy-fronted services expect. https://
+// URLs keep using HTTP/2 over TLS; HTTP/1.1 is not offered.
+func WithH2C() Option {
+	return func(c *config) {
+		p := new(http.Protocols)
+		p.SetHTTP2(true)
+		p.SetUnencryptedHTTP2(true)
+		c.protocols = p
+	}
+}
+
+// WithTransportMiddleware wraps the client's transport with fn, after the
+// other transport options have been applied. Middlewares run in the order
+// given, the last one outermost. If the resulting transport implements
+// io.Closer, Client.Close closes it.
+func WithTransportMiddleware(fn func(next http.RoundTripper) http.RoundTripper) Option {
+	return WithTransportMiddlewareConfig(func(next http.RoundTripper, _ TransportConfig) http.RoundTripper {
+		return fn(next)
+	})
+}
+
+// WithTransportMiddlewareConfig is WithTransportMiddleware for middleware
+// that opens connections of its own instead of calling next, such as an
+// HTTP/3 transport: fn also receives the client's TLS and proxy settings,
+// so that it can apply them too or refuse to run without them.
+func WithTransportMiddlewareConfig(fn func(next http.RoundTripper, tc TransportConfig) http.RoundTripper) Option {
+	return func(c *config) { c.middleware = append(c.middleware, fn) }
+}
+
+// WithCookieJar stores cookies set by responses in jar and sends them with
+// later requests, including retries of the same call. It replaces the Jar
+// of a client passed to WithHTTPClient, which is copied, not modified.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(c *config) { c.jar = jar }
+}
+
+// WithSessionCookies is WithCookieJar with a fresh in-memory jar, for APIs
+// that keep a session in cookies (CSRF flows, legacy portals).
+func WithSessionCookies() Option {
+	return func(c *config) {
+		jar, _ := cookiejar.New(nil) // never fails without options
+		c.jar = jar
+	}
+}
+
+// WithErrorDecoder sets fn to turn error responses (status 400 and above)
+// into domain errors, e.g. by decoding an API's error envelope. When fn
+// returns nil, the generic "HTTP <status>" error is used. The response body
+// has already been read into body and closed.
+func WithErrorDecoder(fn func(resp *http.Response, body []byte) error) Option {
+	return func(c *config) { c.errorDecoder = fn }
+}
+
+// WithResponseValidator checks successful responses with fn, for upstreams
+// that answer 200 with an error payload or a truncated body. A non-nil error
+// fails the attempt, which is retried like a network error (subject to
+// WithRetry, WithRetryPolicy and WithRetryIdempotentOnly); if no retry is left,
+// the call returns the validation error. Streaming calls (Download,
+// DoBuffer, DoJSONStream) and 304 revalidations are not validated.
+func WithResponseValidator(fn func(status int, body []byte) error) Option {
+	return func(c *config) { c.responseValidator = fn }
+}
+
+// WithSuccessStatuses makes the given status codes (typically 404 for
+// existence checks) return their body and status with a nil error instead
+// of failing the call. They are never retried and don't count as errors in
+// Stats. Repeated calls add to the set.
+func WithSuccessStatuses(codes ...int) Option {
+	return func(c *config) {
+		if c.successStatus == nil {
+			c.successStatus = make(map[int]bool, len(codes))
+		}
+		for _, code := range codes {
+			c.successStatus[code] = true
+		}
+	}
+}
+
+// WithLogger logs completed calls, retries, adaptive rate changes and
+// failures to l as structured records (method, url, status, attempt, wait,
+// ...), at the levels set with WithLogLevels. URLs are logged with their
+// password and secret query parameters redacted, as in WithDebugDump. The
+// handler never runs under the client's locks, so it may call back into
+// the client.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) { c.logger = l }
+}
+
+// WithLogLevels overrides DefaultLogLevels for WithLogger.
+func WithLogLevels(levels LogLevels) Option {
+	return func(c *config) { c.logLevels = levels }
+}
+
+// WithDebugDump writes every attempt's request and response to w in wire
+// format, for troubleshooting. Authorization, Proxy-Authorization, Cookie,
+// Set-Cookie, X-Api-Key and the WithAPIKeyPool header are redacted, along
+// with any added by WithRedactedHeaders. Response bodies are cut at 64 KiB;
+// request bodies are included when they can be re-created with GetBody.
+func WithDebugDump(w io.Writer) Option {
+	return func(c *config) { c.debugDump = w }
+}
+
+// WithRedactedHeaders adds headers whose values are hidden in debug dumps
+// and curl commands, e.g. a custom token or signature header.
+func WithRedactedHeaders(names ...string) Option {
+	return func(c *config) { c.redactHeaders = append(c.redactHeaders, names...) }
+}
+
+// WithCurlOnError wraps the errors of failed calls in a *CurlError holding
+// a curl command that reproduces the last attempt, with secrets redacted as
+// in WithDebugDump:
+//
+//	var ce *resilient.CurlError
+//	if errors.As(err, &ce) {
+//		log.Printf("reproduce with: %s", ce.Command)
+//	}
+func WithCurlOnError() Option {
+	return func(c *config) { c.curlOnError = true }
+}
+
+// WithClock replaces the system clock used for backoff waits, adaptive-rate
+// cooldowns, Retry-After dates, elapsed-time budgets, durations and event
+// timestamps, for deterministic tests; see Clock.
+func WithClock(clk Clock) Option {
+	return func(c *config) { c.clock = clk }
+}
+
+// WithSimulatedLatency delays every attempt by a random duration between
+// min and max before it is sent, to exercise timeouts and deadline
+// propagation against a local server. The delay honors the request context.
+// It is meant for tests and local development.
+func WithSimulatedLatency(min, max time.Duration) Option {
+	if max < min {
+		max = min
+	}
+	return func(c *config) { c.simMinDelay, c.simMaxDelay = min, max }
+}
+
+// WithBandwidthLimit throttles request and response bodies to
+// bytesPerSecond in each direction, shared by all requests, as on a slow
+// link. It is meant for tests and local development.
+func WithBandwidthLimit(bytesPerSecond int) Option {
+	return func(c *config) { c.bandwidth = bytesPerSecond }
+}
+
+// WithLatencyBuckets sets the upper bounds of the response-time histogram
+// reported by Client.Latency. Finer buckets around the expected latencies
+// give more accurate percentiles.
+func WithLatencyBuckets(bounds ...time.Duration) Option {
+	return func(c *config) { c.latencyBuckets = bounds }
+}
+
+// WithSLO tracks the success rate of calls over a moving window and calls
+// onBreach when it drops below target (for example 0.99), once per breach:
+// the callback is re-armed when the rate recovers. A call fails when Do
+// returns an error; calls canceled by the caller are not counted, and no
+// breach is reported until the window holds 10 calls. onBreach runs on the
+// goroutine that completed the call and should not block. The current rate
+// is available from Client.SLO.
+func WithSLO(target float64, window time.Duration, onBreach func(SLOReport)) Option {
+	return func(c *config) {
+		c.sloTarget, c.sloWindow, c.sloOnBreach = target, window, onBreach
+	}
+}
+
+// WithHealthCheck sets the path probed by Client.Ping and, with a positive
+// interval, starts a background prober that pings every interval until
+// Close and exposes the outcome as Client.Healthy. A probe succeeds on a 2xx
+// response, or on one of statuses when given.
+func WithHealthCheck(path string, interval time.Duration, statuses ...int) Option {
+	return func(c *config) {
+		c.healthPath, c.healthInterval, c.healthStatuses = path, interval, statuses
+	}
+}
+
+// WithOutbox enables Client.Enqueue, persisting queued requests in store
+// and delivering them from a background worker that checks for due
+// messages every interval (one second if not positive) until Close.
+// Messages left by a previous process are picked up on start.
+func WithOutbox(store OutboxStore, interval time.Duration) Option {
+	return func(c *config) {
+		if interval <= 0 {
+			interval = time.Second
+		}
+		c.outboxStore, c.outboxInterval = store, interval
+	}
+}
+
 // WithOnError sets a callback invoked on non-retryable error responses.
 func WithOnError(fn func(statusCode int, req *http.Request)) Option {
 	return func(c *config) { c.onError = fn }
@@ -124,12 +859,159 @@ func WithOnRateLimited(fn func(req *http.Request)) Option {
 	return func(c *config) { c.onRateLimited = fn }
 }
 
+// WithFallback sets a function that supplies a degraded response, such as
+// cached data or a default value, when the upstream is unrecoverable: the
+// call failed with ErrMaxRetriesExceeded or ErrMaxElapsedTime, or with a
+// transport error such as a refused connection. It is not called for
+// errors the upstream answered with, such as a 404, for calls rejected
+// locally (ErrClientClosed, ErrOffline, ErrCircuitOpen), or when the
+// caller's context is done.
+func WithFallback(fn FallbackFunc) Option {
+	return func(c *config) { c.fallback = fn }
+}
+
+// WithOnComplete adds a callback invoked once per call, after all retries,
+// the cache, memoization and fallback, with the Result (never nil) and
+// error the caller receives: cache and memo hits (Result.Cached),
+// singleflight followers (Result.Shared) and calls rescued by WithFallback
+// (Result.Fallback, no error) are reported too. Unlike the other callbacks
+// it may be given multiple times; callbacks run in the order they were
+// added. It is the extension point used by metrics integrations.
+func WithOnComplete(fn func(req *http.Request, res *Result, err error)) Option {
+	return func(c *config) { c.onComplete = append(c.onComplete, fn) }
+}
+
+// WithDefaultHeaders adds headers to every outgoing request, e.g. an
+// Authorization token or Accept header. Headers already set on a request
+// take precedence.
+func WithDefaultHeaders(headers map[string]string) Option {
+	return func(c *config) {
+		for k, v := range headers {
+			WithHeader(k, v)(c)
+		}
+	}
+}
+
+// WithHeader adds a single default header; see WithDefaultHeaders.
+func WithHeader(key, value string) Option {
+	return func(c *config) {
+		if c.defaultHeaders == nil {
+			c.defaultHeaders = http.Header{}
+		}
+		c.defaultHeaders.Set(key, value)
+	}
+}
+
+// WithUserAgent sets the User-Agent sent with every request, unless the
+// request sets its own. The default is "resilient-go/<Version>"; an empty
+// string falls back to net/http's default.
+func WithUserAgent(ua string) Option {
+	return func(c *config) { c.userAgent = ua }
+}
+
+// WithProgress reports transfer progress of request bodies, downloads made
+// with Download, DownloadFile and DownloadResumable, and chunked uploads.
+// total is -1 when unknown. A retried request body starts again from zero;
+// a resumed download or upload continues from the bytes already transferred.
+// fn is called synchronously from the transfer and should return quickly.
+func WithProgress(fn func(transferred, total int64)) Option {
+	return func(c *config) { c.progress = fn }
+}
+
+// WithDecompression advertises the given content codings in
+// Accept-Encoding and transparently decodes matching responses. Without
+// arguments it enables gzip. The response size limit applies to the decoded
+// body, guarding against decompression bombs.
+func WithDecompression(decoders ...Decoder) Option {
+	return func(c *config) {
+		if len(decoders) == 0 {
+			decoders = []Decoder{Gzip}
+		}
+		if c.decoders == nil {
+			c.decoders = make(map[string]Decoder)
+		}
+		for _, d := range decoders {
+			c.decoders[d.Encoding()] = d
+		}
+	}
+}
+
+// WithGraphQLRetryCodes makes GraphQL retry responses whose errors carry
+// one of the given extensions.code values, such as "RATE_LIMITED" or
+// "THROTTLED", which servers report with a 200 status. Retries follow the
+// client's retry count and backoff.
+func WithGraphQLRetryCodes(codes ...string) Option {
+	return func(c *config) {
+		if c.graphQLRetry == nil {
+			c.graphQLRetry = make(map[string]bool)
+		}
+		for _, code := range codes {
+			c.graphQLRetry[code] = true
+		}
+	}
+}
+
+// WithBearerToken sends "Authorization: Bearer <token>" with every request.
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithBearerTokenFunc calls fn before each attempt and sends the result as
+// a bearer token, for tokens that rotate. Requests that already carry an
+// Authorization header are left alone. fn may be called concurrently.
+func WithBearerTokenFunc(fn func() string) Option {
+	return func(c *config) { c.bearerFunc = fn }
+}
+
+// WithBasicAuth sends HTTP Basic credentials with every request.
+func WithBasicAuth(username, password string) Option {
+	creds := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return WithHeader("Authorization", "Basic "+creds)
+}
+
+// WithDigestAuth answers HTTP Digest challenges (RFC 7616, MD5 and SHA-256,
+// qop=auth) with the given credentials. The first request to a protected
+// resource gets a 401 with the challenge and is retried once with the
+// computed Authorization header; later requests to the same host reuse the
+// nonce, with an increasing nonce count, until the server marks it stale.
+// Hosts that haven't sent a challenge never receive the credentials.
+func WithDigestAuth(username, password string) Option {
+	return func(c *config) { c.digestUser, c.digestPass = username, password }
+}
+
+// WithAPIKeyPool sends one of keys in header with every attempt, spreading
+// quota across them. A key answered with 429 Too Many Requests is skipped
+// until its Retry-After (or a minute) has passed, unless every key is
+// benched. Per-key 429 counts are available from Client.APIKeyStats.
+// Requests that already set header keep their own value.
+func WithAPIKeyPool(header string, keys []string, strategy KeyStrategy) Option {
+	return func(c *config) {
+		c.apiKeys = apiKeyConfig{header: header, keys: keys, strategy: strategy}
+	}
+}
+
+// WithAuthRefresh makes the client recover from 401 Unauthorized: fn is
+// called for a new token, which is sent as "Authorization: Bearer <token>",
+// and the request is retried once before the 401 is returned. The token is
+// kept for later requests, and concurrent 401s share a single refresh. The
+// extra attempt doesn't count against WithRetry.
+func WithAuthRefresh(fn func(ctx context.Context) (token string, err error)) Option {
+	return func(c *config) { c.authRefresh = fn }
+}
+
+// WithSigner signs every attempt with s, after the request hook and the
+// auth options, right before it is sent.
+func WithSigner(s Signer) Option {
+	return func(c *config) { c.signer = s }
+}
+
 // WithRequestHook sets a hook called before each request is sent.
 func WithRequestHook(fn func(req *http.Request)) Option {
 	return func(c *config) { c.requestHook = fn }
 }
 
 // WithResponseHook sets a hook called after each response is received.
+// RedirectChain(resp) reports the redirects that led to it.
 func WithResponseHook(fn func(resp *http.Response)) Option {
 	return func(c *config) { c.responseHook = fn }
 }
@@ -0,0 +1,191 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// OutboxStore persists the requests queued with Client.Enqueue until they
+// are delivered. Implementations must be safe for concurrent use; see
+// FileOutbox for one backed by a directory. A database-backed store only
+// needs to implement these three methods.
+type OutboxStore interface {
+	// Put inserts msg, or replaces the message with the same ID.
+	Put(msg *OutboxMessage) error
+	// List returns the pending messages, oldest first.
+	List() ([]*OutboxMessage, error)
+	// Delete removes the message with the given ID, if any.
+	Delete(id string) error
+}
+
+// OutboxMessage is a request waiting in the outbox.
+type OutboxMessage struct {
+	ID     string      `json:"id"`
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+
+	Created     time.Time `json:"created"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// outbox delivers the messages of an OutboxStore in the background.
+type outbox struct {
+	store    OutboxStore
+	interval time.Duration
+	running  atomic.Bool
+	dropped  atomic.Uint64
+}
+
+// newOutbox returns nil unless WithOutbox is configured.
+func newOutbox(cfg *config) *outbox {
+	if cfg.outboxStore == nil {
+		return nil
+	}
+	return &outbox{store: cfg.outboxStore, interval: cfg.outboxInterval}
+}
+
+// Enqueue stores req in the WithOutbox store and returns once it is
+// persisted; a background worker then delivers it with the client's retry
+// settings, across process restarts. Messages that keep failing are
+// retried with backoff until delivered, unless the upstream rejects them
+// with a 4xx status other than 408 or 429, in which case they are
+// dropped. Enqueue suits fire-and-forget traffic such as telemetry; the
+// response is discarded.
+func (c *Client) Enqueue(req *http.Request) error {
+	if c.outbox == nil {
+		return errors.New("resilient: enqueue: no outbox, see WithOutbox")
+	}
+	if c.inflight.closing() {
+		return ErrClientClosed
+	}
+	msg := &OutboxMessage{
+		ID:      newOutboxID(),
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Header:  req.Header.Clone(),
+		Created: c.cfg.clock.Now(),
+	}
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("resilient: enqueue: read body: %w", err)
+		}
+		msg.Body = body
+	}
+	msg.NextAttempt = msg.Created
+	if err := c.outbox.store.Put(msg); err != nil {
+		return fmt.Errorf("resilient: enqueue: %w", err)
+	}
+	go c.deliverOutbox()
+	return nil
+}
+
+// OutboxDropped returns the number of outbox messages dropped because the
+// upstream rejected them.
+func (c *Client) OutboxDropped() uint64 {
+	if c.outbox == nil {
+		return 0
+	}
+	return c.outbox.dropped.Load()
+}
+
+func newOutboxID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// startOutbox delivers pending messages, including those left by a
+// previous process, now and then every interval until the client is
+// closed.
+func (c *Client) startOutbox() {
+	if c.outbox == nil {
+		return
+	}
+	var tick func()
+	tick = func() {
+		c.deliverOutbox()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if !c.closed {
+			c.outboxTimer = c.cfg.clock.AfterFunc(c.outbox.interval, tick)
+		}
+	}
+	c.mu.Lock()
+	c.outboxTimer = c.cfg.clock.AfterFunc(0, tick)
+	c.mu.Unlock()
+}
+
+// deliverOutbox sends the messages that are due, one at a time. Only one
+// delivery pass runs at once; a pass requested meanwhile is skipped and
+// left to the next tick.
+func (c *Client) deliverOutbox() {
+	o := c.outbox
+	if c.offline.Load() {
+		return // delivered once back online
+	}
+	if !o.running.CompareAndSwap(false, true) {
+		return
+	}
+	defer o.running.Store(false)
+
+	msgs, err := o.store.List()
+	if err != nil {
+		return
+	}
+	for _, msg := range msgs {
+		if c.inflight.closing() {
+			return
+		}
+		if c.cfg.clock.Now().Before(msg.NextAttempt) {
+			continue
+		}
+		c.deliver(msg)
+	}
+}
+
+// deliver sends msg and removes it from the store once delivered or
+// rejected, or schedules it again.
+func (c *Client) deliver(msg *OutboxMessage) {
+	o := c.outbox
+	req, err := http.NewRequest(msg.Method, msg.URL, bytes.NewReader(msg.Body))
+	if err != nil {
+		o.store.Delete(msg.ID)
+		o.dropped.Add(1)
+		return
+	}
+	req.Header = msg.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+
+	_, status, err := c.Do(context.Background(), req)
+	switch {
+	case err == nil:
+		o.store.Delete(msg.ID)
+		return
+	case errors.Is(err, ErrClientClosed):
+		return // delivered by the next process
+	case status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests:
+		o.store.Delete(msg.ID)
+		o.dropped.Add(1)
+		return
+	}
+	msg.Attempts++
+	msg.LastError = err.Error()
+	msg.NextAttempt = c.cfg.clock.Now().Add(c.Backoff(msg.Attempts))
+	o.store.Put(msg)
+}
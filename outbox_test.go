@@ -0,0 +1,96 @@
+package resilient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitUntil polls cond for up to a second.
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOutboxDelivery(t *testing.T) {
+	var calls atomic.Int32
+	var delivered atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		delivered.Store(r.Header.Get("X-Event") + " " + string(body))
+	}))
+	defer srv.Close()
+
+	store, err := NewFileOutbox(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New(WithBaseURL(srv.URL), WithRetry(0, 0), WithOutbox(store, 10*time.Millisecond))
+	defer c.Close()
+
+	req, _ := c.NewRequest(t.Context(), http.MethodPost, "/events", strings.NewReader(`{"n":1}`))
+	req.Header.Set("X-Event", "signup")
+	if err := c.Enqueue(req); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, "delivery", func() bool { return delivered.Load() != nil })
+	if got := delivered.Load(); got != `signup {"n":1}` {
+		t.Fatalf("unexpected delivery %q", got)
+	}
+	waitUntil(t, "an empty outbox", func() bool {
+		msgs, _ := store.List()
+		return len(msgs) == 0
+	})
+}
+
+func TestOutboxSurvivesRestart(t *testing.T) {
+	var delivered atomic.Int32
+	var reject atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reject.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		delivered.Add(1)
+	}))
+	defer srv.Close()
+
+	store, err := NewFileOutbox(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A message left behind by a previous process.
+	store.Put(&OutboxMessage{ID: "left", Method: http.MethodPost, URL: srv.URL + "/events", Created: time.Now()})
+
+	c := New(WithRetry(0, 0), WithOutbox(store, 10*time.Millisecond))
+	waitUntil(t, "delivery of the pending message", func() bool { return delivered.Load() == 1 })
+
+	reject.Store(true)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/events", nil)
+	if err := c.Enqueue(req); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, "the rejected message to be dropped", func() bool { return c.OutboxDropped() == 1 })
+	if msgs, _ := store.List(); len(msgs) != 0 {
+		t.Fatalf("expected an empty outbox, got %d messages", len(msgs))
+	}
+
+	c.Close()
+	if err := c.Enqueue(req); err != ErrClientClosed {
+		t.Fatalf("expected ErrClientClosed after Close, got %v", err)
+	}
+}
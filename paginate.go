@@ -0,0 +1,83 @@
+package resilient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// Paginate walks a paginated JSON API starting at firstPath and yields
+// the items of every page. Each page is decoded into a T and handed to
+// extract, which returns the page's items and the path of the next page
+// (relative to the base URL, or an absolute URL); ok is false on the last
+// page. Cursor and offset schemes both fit:
+//
+//	type page struct {
+//		Users []User `json:"users"`
+//		Next  string `json:"next_cursor"`
+//	}
+//	users := resilient.Paginate(ctx, client, "/users?limit=100",
+//		func(p page) ([]User, string, bool) {
+//			return p.Users, "/users?limit=100&cursor=" + url.QueryEscape(p.Next), p.Next != ""
+//		})
+//	for u, err := range users {
+//		...
+//	}
+//
+// Pages are fetched lazily as iteration proceeds, each through the usual
+// retry and rate-limit pipeline. A failed page ends the sequence with a
+// (zero, err) pair, as does a next path that repeats the current one.
+func Paginate[T, I any](ctx context.Context, c *Client, firstPath string, extract func(page T) (items []I, next string, ok bool)) iter.Seq2[I, error] {
+	return func(yield func(I, error) bool) {
+		var zero I
+		for path := firstPath; ; {
+			var page T
+			if err := c.getPage(ctx, path, &page); err != nil {
+				yield(zero, err)
+				return
+			}
+			items, next, ok := extract(page)
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if !ok {
+				return
+			}
+			if next == path {
+				yield(zero, fmt.Errorf("resilient: paginate: next page %q repeats the current one", next))
+				return
+			}
+			path = next
+		}
+	}
+}
+
+// getPage fetches path, which may be an absolute URL, and decodes the JSON
+// response into out.
+func (c *Client) getPage(ctx context.Context, path string, out any) error {
+	var req *http.Request
+	var err error
+	if u, perr := url.Parse(path); perr == nil && u.IsAbs() {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	} else {
+		req, err = c.NewRequest(ctx, http.MethodGet, path, nil)
+	}
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", JSONCodec.ContentType())
+
+	data, _, err := c.Do(req.Context(), req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("resilient: unmarshal response: %w", err)
+	}
+	return nil
+}
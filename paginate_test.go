@@ -0,0 +1,86 @@
+package resilient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+type testPage struct {
+	Items []int  `json:"items"`
+	Next  string `json:"next"`
+}
+
+func TestPaginate(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		next := ""
+		switch {
+		case cursor == 0:
+			next = "/items?cursor=2"
+		case cursor == 2:
+			// Absolute next links are followed as is.
+			next = srv.URL + "/items?cursor=4"
+		}
+		fmt.Fprintf(w, `{"items":[%d,%d],"next":%q}`, cursor, cursor+1, next)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var got []int
+	for item, err := range Paginate(context.Background(), c, "/items", func(p testPage) ([]int, string, bool) {
+		return p.Items, p.Next, p.Next != ""
+	}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, item)
+	}
+	if fmt.Sprint(got) != "[0 1 2 3 4 5]" {
+		t.Fatalf("expected all pages, got %v", got)
+	}
+}
+
+func TestPaginateStopsEarly(t *testing.T) {
+	pages := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		if r.URL.Query().Get("p") == "3" {
+			http.Error(w, "boom", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"items":[1,2],"next":"/?p=%d"}`, pages+1)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	extract := func(p testPage) ([]int, string, bool) { return p.Items, p.Next, true }
+
+	for range Paginate(context.Background(), c, "/", extract) {
+		break
+	}
+	if pages != 1 {
+		t.Fatalf("expected breaking to stop fetching, got %d pages", pages)
+	}
+
+	pages = 0
+	var n int
+	var err error
+	for _, err = range Paginate(context.Background(), c, "/", extract) {
+		if err != nil {
+			break
+		}
+		n++
+	}
+	if err == nil || n != 4 {
+		t.Fatalf("expected 4 items then the page error, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,99 @@
+package resilient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PathParam fills the {name} placeholder of a path template passed to the
+// convenience methods:
+//
+//	client.Get(ctx, "/users/{id}/repos", resilient.PathParam("id", userID))
+//
+// The value is path-escaped. Only the path is templated, not the query
+// string, and braces without a matching PathParam are sent as they are.
+// PathParam can be mixed freely with header maps; it occupies the "{name}"
+// key, which is never a valid header name.
+func PathParam(name, value string) map[string]string {
+	return map[string]string{"{" + name + "}": value}
+}
+
+func isPathParam(key string) bool {
+	return len(key) > 2 && key[0] == '{' && key[len(key)-1] == '}'
+}
+
+// expandPath substitutes the path parameters found in headers into the
+// path part of template, before any query string. Braces without a
+// matching PathParam are left as they are.
+func expandPath(template string, headers []map[string]string) string {
+	path, query, hasQuery := strings.Cut(template, "?")
+	if !strings.Contains(path, "{") {
+		return template
+	}
+	var b strings.Builder
+	rest := path
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			break
+		}
+		key := rest[open : open+end+1]
+		b.WriteString(rest[:open])
+		if value, ok := lookupPathParam(key, headers); ok {
+			b.WriteString(url.PathEscape(value))
+		} else {
+			b.WriteString(key)
+		}
+		rest = rest[open+end+1:]
+	}
+	b.WriteString(rest)
+	if hasQuery {
+		b.WriteString("?" + query)
+	}
+	return b.String()
+}
+
+func lookupPathParam(key string, headers []map[string]string) (string, bool) {
+	for i := len(headers) - 1; i >= 0; i-- {
+		if v, ok := headers[i][key]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+type routeKey struct{}
+
+// Route returns the path template a request was made from, such as
+// "/users/{id}/repos", or its URL path if no template was used. Metrics
+// hooks can use it to group requests by route rather than concrete URL.
+func Route(req *http.Request) string {
+	if route, ok := req.Context().Value(routeKey{}).(string); ok {
+		return route
+	}
+	return req.URL.Path
+}
+
+// NewRequest builds a request to baseURL+path, below the Group prefix if
+// any, the way the convenience methods do: path parameters are expanded,
+// headers applied, and the request's context carries the route template.
+// It is meant for helpers that encode their own bodies and then call Do.
+func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader, headers ...map[string]string) (*http.Request, error) {
+	expanded := expandPath(path, headers)
+	if expanded != path {
+		ctx = context.WithValue(ctx, routeKey{}, c.cfg.pathPrefix+path)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.baseURL+c.cfg.pathPrefix+expanded, body)
+	if err != nil {
+		return nil, err
+	}
+	applyHeaders(req, headers)
+	return req, nil
+}
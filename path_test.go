@@ -0,0 +1,60 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPathParams(t *testing.T) {
+	var route string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.EscapedPath() + "|" + r.Header.Get("X-Test")))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRequestHook(func(req *http.Request) {
+		route = Route(req)
+	}))
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/users/{id}/repos/{repo}",
+		PathParam("id", "a b/c"), PathParam("repo", "x"), map[string]string{"X-Test": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "/users/a%20b%2Fc/repos/x|1" {
+		t.Fatalf("unexpected path or headers: %q", body)
+	}
+	if route != "/users/{id}/repos/{repo}" {
+		t.Fatalf("expected route template, got %q", route)
+	}
+}
+
+func TestPathParamsLiteralBraces(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path + "|" + r.URL.Query().Get("q")))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	// Braces in the query string, or without a matching PathParam, are
+	// sent as they are.
+	body, _, err := c.Get(context.Background(), `/search/{kind}/{id}?q={"id":1}`, PathParam("id", "7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `/search/{kind}/7|{"id":1}` {
+		t.Fatalf("unexpected path or query: %q", body)
+	}
+}
+
+func TestRouteWithoutTemplate(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	if got := Route(req); got != "/users/1" {
+		t.Fatalf("expected URL path, got %q", got)
+	}
+}
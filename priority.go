@@ -0,0 +1,220 @@
+package resilient
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// Priority orders requests waiting for a rate-limit token when
+// WithPriorityQueue is enabled. Higher values are served first.
+type Priority int
+
+const (
+	// PriorityLow is for background and batch traffic.
+	PriorityLow Priority = -1
+	// PriorityNormal is the default for requests without a priority.
+	PriorityNormal Priority = 0
+	// PriorityHigh is for latency-sensitive, user-facing calls.
+	PriorityHigh Priority = 1
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx that tags requests made with it with p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom returns the priority carried by ctx, or PriorityNormal.
+func PriorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// queueWaiter is a request waiting in the priority queue. err is set
+// before ready is closed.
+type queueWaiter struct {
+	priority  Priority
+	seq       uint64
+	ready     chan struct{}
+	err       error
+	served    bool
+	cancelled bool
+}
+
+// waiterHeap orders waiters by priority, then arrival.
+type waiterHeap []*queueWaiter
+
+func (h waiterHeap) Len() int { return len(h) }
+func (h waiterHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h waiterHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *waiterHeap) Push(x any)   { *h = append(*h, x.(*queueWaiter)) }
+func (h *waiterHeap) Pop() any {
+	old := *h
+	w := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return w
+}
+
+// priorityQueue hands out rate-limit tokens to waiters in priority order.
+// A single dispatcher reserves the next token and only picks its recipient
+// once the token is due, so urgent requests arriving in the meantime still
+// jump ahead. The dispatcher's wait is cut short by stop once every waiter
+// has given up or the queue is closed.
+type priorityQueue struct {
+	mu      sync.Mutex
+	waiters waiterHeap
+	seq     uint64
+	live    int // waiters neither served nor cancelled
+	running bool
+	stop    context.CancelFunc
+	closed  bool
+}
+
+// wait blocks until the dispatcher grants a token from lim, ctx is done or
+// the queue is closed.
+func (q *priorityQueue) wait(ctx context.Context, lim RateLimiter) error {
+	w := &queueWaiter{priority: PriorityFrom(ctx), ready: make(chan struct{})}
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return ErrClientClosed
+	}
+	q.seq++
+	w.seq = q.seq
+	heap.Push(&q.waiters, w)
+	q.live++
+	if !q.running {
+		q.running = true
+		var dctx context.Context
+		dctx, q.stop = context.WithCancel(context.Background())
+		go q.dispatch(dctx, lim)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return w.err
+	case <-ctx.Done():
+		q.mu.Lock()
+		if !w.served {
+			w.cancelled = true
+			q.live--
+			if q.live == 0 {
+				q.stop()
+			}
+		}
+		q.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// close fails every waiter with ErrClientClosed and stops the dispatcher.
+func (q *priorityQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	if q.stop != nil {
+		q.stop()
+	}
+	q.failAll(ErrClientClosed)
+}
+
+// failAll hands err to every waiter still queued. q.mu must be held.
+func (q *priorityQueue) failAll(err error) {
+	for q.waiters.Len() > 0 {
+		w := heap.Pop(&q.waiters).(*queueWaiter)
+		if !w.cancelled {
+			w.served = true
+			w.err = err
+			close(w.ready)
+		}
+	}
+	q.live = 0
+}
+
+func (q *priorityQueue) dispatch(ctx context.Context, lim RateLimiter) {
+	for {
+		cancel, err := take(ctx, lim)
+
+		q.mu.Lock()
+		if err != nil {
+			switch {
+			case q.closed || q.live == 0:
+			case ctx.Err() != nil:
+				// The waiters that stopped it left, but others joined since.
+				ctx, q.stop = context.WithCancel(context.Background())
+				q.mu.Unlock()
+				continue
+			default:
+				// The limiter can never grant a token, such as with burst 0.
+				q.failAll(err)
+			}
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		var next *queueWaiter
+		for q.waiters.Len() > 0 {
+			w := heap.Pop(&q.waiters).(*queueWaiter)
+			if !w.cancelled {
+				next = w
+				break
+			}
+		}
+		if next == nil {
+			// Everyone gave up; hand the token back and stop.
+			cancel()
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		next.served = true
+		q.live--
+		close(next.ready)
+		if q.live == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+	}
+}
+
+// take waits for a token from lim until ctx is done, returning a function
+// that hands it back. Limiters without reservations can't return an unused
+// token.
+func take(ctx context.Context, lim RateLimiter) (func(), error) {
+	rl, ok := lim.(reservingLimiter)
+	if !ok {
+		return func() {}, lim.Wait(ctx)
+	}
+	r := rl.Reserve()
+	if !r.OK() {
+		return nil, lim.Wait(ctx) // cannot be granted; let Wait report why
+	}
+	if d := r.Delay(); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			r.Cancel()
+			return nil, ctx.Err()
+		}
+	}
+	return r.Cancel, nil
+}
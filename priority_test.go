@@ -0,0 +1,125 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		order = append(order, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRateLimit(20, 1), WithPriorityQueue())
+	defer c.Close()
+
+	// Drain the burst so subsequent requests queue.
+	c.Get(context.Background(), "/warmup")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get(WithPriority(context.Background(), PriorityLow), "/low")
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.Get(WithPriority(context.Background(), PriorityHigh), "/high")
+	}()
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 5 || order[1] != "/high" {
+		t.Fatalf("expected high priority request to jump the queue, got %v", order)
+	}
+}
+
+func TestPriorityFrom(t *testing.T) {
+	if p := PriorityFrom(context.Background()); p != PriorityNormal {
+		t.Fatalf("expected normal priority by default, got %d", p)
+	}
+	if p := PriorityFrom(WithPriority(context.Background(), PriorityHigh)); p != PriorityHigh {
+		t.Fatalf("expected high priority, got %d", p)
+	}
+}
+
+func TestPriorityQueueInterrupted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRateLimit(0.1, 1), WithPriorityQueue())
+	c.Get(context.Background(), "/warmup")
+
+	// A canceled waiter returns at once and stops the dispatcher, which
+	// hands its reserved token back.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.Get(ctx, "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	waitUntil(t, "dispatcher stop", func() bool {
+		c.queue.mu.Lock()
+		defer c.queue.mu.Unlock()
+		return !c.queue.running
+	})
+
+	// Close fails the requests still waiting for a token.
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := c.Get(context.Background(), "/")
+		errc <- err
+	}()
+	waitUntil(t, "queued request", func() bool {
+		c.queue.mu.Lock()
+		defer c.queue.mu.Unlock()
+		return c.queue.live == 1
+	})
+	c.Close()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrClientClosed) {
+			t.Fatalf("expected ErrClientClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request still waiting after Close")
+	}
+}
+
+func TestPriorityQueueZeroBurst(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithLimiter(rate.NewLimiter(10, 0)), WithPriorityQueue())
+	defer c.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := c.Get(context.Background(), "/")
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("expected an error from a limiter that can never grant a token")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("request hangs on a limiter with burst 0")
+	}
+}
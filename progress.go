@@ -0,0 +1,46 @@
+package resilient
+
+import (
+	"context"
+	"io"
+)
+
+// progressReader reports the bytes read through it.
+type progressReader struct {
+	r     io.Reader
+	n     int64
+	total int64
+	fn    func(transferred, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.n += int64(n)
+		p.fn(p.n, p.total)
+	}
+	return n, err
+}
+
+type uploadProgressKey struct{}
+
+// withUploadOffset makes request bodies sent with ctx report progress as
+// part of a larger upload: offset bytes already sent out of total.
+func (c *Client) withUploadOffset(ctx context.Context, offset, total int64) context.Context {
+	fn := c.cfg.progress
+	if fn == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, uploadProgressKey{}, func(n, _ int64) {
+		fn(offset+n, total)
+	})
+}
+
+// uploadProgress returns the progress callback for a request body sent
+// with ctx, or nil.
+func (c *Client) uploadProgress(ctx context.Context) func(transferred, total int64) {
+	if fn, ok := ctx.Value(uploadProgressKey{}).(func(int64, int64)); ok {
+		return fn
+	}
+	return c.cfg.progress
+}
@@ -0,0 +1,141 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type progressLog struct {
+	mu      sync.Mutex
+	reports [][2]int64
+}
+
+func (p *progressLog) record(transferred, total int64) {
+	p.mu.Lock()
+	p.reports = append(p.reports, [2]int64{transferred, total})
+	p.mu.Unlock()
+}
+
+func (p *progressLog) last() [2]int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.reports) == 0 {
+		return [2]int64{}
+	}
+	return p.reports[len(p.reports)-1]
+}
+
+func TestProgressDownload(t *testing.T) {
+	payload := strings.Repeat("d", 100_000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		io.WriteString(w, payload)
+	}))
+	defer srv.Close()
+
+	var log progressLog
+	c := New(WithBaseURL(srv.URL), WithProgress(log.record))
+	defer c.Close()
+
+	if _, err := c.Download(context.Background(), "/", io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if got := log.last(); got != [2]int64{100_000, 100_000} {
+		t.Fatalf("expected final report of full size, got %v", got)
+	}
+}
+
+func TestProgressResumableDownload(t *testing.T) {
+	payload := []byte(strings.Repeat("r", 10_000))
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+			w.Write(payload[:3000])
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer srv.Close()
+
+	var log progressLog
+	c := New(WithBaseURL(srv.URL), WithRetry(1, time.Millisecond), WithProgress(log.record))
+	defer c.Close()
+
+	if _, err := c.DownloadResumable(context.Background(), "/", filepath.Join(t.TempDir(), "f")); err != nil {
+		t.Fatal(err)
+	}
+	var prev int64
+	for _, r := range log.reports {
+		if r[0] < prev || r[0] > r[1] {
+			t.Fatalf("expected monotonic progress within total, got %v", log.reports)
+		}
+		prev = r[0]
+	}
+	if got := log.last(); got != [2]int64{10_000, 10_000} {
+		t.Fatalf("expected final report of full size, got %v", got)
+	}
+}
+
+func TestProgressUpload(t *testing.T) {
+	ts := &tusServer{failAt: -1}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	var log progressLog
+	c := New(WithBaseURL(srv.URL), WithProgress(log.record))
+	defer c.Close()
+
+	payload := strings.Repeat("u", 250)
+	if _, err := c.Upload(context.Background(), TusDriver{Endpoint: "/files"}, strings.NewReader(payload), 250, 100); err != nil {
+		t.Fatal(err)
+	}
+	var prev int64
+	for _, r := range log.reports {
+		if r[1] != 250 || r[0] < prev {
+			t.Fatalf("expected monotonic progress against the upload size, got %v", log.reports)
+		}
+		prev = r[0]
+	}
+	if got := log.last(); got != [2]int64{250, 250} {
+		t.Fatalf("expected final report of full size, got %v", got)
+	}
+}
+
+func TestProgressRetriedBody(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	var log progressLog
+	c := New(WithBaseURL(srv.URL), WithRetry(1, time.Millisecond), WithProgress(log.record))
+	defer c.Close()
+
+	if _, _, err := c.Post(context.Background(), "/", "text/plain", strings.NewReader("hello")); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range log.reports {
+		if r[0] > 5 {
+			t.Fatalf("expected retries not to double count, got %v", log.reports)
+		}
+	}
+	if got := log.last(); got != [2]int64{5, 5} {
+		t.Fatalf("expected final report of full body, got %v", got)
+	}
+}
@@ -0,0 +1,168 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ProxyStrategy selects how WithProxyPool assigns proxies to requests.
+type ProxyStrategy int
+
+const (
+	// ProxyPerRequest rotates through the proxies on every request.
+	ProxyPerRequest ProxyStrategy = iota
+	// ProxyPerHost sends all requests to a host through the same proxy,
+	// moving the host to another one only when its proxy is benched.
+	ProxyPerHost
+)
+
+const (
+	// proxyMaxFailures is how many consecutive failures bench a proxy.
+	proxyMaxFailures = 3
+	// proxyCooldown is how long a benched proxy sits out.
+	proxyCooldown = 30 * time.Second
+)
+
+// ProxyStats describes one proxy of a WithProxyPool pool.
+type ProxyStats struct {
+	URL      string
+	Requests uint64
+	Failures uint64
+	// Benched reports whether the proxy is currently sitting out after
+	// repeated failures.
+	Benched bool
+}
+
+type proxyState struct {
+	url          *url.URL
+	requests     uint64
+	failures     uint64
+	consecutive  int
+	benchedUntil time.Time
+}
+
+// proxyPool hands out proxies and benches the ones that keep failing.
+type proxyPool struct {
+	strategy ProxyStrategy
+
+	mu      sync.Mutex
+	proxies []*proxyState
+	next    int
+	hosts   map[string]*proxyState
+}
+
+func newProxyPool(cfg *config) *proxyPool {
+	var proxies []*proxyState
+	for _, raw := range cfg.proxyURLs {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			proxies = append(proxies, &proxyState{url: u})
+		}
+	}
+	if len(proxies) == 0 {
+		return nil
+	}
+	return &proxyPool{strategy: cfg.proxyStrategy, proxies: proxies, hosts: make(map[string]*proxyState)}
+}
+
+// pick returns the proxy for a request to host.
+func (p *proxyPool) pick(host string, now time.Time) *proxyState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.strategy == ProxyPerHost {
+		if ps, ok := p.hosts[host]; ok && !now.Before(ps.benchedUntil) {
+			ps.requests++
+			return ps
+		}
+	}
+
+	// The next proxy in turn that isn't benched, or the one whose bench
+	// ends first when all of them are.
+	var best *proxyState
+	for i := range p.proxies {
+		idx := (p.next + i) % len(p.proxies)
+		ps := p.proxies[idx]
+		if !now.Before(ps.benchedUntil) {
+			best, p.next = ps, idx+1
+			break
+		}
+		if best == nil || ps.benchedUntil.Before(best.benchedUntil) {
+			best = ps
+		}
+	}
+	if p.strategy == ProxyPerHost {
+		p.hosts[host] = best
+	}
+	best.requests++
+	return best
+}
+
+// report records the outcome of a request sent through ps.
+func (p *proxyPool) report(ps *proxyState, failed bool, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !failed {
+		ps.consecutive = 0
+		return
+	}
+	ps.failures++
+	ps.consecutive++
+	if ps.consecutive >= proxyMaxFailures {
+		ps.consecutive = 0
+		ps.benchedUntil = now.Add(proxyCooldown)
+	}
+}
+
+func (p *proxyPool) stats(now time.Time) []ProxyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]ProxyStats, len(p.proxies))
+	for i, ps := range p.proxies {
+		stats[i] = ProxyStats{
+			URL:      ps.url.Redacted(),
+			Requests: ps.requests,
+			Failures: ps.failures,
+			Benched:  now.Before(ps.benchedUntil),
+		}
+	}
+	return stats
+}
+
+// ProxyStats returns per-proxy counters for WithProxyPool, in the order
+// the proxies were given, or nil without a proxy pool. Credentials in
+// proxy URLs are redacted.
+func (c *Client) ProxyStats() []ProxyStats {
+	if c.proxies == nil {
+		return nil
+	}
+	return c.proxies.stats(time.Now())
+}
+
+type proxyKey struct{}
+
+// proxyFromContext is the http.Transport.Proxy func used with a pool: the
+// proxy was chosen by proxyTransport and travels in the request context.
+func proxyFromContext(req *http.Request) (*url.URL, error) {
+	u, _ := req.Context().Value(proxyKey{}).(*url.URL)
+	return u, nil
+}
+
+// proxyTransport picks a proxy for each request and reports failures
+// (transport errors and 407 Proxy Authentication Required) to the pool.
+type proxyTransport struct {
+	base http.RoundTripper
+	pool *proxyPool
+}
+
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ps := t.pool.pick(req.URL.Host, time.Now())
+	req = req.WithContext(context.WithValue(req.Context(), proxyKey{}, ps.url))
+	resp, err := t.base.RoundTrip(req)
+	failed := err != nil && req.Context().Err() == nil ||
+		resp != nil && resp.StatusCode == http.StatusProxyAuthRequired
+	t.pool.report(ps, failed, time.Now())
+	return resp, err
+}
@@ -0,0 +1,92 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeProxy answers proxied requests itself with its name and the target
+// host.
+func fakeProxy(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(name + " " + r.URL.Host))
+	}))
+}
+
+func TestProxyPoolRotation(t *testing.T) {
+	p1, p2 := fakeProxy("p1"), fakeProxy("p2")
+	defer p1.Close()
+	defer p2.Close()
+
+	c := New(WithProxyPool([]string{p1.URL, p2.URL, "::bad"}, ProxyPerRequest))
+	defer c.Close()
+
+	var got []string
+	for range 3 {
+		body, _, err := c.Get(context.Background(), "http://api.example/x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(body))
+	}
+	if got[0] != "p1 api.example" || got[1] != "p2 api.example" || got[2] != "p1 api.example" {
+		t.Fatalf("expected round robin over proxies, got %v", got)
+	}
+	if stats := c.ProxyStats(); len(stats) != 2 || stats[0].Requests != 2 || stats[1].Requests != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestProxyPoolPerHost(t *testing.T) {
+	p1, p2 := fakeProxy("p1"), fakeProxy("p2")
+	defer p1.Close()
+	defer p2.Close()
+
+	c := New(WithProxyPool([]string{p1.URL, p2.URL}, ProxyPerHost))
+	defer c.Close()
+
+	get := func(u string) string {
+		body, _, err := c.Get(context.Background(), u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+	a1, b1, a2, b2 := get("http://a.example/"), get("http://b.example/"), get("http://a.example/"), get("http://b.example/")
+	if a1 != a2 || b1 != b2 || a1[:2] == b1[:2] {
+		t.Fatalf("expected hosts pinned to different proxies, got %q %q %q %q", a1, b1, a2, b2)
+	}
+}
+
+func TestProxyPoolBenchesFailing(t *testing.T) {
+	good := fakeProxy("good")
+	defer good.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	c := New(
+		WithProxyPool([]string{dead.URL, good.URL}, ProxyPerHost),
+		WithRetry(5, time.Millisecond),
+	)
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "http://api.example/")
+	if err != nil || string(body) != "good api.example" {
+		t.Fatalf("expected failover to the healthy proxy, got %q %v", body, err)
+	}
+	stats := c.ProxyStats()
+	if stats[0].Failures != proxyMaxFailures || !stats[0].Benched || stats[1].Benched {
+		t.Fatalf("expected the dead proxy benched, got %+v", stats)
+	}
+
+	p := c.proxies
+	if ps := p.pick("api.example", time.Now().Add(proxyCooldown)); ps.url.Host != good.Listener.Addr().String() {
+		t.Fatalf("expected the host to stay on the healthy proxy, got %s", ps.url)
+	}
+	if ps := p.pick("other.example", time.Now().Add(proxyCooldown)); ps != p.proxies[0] {
+		t.Fatalf("expected the dead proxy back after the cooldown, got %s", ps.url)
+	}
+}
@@ -0,0 +1,158 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// quotaPacer spreads the quota an API reports as remaining evenly over the
+// time left until it resets, so a client drains its budget at a steady pace
+// instead of bursting into a 429.
+type quotaPacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+	reset    time.Time
+	lastUsed time.Time // guarded by Client.mu, for the idle sweep
+}
+
+// update recomputes the pacing interval from the remaining quota and the
+// reset time reported by a response.
+func (p *quotaPacer) update(remaining int, reset, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reset = reset
+	window := reset.Sub(now)
+	switch {
+	case window <= 0:
+		p.interval = 0
+	case remaining <= 0:
+		// Quota exhausted: hold everything until the window resets.
+		p.interval = 0
+		if reset.After(p.next) {
+			p.next = reset
+		}
+	default:
+		p.interval = window / time.Duration(remaining)
+	}
+}
+
+// reserve returns how long the caller must wait for its turn.
+func (p *quotaPacer) reserve(now time.Time) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !now.Before(p.reset) {
+		// The window has reset; pace freely until new headers arrive.
+		p.interval = 0
+		return 0
+	}
+	slot := now
+	if p.next.After(slot) {
+		slot = p.next
+	}
+	p.next = slot.Add(p.interval)
+	return slot.Sub(now)
+}
+
+func (p *quotaPacer) wait(ctx context.Context) error {
+	delay := p.reserve(time.Now())
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseQuota reads X-RateLimit-Remaining and X-RateLimit-Reset. The reset
+// may be a Unix timestamp (as GitHub sends) or a number of seconds from now.
+func parseQuota(h http.Header, now time.Time) (remaining int, reset time.Time, ok bool) {
+	remaining, err := strconv.Atoi(strings.TrimSpace(h.Get("X-RateLimit-Remaining")))
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(h.Get("X-RateLimit-Reset")), 10, 64)
+	if err != nil || secs < 0 {
+		return 0, time.Time{}, false
+	}
+	// Anything later than 2001-09-09 is an epoch timestamp, not a delta.
+	if secs >= 1e9 {
+		return remaining, time.Unix(secs, 0), true
+	}
+	return remaining, now.Add(time.Duration(secs) * time.Second), true
+}
+
+// observeQuota reports IETF RateLimit headers to the rate-limit hook and
+// updates the pacer for the request's host. X-RateLimit-* headers take
+// precedence over the IETF fields for pacing.
+func (c *Client) observeQuota(req *http.Request, h http.Header) {
+	if !c.cfg.quotaPacing && c.cfg.rateLimitHook == nil {
+		return
+	}
+	info, hasInfo := ParseRateLimit(h)
+	if hasInfo && c.cfg.rateLimitHook != nil {
+		c.cfg.rateLimitHook(req, info)
+	}
+	if !c.cfg.quotaPacing {
+		return
+	}
+
+	now := time.Now()
+	remaining, reset, ok := parseQuota(h, now)
+	if !ok && hasInfo {
+		remaining, reset, ok = info.Remaining, now.Add(info.Reset), true
+	}
+	if ok {
+		c.quotaPacer(req.URL.Host).update(remaining, reset, now)
+	}
+}
+
+// quotaPacer returns the pacer for host, creating it on first use.
+func (c *Client) quotaPacer(host string) *quotaPacer {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.quotas[host]
+	if !ok {
+		if c.quotas == nil {
+			c.quotas = make(map[string]*quotaPacer)
+		}
+		p = &quotaPacer{}
+		c.quotas[host] = p
+	}
+	p.lastUsed = now
+	if len(c.quotas) >= c.quotaSweepAt {
+		c.sweepQuotas(now)
+	}
+	return p
+}
+
+// sweepQuotas drops the pacers idle for idleTTL whose window has reset and
+// that hold no reserved slot, which a fresh pacer would replace exactly.
+// It must be called with c.mu held.
+func (c *Client) sweepQuotas(now time.Time) {
+	for host, p := range c.quotas {
+		if now.Sub(p.lastUsed) < idleTTL {
+			continue
+		}
+		p.mu.Lock()
+		done := !now.Before(p.reset) && !now.Before(p.next)
+		p.mu.Unlock()
+		if done {
+			delete(c.quotas, host)
+		}
+	}
+	c.quotaSweepAt = max(64, 2*len(c.quotas))
+}
@@ -0,0 +1,114 @@
+package resilient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuotaPacing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "10")
+		w.Header().Set("X-RateLimit-Reset", "1")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithQuotaPacing())
+	defer c.Close()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, _, err := c.Get(context.Background(), "/"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// The first response sets a 100ms interval; two later requests wait for it.
+	if d := time.Since(start); d < 180*time.Millisecond {
+		t.Fatalf("expected requests to be paced ~100ms apart, took %v", d)
+	}
+}
+
+func TestQuotaPacerExhausted(t *testing.T) {
+	now := time.Now()
+	var p quotaPacer
+	p.update(0, now.Add(30*time.Second), now)
+	if d := p.reserve(now); d != 30*time.Second {
+		t.Fatalf("expected to wait for reset, got %v", d)
+	}
+	if d := p.reserve(now.Add(31 * time.Second)); d != 0 {
+		t.Fatalf("expected no wait after reset, got %v", d)
+	}
+}
+
+func TestQuotaPacerSpread(t *testing.T) {
+	now := time.Now()
+	var p quotaPacer
+	p.update(60, now.Add(time.Minute), now)
+	if d := p.reserve(now); d != 0 {
+		t.Fatalf("expected first request to go immediately, got %v", d)
+	}
+	if d := p.reserve(now); d != time.Second {
+		t.Fatalf("expected 1s spacing, got %v", d)
+	}
+}
+
+func TestQuotaPacersEvictIdleHosts(t *testing.T) {
+	c := New(WithQuotaPacing())
+	defer c.Close()
+
+	now := time.Now()
+	busy := c.quotaPacer("busy.example")
+	held := c.quotaPacer("held.example")
+	held.update(0, now.Add(time.Hour), now) // exhausted until the reset
+	for i := range 1000 {
+		c.quotaPacer(fmt.Sprintf("host%d.example", i)).update(10, now.Add(-time.Second), now)
+		c.quotaPacer("busy.example")
+		c.mu.Lock()
+		// Age every host but the busy one as if it was last called long ago.
+		for h, p := range c.quotas {
+			if h != "busy.example" {
+				p.lastUsed = p.lastUsed.Add(-idleTTL)
+			}
+		}
+		n := len(c.quotas)
+		c.mu.Unlock()
+		if n > 128 {
+			t.Fatalf("quota pacers grew to %d after %d hosts", n, i+1)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.quotas["busy.example"] != busy {
+		t.Fatal("busy host pacer was evicted")
+	}
+	if c.quotas["held.example"] != held {
+		t.Fatal("pacer holding requests until its reset was evicted")
+	}
+}
+
+func TestParseQuota(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	h := http.Header{}
+	h.Set("X-RateLimit-Remaining", "42")
+	h.Set("X-RateLimit-Reset", "1700000060")
+	remaining, reset, ok := parseQuota(h, now)
+	if !ok || remaining != 42 || !reset.Equal(now.Add(time.Minute)) {
+		t.Fatalf("epoch reset: got %d %v %v", remaining, reset, ok)
+	}
+
+	h.Set("X-RateLimit-Reset", "30")
+	if _, reset, ok = parseQuota(h, now); !ok || !reset.Equal(now.Add(30*time.Second)) {
+		t.Fatalf("delta reset: got %v %v", reset, ok)
+	}
+
+	h.Del("X-RateLimit-Remaining")
+	if _, _, ok = parseQuota(h, now); ok {
+		t.Fatal("expected missing headers to be ignored")
+	}
+}
@@ -0,0 +1,104 @@
+package resilient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitInfo is the quota state a server reports with the IETF RateLimit
+// header fields (draft-ietf-httpapi-ratelimit-headers).
+type RateLimitInfo struct {
+	// Policy is the policy name, empty if the server doesn't name it.
+	Policy string
+	// Limit is the quota per window, 0 if no policy was sent.
+	Limit int
+	// Window is the policy's time window, 0 if unknown.
+	Window time.Duration
+	// Remaining is the quota left in the current window.
+	Remaining int
+	// Reset is the time until the quota resets.
+	Reset time.Duration
+}
+
+// ParseRateLimit reads the IETF RateLimit and RateLimit-Policy headers from
+// h. It understands the structured form of recent drafts
+//
+//	RateLimit: "default";r=50;t=30
+//	RateLimit-Policy: "default";q=100;w=60
+//
+// as well as the RateLimit-Limit/-Remaining/-Reset fields of earlier ones.
+// When several policies are reported, the one with the least remaining
+// quota is returned.
+func ParseRateLimit(h http.Header) (RateLimitInfo, bool) {
+	if v := h.Get("RateLimit"); v != "" {
+		return parseRateLimitItems(v, h.Values("RateLimit-Policy"))
+	}
+
+	remaining, err := strconv.Atoi(strings.TrimSpace(h.Get("RateLimit-Remaining")))
+	if err != nil {
+		return RateLimitInfo{}, false
+	}
+	reset, err := strconv.Atoi(strings.TrimSpace(h.Get("RateLimit-Reset")))
+	if err != nil || reset < 0 {
+		return RateLimitInfo{}, false
+	}
+	info := RateLimitInfo{Remaining: remaining, Reset: time.Duration(reset) * time.Second}
+	info.Limit, _ = strconv.Atoi(strings.TrimSpace(h.Get("RateLimit-Limit")))
+	if p := h.Get("RateLimit-Policy"); p != "" {
+		// e.g. "100;w=60": the bare quota followed by parameters.
+		_, params := parseSFItem(p)
+		info.Window = sfSeconds(params["w"])
+	}
+	return info, true
+}
+
+func parseRateLimitItems(v string, policyValues []string) (RateLimitInfo, bool) {
+	policies := map[string]map[string]string{}
+	for _, pv := range policyValues {
+		for _, item := range strings.Split(pv, ",") {
+			name, params := parseSFItem(item)
+			policies[name] = params
+		}
+	}
+
+	var best RateLimitInfo
+	found := false
+	for _, item := range strings.Split(v, ",") {
+		name, params := parseSFItem(item)
+		remaining, err := strconv.Atoi(params["r"])
+		if err != nil {
+			continue
+		}
+		info := RateLimitInfo{Policy: name, Remaining: remaining, Reset: sfSeconds(params["t"])}
+		if p, ok := policies[name]; ok {
+			info.Limit, _ = strconv.Atoi(p["q"])
+			info.Window = sfSeconds(p["w"])
+		}
+		if !found || info.Remaining < best.Remaining {
+			best, found = info, true
+		}
+	}
+	return best, found
+}
+
+// parseSFItem splits a structured-field item such as `"name";a=1;b=2` into
+// its bare value (unquoted) and parameters.
+func parseSFItem(item string) (string, map[string]string) {
+	parts := strings.Split(item, ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+		params[strings.ToLower(k)] = strings.Trim(v, `"`)
+	}
+	return strings.Trim(strings.TrimSpace(parts[0]), `"`), params
+}
+
+func sfSeconds(v string) time.Duration {
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
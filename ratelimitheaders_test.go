@@ -0,0 +1,83 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitStructured(t *testing.T) {
+	h := http.Header{}
+	h.Set("RateLimit", `"burst";r=80;t=1, "daily";r=5;t=3600`)
+	h.Set("RateLimit-Policy", `"burst";q=100;w=1, "daily";q=1000;w=86400`)
+
+	info, ok := ParseRateLimit(h)
+	if !ok {
+		t.Fatal("expected headers to parse")
+	}
+	want := RateLimitInfo{Policy: "daily", Limit: 1000, Window: 86400 * time.Second, Remaining: 5, Reset: time.Hour}
+	if info != want {
+		t.Fatalf("expected most restrictive policy %+v, got %+v", want, info)
+	}
+}
+
+func TestParseRateLimitLegacy(t *testing.T) {
+	h := http.Header{}
+	h.Set("RateLimit-Limit", "100")
+	h.Set("RateLimit-Remaining", "42")
+	h.Set("RateLimit-Reset", "30")
+	h.Set("RateLimit-Policy", "100;w=60")
+
+	info, ok := ParseRateLimit(h)
+	want := RateLimitInfo{Limit: 100, Window: time.Minute, Remaining: 42, Reset: 30 * time.Second}
+	if !ok || info != want {
+		t.Fatalf("expected %+v, got %+v (ok=%v)", want, info, ok)
+	}
+
+	if _, ok := ParseRateLimit(http.Header{}); ok {
+		t.Fatal("expected no info without headers")
+	}
+}
+
+func TestRateLimitHook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit", `"default";r=9;t=60`)
+		w.Header().Set("RateLimit-Policy", `"default";q=10;w=60`)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var got RateLimitInfo
+	c := New(WithBaseURL(srv.URL), WithRateLimitHook(func(req *http.Request, info RateLimitInfo) {
+		got = info
+	}))
+	defer c.Close()
+
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Policy != "default" || got.Limit != 10 || got.Remaining != 9 {
+		t.Fatalf("unexpected hook info: %+v", got)
+	}
+}
+
+func TestQuotaPacingIETF(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit", `"default";r=10;t=1`)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithQuotaPacing())
+	defer c.Close()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		c.Get(context.Background(), "/")
+	}
+	if d := time.Since(start); d < 180*time.Millisecond {
+		t.Fatalf("expected IETF headers to drive pacing, took %v", d)
+	}
+}
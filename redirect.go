@@ -0,0 +1,28 @@
+package resilient
+
+import "net/http"
+
+// Redirect is one hop of a followed redirect: the URL that was requested
+// and the 3xx status it was answered with.
+type Redirect struct {
+	URL        string
+	StatusCode int
+}
+
+// RedirectChain returns the redirects followed to arrive at resp, oldest
+// first, or nil if the first request was answered directly. It is meant
+// for response hooks; DoResult records the same chain in Result.Redirects.
+func RedirectChain(resp *http.Response) []Redirect {
+	if resp == nil || resp.Request == nil {
+		return nil
+	}
+	var chain []Redirect
+	for r := resp.Request.Response; r != nil; {
+		chain = append(chain, Redirect{URL: r.Request.URL.String(), StatusCode: r.StatusCode})
+		r = r.Request.Response
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
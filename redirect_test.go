@@ -0,0 +1,48 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectChain(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/short":
+			http.Redirect(w, r, "/login", http.StatusMovedPermanently)
+		case "/login":
+			http.Redirect(w, r, "/home", http.StatusFound)
+		default:
+			w.Write([]byte("home"))
+		}
+	}))
+	defer srv.Close()
+
+	var hooked []Redirect
+	c := New(WithBaseURL(srv.URL), WithResponseHook(func(resp *http.Response) {
+		hooked = RedirectChain(resp)
+	}))
+	defer c.Close()
+
+	req, _ := c.NewRequest(context.Background(), http.MethodGet, "/short", nil)
+	res, err := c.DoResult(context.Background(), req)
+	if err != nil || string(res.Body) != "home" {
+		t.Fatalf("expected success, got %q %v", res.Body, err)
+	}
+	want := []Redirect{
+		{URL: srv.URL + "/short", StatusCode: http.StatusMovedPermanently},
+		{URL: srv.URL + "/login", StatusCode: http.StatusFound},
+	}
+	for _, got := range [][]Redirect{res.Redirects, hooked} {
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	req, _ = c.NewRequest(context.Background(), http.MethodGet, "/home", nil)
+	if res, _ := c.DoResult(context.Background(), req); res.Redirects != nil {
+		t.Fatalf("expected no redirects, got %v", res.Redirects)
+	}
+}
@@ -0,0 +1,100 @@
+package resilient
+
+import (
+	"slices"
+	"sync"
+)
+
+// Registry creates and holds named clients that share a set of default
+// options, for services that talk to many upstreams:
+//
+//	reg := resilient.NewRegistry(resilient.WithRetry(3, time.Second))
+//	reg.Register("github", resilient.WithBaseURL("https://api.github.com"))
+//	gh := reg.Client("github")
+//	defer reg.Close()
+type Registry struct {
+	defaults []Option
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewRegistry returns an empty Registry whose clients are created with
+// defaults, followed by their own options.
+func NewRegistry(defaults ...Option) *Registry {
+	return &Registry{defaults: defaults, clients: make(map[string]*Client)}
+}
+
+// Register creates the client called name from the registry defaults and
+// opts, closing and replacing any client already registered under it.
+func (r *Registry) Register(name string, opts ...Option) *Client {
+	c := New(append(slices.Clip(r.defaults), opts...)...)
+	r.mu.Lock()
+	old := r.clients[name]
+	r.clients[name] = c
+	r.mu.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	return c
+}
+
+// Client returns the client called name, creating it from the registry
+// defaults alone if it has not been registered.
+func (r *Registry) Client(name string) *Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.clients[name]
+	if c == nil {
+		c = New(r.defaults...)
+		r.clients[name] = c
+	}
+	return c
+}
+
+// Lookup returns the client called name, if there is one.
+func (r *Registry) Lookup(name string) (*Client, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.clients[name]
+	return c, ok
+}
+
+// Names returns the names of the registered clients, sorted.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.clients))
+	for name := range r.clients {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Stats returns a snapshot of each client's statistics, keyed by name.
+func (r *Registry) Stats() map[string]Stats {
+	r.mu.Lock()
+	clients := make(map[string]*Client, len(r.clients))
+	for name, c := range r.clients {
+		clients[name] = c
+	}
+	r.mu.Unlock()
+
+	stats := make(map[string]Stats, len(clients))
+	for name, c := range clients {
+		stats[name] = c.Stats()
+	}
+	return stats
+}
+
+// Close closes every client and empties the registry.
+func (r *Registry) Close() {
+	r.mu.Lock()
+	clients := r.clients
+	r.clients = make(map[string]*Client)
+	r.mu.Unlock()
+	for _, c := range clients {
+		c.Close()
+	}
+}
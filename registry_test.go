@@ -0,0 +1,44 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Service") + " " + r.Header.Get("X-Team")))
+	}))
+	defer srv.Close()
+
+	reg := NewRegistry(WithBaseURL(srv.URL), WithHeader("X-Team", "payments"))
+	gh := reg.Register("github", WithHeader("X-Service", "github"))
+	if reg.Client("github") != gh {
+		t.Fatal("expected Client to return the registered client")
+	}
+	body, _, err := gh.Get(context.Background(), "/")
+	if err != nil || string(body) != "github payments" {
+		t.Fatalf("expected defaults plus client options, got %q %v", body, err)
+	}
+	if _, _, err := reg.Client("stripe").Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reg.Lookup("slack"); ok {
+		t.Fatal("expected Lookup not to create clients")
+	}
+
+	if names := reg.Names(); len(names) != 2 || names[0] != "github" || names[1] != "stripe" {
+		t.Fatalf("unexpected names %v", names)
+	}
+	stats := reg.Stats()
+	if stats["github"].TotalRequests != 1 || stats["stripe"].TotalRequests != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	reg.Close()
+	if !gh.closed || len(reg.Names()) != 0 {
+		t.Fatal("expected Close to close and remove every client")
+	}
+}
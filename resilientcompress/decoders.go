@@ -0,0 +1,44 @@
+// Package resilientcompress provides brotli and zstd decoders for
+// resilient.WithDecompression:
+//
+//	client := resilient.New(
+//	    resilient.WithDecompression(resilient.Gzip, resilientcompress.Brotli, resilientcompress.Zstd),
+//	)
+//
+// It lives in its own package so the core package doesn't import the
+// compression libraries.
+package resilientcompress
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/egorkaBurkenya/resilient-go"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Decoders for the "br" and "zstd" content codings.
+var (
+	Brotli resilient.Decoder = brotliDecoder{}
+	Zstd   resilient.Decoder = zstdDecoder{}
+)
+
+type brotliDecoder struct{}
+
+func (brotliDecoder) Encoding() string { return "br" }
+
+func (brotliDecoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+}
+
+type zstdDecoder struct{}
+
+func (zstdDecoder) Encoding() string { return "zstd" }
+
+func (zstdDecoder) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
@@ -0,0 +1,59 @@
+package resilientcompress
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/egorkaBurkenya/resilient-go"
+	"github.com/klauspost/compress/zstd"
+)
+
+func compress(t *testing.T, enc string, data []byte) []byte {
+	var buf bytes.Buffer
+	switch enc {
+	case "br":
+		w := brotli.NewWriter(&buf)
+		w.Write(data)
+		w.Close()
+	case "zstd":
+		w, err := zstd.NewWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+		w.Close()
+	}
+	return buf.Bytes()
+}
+
+func TestDecoders(t *testing.T) {
+	payload := bytes.Repeat([]byte("compressible "), 100)
+	for _, enc := range []string{"br", "zstd"} {
+		t.Run(enc, func(t *testing.T) {
+			body := compress(t, enc, payload)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if ae := r.Header.Get("Accept-Encoding"); ae != "br, zstd" {
+					t.Errorf("unexpected Accept-Encoding %q", ae)
+				}
+				w.Header().Set("Content-Encoding", enc)
+				w.Write(body)
+			}))
+			defer srv.Close()
+
+			c := resilient.New(resilient.WithBaseURL(srv.URL), resilient.WithDecompression(Brotli, Zstd))
+			defer c.Close()
+
+			got, _, err := c.Get(context.Background(), "/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Fatalf("expected decoded body, got %d bytes", len(got))
+			}
+		})
+	}
+}
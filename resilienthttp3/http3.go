@@ -0,0 +1,211 @@
+// Package resilienthttp3 sends requests over HTTP/3 (QUIC), falling back to
+// the client's regular HTTP/2 or HTTP/1.1 transport when QUIC fails:
+//
+//	client := resilient.New(
+//	    resilient.WithBaseURL("https://api.example.com"),
+//	    resilienthttp3.WithHTTP3(),
+//	)
+//
+// Only https:// requests with a replayable body are tried over HTTP/3. When
+// no QUIC connection can be set up, typically because UDP is blocked along
+// the way, the request is re-sent over the fallback transport, and so are
+// later requests to the same host for a while before QUIC is tried again.
+// A request that fails once the connection is up is only re-sent when it
+// is idempotent, since it may already have been delivered.
+//
+// QUIC connections use the client's TLS settings (WithTLSConfig,
+// WithRootCAs, WithClientCertificate, WithPinnedCertificates, ...). They
+// can't go through WithProxyPool proxies, so a client with both fails its
+// requests with ErrProxied rather than bypassing the proxies.
+//
+// It lives in its own package so the core package doesn't import quic-go.
+package resilienthttp3
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// Option configures WithHTTP3.
+type Option func(*Transport)
+
+// WithHandshakeTimeout bounds the QUIC handshake, and so how long a request
+// waits before falling back when UDP is blocked. Defaults to 2 seconds.
+func WithHandshakeTimeout(d time.Duration) Option {
+	return func(t *Transport) { t.handshakeTimeout = d }
+}
+
+// WithFallbackPeriod sets how long a host that failed over QUIC is sent
+// over the fallback transport. Defaults to 5 minutes.
+func WithFallbackPeriod(d time.Duration) Option {
+	return func(t *Transport) { t.fallbackPeriod = d }
+}
+
+// WithTLSClientConfig sets the TLS configuration of QUIC connections. It
+// is cloned. WithHTTP3 sets it to the client's.
+func WithTLSClientConfig(tc *tls.Config) Option {
+	return func(t *Transport) { t.tlsConfig = tc }
+}
+
+// ErrProxied is returned for every request of a client that combines
+// WithHTTP3 with resilient.WithProxyPool.
+var ErrProxied = errors.New("resilienthttp3: HTTP/3 can't be sent through a proxy pool")
+
+// WithHTTP3 returns a resilient.Option that sends https:// requests over
+// HTTP/3, with the client's TLS settings.
+func WithHTTP3(opts ...Option) resilient.Option {
+	return resilient.WithTransportMiddlewareConfig(func(next http.RoundTripper, tc resilient.TransportConfig) http.RoundTripper {
+		t := NewTransport(next, append([]Option{WithTLSClientConfig(tc.TLS)}, opts...)...)
+		if tc.Proxied {
+			t.err = ErrProxied
+		}
+		return t
+	})
+}
+
+// Transport is an http.RoundTripper that tries HTTP/3 first and falls back
+// to another transport.
+type Transport struct {
+	h3        *http3.Transport
+	fallback  http.RoundTripper
+	tlsConfig *tls.Config
+	err       error // returned for every request
+
+	handshakeTimeout time.Duration
+	fallbackPeriod   time.Duration
+
+	mu     sync.Mutex
+	broken map[string]time.Time // host -> skip QUIC until
+}
+
+// NewTransport returns a Transport falling back to fallback. QUIC uses the
+// TLS configuration set with WithTLSClientConfig, or the defaults.
+func NewTransport(fallback http.RoundTripper, opts ...Option) *Transport {
+	t := &Transport{
+		fallback:         fallback,
+		handshakeTimeout: 2 * time.Second,
+		fallbackPeriod:   5 * time.Minute,
+		broken:           make(map[string]time.Time),
+	}
+	for _, o := range opts {
+		o(t)
+	}
+
+	var tc *tls.Config
+	if t.tlsConfig != nil {
+		tc = t.tlsConfig.Clone()
+		tc.NextProtos = nil
+	}
+	t.h3 = &http3.Transport{
+		TLSClientConfig: tc,
+		QUICConfig:      &quic.Config{HandshakeIdleTimeout: t.handshakeTimeout},
+		Dial:            dial,
+	}
+	return t
+}
+
+// dial sets up a QUIC connection with a full handshake, without 0-RTT, so
+// that no request is sent before it succeeds and its errors can be told
+// apart from those of a request in flight.
+func dial(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
+	conn, err := quic.DialAddr(ctx, addr, tlsCfg, cfg)
+	if err != nil {
+		return nil, &dialError{err}
+	}
+	return conn, nil
+}
+
+// dialError is a failure to set up a QUIC connection: nothing was sent.
+type dialError struct{ err error }
+
+func (e *dialError) Error() string { return e.err.Error() }
+
+func (e *dialError) Unwrap() error { return e.err }
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, t.err
+	}
+	// A body can only be replayed on the fallback through GetBody.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	if req.URL.Scheme != "https" || !replayable || t.isBroken(req.URL.Host) {
+		return t.fallback.RoundTrip(req)
+	}
+
+	resp, err := t.h3.RoundTrip(req)
+	if err == nil || req.Context().Err() != nil {
+		return resp, err
+	}
+
+	var de *dialError
+	switch {
+	case errors.As(err, &de):
+		t.markBroken(req.URL.Host)
+	case !isIdempotent(req):
+		return nil, err // may have been delivered over QUIC
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	return t.fallback.RoundTrip(req)
+}
+
+// isIdempotent reports whether req can be safely re-sent, by the same rules
+// as net/http.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, ok := req.Header["Idempotency-Key"]
+	if !ok {
+		_, ok = req.Header["X-Idempotency-Key"]
+	}
+	return ok
+}
+
+func (t *Transport) isBroken(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	until, ok := t.broken[host]
+	if ok && time.Now().After(until) {
+		delete(t.broken, host)
+		return false
+	}
+	return ok
+}
+
+func (t *Transport) markBroken(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.broken[host] = time.Now().Add(t.fallbackPeriod)
+}
+
+// Close closes the QUIC connections, and the fallback if it is an
+// io.Closer.
+func (t *Transport) Close() error {
+	err := t.h3.Close()
+	if c, ok := t.fallback.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+package resilienthttp3
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+var proto = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.Proto))
+})
+
+func TestHTTP3(t *testing.T) {
+	// Borrow the httptest certificate for the QUIC server.
+	tlsSrv := httptest.NewTLSServer(proto)
+	defer tlsSrv.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	h3 := &http3.Server{Handler: proto, TLSConfig: &tls.Config{Certificates: tlsSrv.TLS.Certificates}}
+	go h3.Serve(conn)
+	defer h3.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsSrv.Certificate())
+	c := resilient.New(
+		resilient.WithBaseURL("https://"+conn.LocalAddr().String()),
+		resilient.WithRootCAs(roots),
+		resilient.WithRetry(0, 0),
+		WithHTTP3(),
+	)
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/")
+	if err != nil || string(body) != "HTTP/3.0" {
+		t.Fatalf("expected HTTP/3, got %q %v", body, err)
+	}
+}
+
+func TestHTTP3Fallback(t *testing.T) {
+	tlsSrv := httptest.NewTLSServer(proto)
+	defer tlsSrv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsSrv.Certificate())
+	c := resilient.New(
+		resilient.WithBaseURL(tlsSrv.URL),
+		resilient.WithRootCAs(roots),
+		resilient.WithRetry(0, 0),
+		WithHTTP3(WithHandshakeTimeout(200*time.Millisecond)),
+	)
+	defer c.Close()
+
+	// Nothing listens on UDP: the first request falls back, and the host
+	// then goes straight to the fallback.
+	for range 2 {
+		start := time.Now()
+		body, _, err := c.Get(context.Background(), "/")
+		if err != nil || string(body) != "HTTP/1.1" {
+			t.Fatalf("expected fallback to HTTP/1.1, got %q %v", body, err)
+		}
+		if time.Since(start) > 2*time.Second {
+			t.Fatalf("fallback took %v", time.Since(start))
+		}
+	}
+}
+
+func TestHTTP3FallbackOnlyWhenSafe(t *testing.T) {
+	// Fallback over TCP and QUIC on the same port; QUIC aborts every request
+	// after receiving it.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fallbacks atomic.Int32
+	tlsSrv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbacks.Add(1)
+		proto(w, r)
+	}))
+	tlsSrv.Listener = ln
+	tlsSrv.StartTLS()
+	defer tlsSrv.Close()
+
+	conn, err := net.ListenPacket("udp", ln.Addr().String())
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	h3 := &http3.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.ReadAll(r.Body)
+			panic(http.ErrAbortHandler)
+		}),
+		TLSConfig: &tls.Config{Certificates: tlsSrv.TLS.Certificates},
+	}
+	go h3.Serve(conn)
+	defer h3.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsSrv.Certificate())
+	c := resilient.New(
+		resilient.WithBaseURL(tlsSrv.URL),
+		resilient.WithRootCAs(roots),
+		resilient.WithRetry(0, 0),
+		WithHTTP3(),
+	)
+	defer c.Close()
+	ctx := context.Background()
+
+	if _, _, err := c.Post(ctx, "/", "text/plain", strings.NewReader("payment")); err == nil {
+		t.Fatal("expected the POST to fail without a fallback")
+	}
+	if n := fallbacks.Load(); n != 0 {
+		t.Fatalf("POST delivered over QUIC must not be re-sent, got %d fallback calls", n)
+	}
+
+	body, _, err := c.Get(ctx, "/")
+	if err != nil || string(body) != "HTTP/1.1" {
+		t.Fatalf("expected the GET to fall back, got %q %v", body, err)
+	}
+}
+
+func TestHTTP3KeepsTLSSettings(t *testing.T) {
+	tlsSrv := httptest.NewTLSServer(proto)
+	defer tlsSrv.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp unavailable: %v", err)
+	}
+	var served atomic.Int32
+	h3 := &http3.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served.Add(1)
+			proto(w, r)
+		}),
+		TLSConfig: &tls.Config{Certificates: tlsSrv.TLS.Certificates},
+	}
+	go h3.Serve(conn)
+	defer h3.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsSrv.Certificate())
+	get := func(pin string) (string, error) {
+		// WithDebugDump wraps the transport HTTP/3 falls back to.
+		c := resilient.New(
+			resilient.WithBaseURL("https://"+conn.LocalAddr().String()),
+			resilient.WithRootCAs(roots),
+			resilient.WithPinnedCertificates(pin),
+			resilient.WithDebugDump(io.Discard),
+			resilient.WithRetry(0, 0),
+			WithHTTP3(WithHandshakeTimeout(time.Second)),
+		)
+		defer c.Close()
+		body, _, err := c.Get(context.Background(), "/")
+		return string(body), err
+	}
+
+	if body, err := get(resilient.SPKIPin(tlsSrv.Certificate())); err != nil || body != "HTTP/3.0" {
+		t.Fatalf("expected HTTP/3 with the right pin, got %q %v", body, err)
+	}
+	if _, err := get("sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="); err == nil || served.Load() != 1 {
+		t.Fatalf("expected a wrong pin to be rejected over QUIC, got %v with %d requests served", err, served.Load())
+	}
+}
+
+func TestHTTP3RefusesProxyPool(t *testing.T) {
+	var served atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served.Add(1)
+	}))
+	defer srv.Close()
+
+	c := resilient.New(
+		resilient.WithBaseURL(srv.URL),
+		resilient.WithProxyPool([]string{"http://127.0.0.1:1"}, resilient.ProxyPerRequest),
+		resilient.WithRetry(0, 0),
+		WithHTTP3(),
+	)
+	defer c.Close()
+	if _, _, err := c.Get(context.Background(), "/"); !errors.Is(err, ErrProxied) || served.Load() != 0 {
+		t.Fatalf("expected ErrProxied, got %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+// Package resilientmsgpack provides a MessagePack codec for
+// resilient.Client.DoWith:
+//
+//	var out Event
+//	status, err := client.DoWith(ctx, resilientmsgpack.Codec, "POST", "/events", in, &out)
+//
+// It lives in its own package so the core package doesn't import a
+// MessagePack implementation.
+package resilientmsgpack
+
+import (
+	"github.com/egorkaBurkenya/resilient-go"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ContentType is the media type sent as Content-Type and Accept.
+const ContentType = "application/msgpack"
+
+// Codec encodes and decodes MessagePack bodies.
+var Codec resilient.Codec = codec{}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (codec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+func (codec) ContentType() string                { return ContentType }
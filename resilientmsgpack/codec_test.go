@@ -0,0 +1,46 @@
+package resilientmsgpack
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type event struct {
+	Name  string `msgpack:"name"`
+	Count int    `msgpack:"count"`
+}
+
+func TestCodec(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != ContentType {
+			t.Errorf("expected %s, got %q", ContentType, ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var in event
+		if err := msgpack.Unmarshal(body, &in); err != nil {
+			t.Errorf("server: %v", err)
+		}
+		in.Count++
+		out, _ := msgpack.Marshal(in)
+		w.Write(out)
+	}))
+	defer srv.Close()
+
+	c := resilient.New(resilient.WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var out event
+	status, err := c.DoWith(context.Background(), Codec, http.MethodPost, "/events", event{Name: "click", Count: 1}, &out)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("unexpected result: %d, %v", status, err)
+	}
+	if out != (event{Name: "click", Count: 2}) {
+		t.Fatalf("unexpected response: %+v", out)
+	}
+}
@@ -0,0 +1,99 @@
+// Package resilientotel records resilient.Client metrics with OpenTelemetry.
+//
+// Instruments are registered against a caller-provided MeterProvider and
+// attached to a client through their Option:
+//
+//	m, err := resilientotel.NewMetrics(provider, "github")
+//	if err != nil {
+//	    return err
+//	}
+//	client := resilient.New(
+//	    resilient.WithBaseURL("https://api.github.com"),
+//	    m.Option(),
+//	)
+//
+// Every measurement carries a "client.name" attribute with the given name.
+package resilientotel
+
+import (
+	"net/http"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ScopeName is the instrumentation scope used for the client's meter.
+const ScopeName = "github.com/egorkaBurkenya/resilient-go"
+
+// Metrics holds the OpenTelemetry instruments for a resilient.Client.
+type Metrics struct {
+	attrs metric.MeasurementOption
+
+	requests    metric.Int64Counter
+	errors      metric.Int64Counter
+	rateLimited metric.Int64Counter
+	retries     metric.Int64Counter
+	latency     metric.Float64Histogram
+}
+
+// NewMetrics creates the client instruments on a meter obtained from mp.
+func NewMetrics(mp metric.MeterProvider, name string) (*Metrics, error) {
+	meter := mp.Meter(ScopeName)
+	m := &Metrics{
+		attrs: metric.WithAttributeSet(attribute.NewSet(attribute.String("client.name", name))),
+	}
+
+	var err error
+	if m.requests, err = meter.Int64Counter("resilient.requests",
+		metric.WithDescription("Total number of requests executed by the client."),
+		metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if m.errors, err = meter.Int64Counter("resilient.errors",
+		metric.WithDescription("Total number of requests that ended in an error."),
+		metric.WithUnit("{request}")); err != nil {
+		return nil, err
+	}
+	if m.rateLimited, err = meter.Int64Counter("resilient.rate_limited",
+		metric.WithDescription("Total number of 429 responses received."),
+		metric.WithUnit("{response}")); err != nil {
+		return nil, err
+	}
+	if m.retries, err = meter.Int64Counter("resilient.retries",
+		metric.WithDescription("Total number of retry attempts."),
+		metric.WithUnit("{attempt}")); err != nil {
+		return nil, err
+	}
+	if m.latency, err = meter.Float64Histogram("resilient.request.duration",
+		metric.WithDescription("Request latency including retries and rate-limit waits."),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Option returns a resilient.Option that records the client's requests.
+func (m *Metrics) Option() resilient.Option {
+	return resilient.WithOnComplete(m.record)
+}
+
+func (m *Metrics) record(req *http.Request, res *resilient.Result, err error) {
+	ctx := req.Context()
+	m.requests.Add(ctx, 1, m.attrs)
+	if err != nil {
+		m.errors.Add(ctx, 1, m.attrs)
+	}
+	if res.Shared {
+		// The call that was joined already counted its attempts.
+		m.latency.Record(ctx, res.Duration.Seconds(), m.attrs)
+		return
+	}
+	if res.RateLimited > 0 {
+		m.rateLimited.Add(ctx, int64(res.RateLimited), m.attrs)
+	}
+	if res.Attempts > 1 {
+		m.retries.Add(ctx, int64(res.Attempts-1), m.attrs)
+	}
+	m.latency.Record(ctx, res.Duration.Seconds(), m.attrs)
+}
@@ -0,0 +1,174 @@
+package resilientotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMetrics(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(429)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	m, err := NewMetrics(provider, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := resilient.New(
+		resilient.WithBaseURL(srv.URL),
+		resilient.WithRetry(2, 10*time.Millisecond),
+		m.Option(),
+	)
+	defer c.Close()
+
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	var histCount uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, md := range sm.Metrics {
+			switch data := md.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					if v, ok := dp.Attributes.Value("client.name"); !ok || v.AsString() != "test" {
+						t.Fatalf("%s: missing client.name attribute", md.Name)
+					}
+					got[md.Name] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					histCount += dp.Count
+				}
+			}
+		}
+	}
+
+	want := map[string]int64{
+		"resilient.requests":     1,
+		"resilient.rate_limited": 1,
+		"resilient.retries":      1,
+	}
+	for name, v := range want {
+		if got[name] != v {
+			t.Fatalf("%s = %d, want %d", name, got[name], v)
+		}
+	}
+	if got["resilient.errors"] != 0 {
+		t.Fatalf("expected no errors, got %d", got["resilient.errors"])
+	}
+	if histCount != 1 {
+		t.Fatalf("expected 1 latency sample, got %d", histCount)
+	}
+}
+
+// sums collects the counters of reader by name.
+func sums(t *testing.T, reader sdkmetric.Reader) map[string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, md := range sm.Metrics {
+			if data, ok := md.Data.(metricdata.Sum[int64]); ok {
+				for _, dp := range data.DataPoints {
+					got[md.Name] += dp.Value
+				}
+			}
+		}
+	}
+	return got
+}
+
+func TestMetricsCountWhatCallersGet(t *testing.T) {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(429)
+			return
+		}
+		<-release
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	reader := sdkmetric.NewManualReader()
+	m, err := NewMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := resilient.New(
+		resilient.WithBaseURL(srv.URL),
+		resilient.WithRetry(1, time.Millisecond),
+		resilient.WithSingleflight(),
+		resilient.WithMemoize(time.Minute),
+		resilient.WithFallback(func(ctx context.Context, req *http.Request, last *resilient.Result, err error) ([]byte, int, error) {
+			return []byte("default"), http.StatusOK, nil
+		}),
+		m.Option(),
+	)
+	defer c.Close()
+	ctx := context.Background()
+
+	// Three callers share one call with a retry, a fourth is memoized and
+	// a fifth is rescued by the fallback.
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(func() {
+			if _, _, err := c.Get(ctx, "/ok"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	for _, path := range []string{"/ok", "/down"} {
+		if _, _, err := c.Get(ctx, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := sums(t, reader)
+	want := map[string]int64{
+		"resilient.requests":     5,
+		"resilient.errors":       0,
+		"resilient.rate_limited": 1,
+		"resilient.retries":      2, // one for /ok, one for /down
+	}
+	for name, v := range want {
+		if got[name] != v {
+			t.Fatalf("%s = %d, want %d", name, got[name], v)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package resilientprom exports resilient.Client metrics to Prometheus.
+//
+// A Collector is attached to a client through its Option and registered
+// with a Prometheus registry like any other collector:
+//
+//	col := resilientprom.NewCollector("github")
+//	client := resilient.New(
+//	    resilient.WithBaseURL("https://api.github.com"),
+//	    col.Option(),
+//	)
+//	prometheus.MustRegister(col)
+//
+// Every metric carries a "client" label with the collector's name, so
+// several clients can be exported side by side.
+package resilientprom
+
+import (
+	"net/http"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector records per-request metrics of a resilient.Client.
+type Collector struct {
+	requests    prometheus.Counter
+	errors      prometheus.Counter
+	rateLimited prometheus.Counter
+	retries     prometheus.Counter
+	latency     prometheus.Histogram
+}
+
+// Compile-time interface check.
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector creates a Collector whose metrics are labelled client=name.
+// Latency is recorded with prometheus.DefBuckets unless buckets are given.
+func NewCollector(name string, buckets ...float64) *Collector {
+	labels := prometheus.Labels{"client": name}
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	return &Collector{
+		requests: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "resilient_requests_total",
+			Help:        "Total number of requests executed by the client.",
+			ConstLabels: labels,
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "resilient_errors_total",
+			Help:        "Total number of requests that ended in an error.",
+			ConstLabels: labels,
+		}),
+		rateLimited: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "resilient_rate_limited_total",
+			Help:        "Total number of 429 responses received.",
+			ConstLabels: labels,
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "resilient_retries_total",
+			Help:        "Total number of retry attempts.",
+			ConstLabels: labels,
+		}),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:        "resilient_request_duration_seconds",
+			Help:        "Request latency including retries and rate-limit waits.",
+			ConstLabels: labels,
+			Buckets:     buckets,
+		}),
+	}
+}
+
+// Option returns a resilient.Option that feeds the client's requests into
+// the collector.
+func (c *Collector) Option() resilient.Option {
+	return resilient.WithOnComplete(c.observe)
+}
+
+func (c *Collector) observe(_ *http.Request, res *resilient.Result, err error) {
+	c.requests.Inc()
+	if err != nil {
+		c.errors.Inc()
+	}
+	if res.Shared {
+		// The call that was joined already counted its attempts.
+		c.latency.Observe(res.Duration.Seconds())
+		return
+	}
+	c.rateLimited.Add(float64(res.RateLimited))
+	if res.Attempts > 1 {
+		c.retries.Add(float64(res.Attempts - 1))
+	}
+	c.latency.Observe(res.Duration.Seconds())
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics() {
+		m.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.metrics() {
+		m.Collect(ch)
+	}
+}
+
+func (c *Collector) metrics() []prometheus.Collector {
+	return []prometheus.Collector{c.requests, c.errors, c.rateLimited, c.retries, c.latency}
+}
@@ -0,0 +1,108 @@
+package resilientprom
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(429)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	col := NewCollector("test")
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(col)
+
+	c := resilient.New(
+		resilient.WithBaseURL(srv.URL),
+		resilient.WithRetry(2, 10*time.Millisecond),
+		col.Option(),
+	)
+	defer c.Close()
+
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP resilient_rate_limited_total Total number of 429 responses received.
+# TYPE resilient_rate_limited_total counter
+resilient_rate_limited_total{client="test"} 1
+# HELP resilient_requests_total Total number of requests executed by the client.
+# TYPE resilient_requests_total counter
+resilient_requests_total{client="test"} 1
+# HELP resilient_retries_total Total number of retry attempts.
+# TYPE resilient_retries_total counter
+resilient_retries_total{client="test"} 1
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"resilient_requests_total", "resilient_rate_limited_total", "resilient_retries_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(col, "resilient_request_duration_seconds"); n != 1 {
+		t.Fatalf("expected latency histogram, got %d series", n)
+	}
+}
+
+func TestCollectorCountsWhatCallersGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	col := NewCollector("test")
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(col)
+
+	c := resilient.New(
+		resilient.WithBaseURL(srv.URL),
+		resilient.WithRetry(1, time.Millisecond),
+		resilient.WithMemoize(time.Minute),
+		resilient.WithFallback(func(ctx context.Context, req *http.Request, last *resilient.Result, err error) ([]byte, int, error) {
+			return []byte("default"), http.StatusOK, nil
+		}),
+		col.Option(),
+	)
+	defer c.Close()
+
+	// A fresh call, a memoized one and one rescued by the fallback.
+	for _, path := range []string{"/ok", "/ok", "/down"} {
+		if _, _, err := c.Get(context.Background(), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := `
+# HELP resilient_errors_total Total number of requests that ended in an error.
+# TYPE resilient_errors_total counter
+resilient_errors_total{client="test"} 0
+# HELP resilient_requests_total Total number of requests executed by the client.
+# TYPE resilient_requests_total counter
+resilient_requests_total{client="test"} 3
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "resilient_requests_total", "resilient_errors_total")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
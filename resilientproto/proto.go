@@ -0,0 +1,61 @@
+// Package resilientproto sends Protocol Buffers messages through a
+// resilient.Client, for gRPC-gateway and Twirp-style endpoints that accept
+// application/x-protobuf bodies.
+//
+//	var resp pb.GetUserResponse
+//	status, err := resilientproto.DoProto(ctx, client, "POST", "/twirp/users.Users/GetUser",
+//	    &pb.GetUserRequest{Id: 42}, &resp)
+//
+// It lives in its own package so that the core package doesn't import the
+// protobuf runtime, and programs that don't use it don't link it.
+package resilientproto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentType is the media type used for request and response bodies.
+const ContentType = "application/x-protobuf"
+
+// Codec encodes and decodes proto.Message values for
+// resilient.Client.DoWith. Values that are not proto.Message are rejected.
+var Codec resilient.Codec = codec{}
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("resilientproto: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("resilientproto: %T is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+func (codec) ContentType() string { return ContentType }
+
+// DoProto marshals reqMsg, sends it to baseURL+path through c, and unmarshals
+// the response into respMsg. reqMsg or respMsg may be nil to send or expect
+// no body. Retries, rate limiting and the rest of the client's pipeline
+// apply as for any other request.
+func DoProto(ctx context.Context, c *resilient.Client, method, path string, reqMsg, respMsg proto.Message, headers ...map[string]string) (int, error) {
+	var reqBody, respBody any
+	if reqMsg != nil {
+		reqBody = reqMsg
+	}
+	if respMsg != nil {
+		respBody = respMsg
+	}
+	return c.DoWith(ctx, Codec, method, path, reqBody, respBody, headers...)
+}
@@ -0,0 +1,57 @@
+package resilientproto
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestDo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != ContentType {
+			t.Errorf("expected %s, got %q", ContentType, ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var in wrapperspb.StringValue
+		if err := proto.Unmarshal(body, &in); err != nil {
+			t.Errorf("server: %v", err)
+		}
+		out, _ := proto.Marshal(wrapperspb.String("hello " + in.GetValue()))
+		w.Header().Set("Content-Type", ContentType)
+		w.Write(out)
+	}))
+	defer srv.Close()
+
+	c := resilient.New(resilient.WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var resp wrapperspb.StringValue
+	status, err := DoProto(context.Background(), c, http.MethodPost, "/greet", wrapperspb.String("proto"), &resp)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("unexpected result: %d, %v", status, err)
+	}
+	if resp.GetValue() != "hello proto" {
+		t.Fatalf("unexpected response: %q", resp.GetValue())
+	}
+}
+
+func TestDoBadResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte{0xff, 0xff, 0xff})
+	}))
+	defer srv.Close()
+
+	c := resilient.New(resilient.WithBaseURL(srv.URL))
+	defer c.Close()
+
+	var resp wrapperspb.StringValue
+	if _, err := DoProto(context.Background(), c, http.MethodGet, "/", nil, &resp); err == nil {
+		t.Fatal("expected an unmarshal error")
+	}
+}
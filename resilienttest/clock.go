@@ -0,0 +1,124 @@
+package resilienttest
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+// FakeClock is a resilient.Clock that only moves when told to, for tests of
+// backoff, cooldowns and Retry-After without real waiting. Install it with
+// resilient.WithClock. It is safe for concurrent use.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// fakeTimer is a pending After channel or AfterFunc call.
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	ch    chan time.Time
+	fn    func()
+}
+
+var _ resilient.Clock = (*FakeClock)(nil)
+
+// NewFakeClock returns a FakeClock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the clock's time once it has been
+// advanced by d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1)}
+	c.schedule(t, d)
+	return t.ch
+}
+
+// AfterFunc runs f in its own goroutine once the clock has been advanced
+// by d.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) resilient.Timer {
+	t := &fakeTimer{clock: c, fn: f}
+	c.schedule(t, d)
+	return t
+}
+
+func (c *FakeClock) schedule(t *fakeTimer, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.at = c.now.Add(d)
+	if d <= 0 {
+		t.fire(c.now)
+		return
+	}
+	c.waiters = append(c.waiters, t)
+	c.cond.Broadcast()
+}
+
+// Advance moves the clock forward by d, firing every timer that comes due,
+// in order.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	slices.SortStableFunc(c.waiters, func(a, b *fakeTimer) int { return a.at.Compare(b.at) })
+	n := 0
+	for n < len(c.waiters) && !c.waiters[n].at.After(c.now) {
+		c.waiters[n].fire(c.now)
+		n++
+	}
+	c.waiters = slices.Delete(c.waiters, 0, n)
+}
+
+// Waiters returns the number of pending timers.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n timers are pending, so a test can be
+// sure the client is waiting before it calls Advance.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+func (t *fakeTimer) fire(now time.Time) {
+	if t.fn != nil {
+		go t.fn()
+		return
+	}
+	t.ch <- now
+}
+
+// Stop cancels an AfterFunc call that has not run yet.
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := slices.Index(c.waiters, t)
+	if i < 0 {
+		return false
+	}
+	c.waiters = slices.Delete(c.waiters, i, i+1)
+	return true
+}
@@ -0,0 +1,93 @@
+package resilienttest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+func TestFakeClockBackoff(t *testing.T) {
+	clk := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := NewMock(On("GET", "/slow").
+		Reply(503, "").Header("Retry-After", "Wed, 01 Jan 2025 01:00:00 GMT").
+		Reply(200, "done"))
+	c := resilient.New(resilient.WithBaseURL(BaseURL), resilient.WithRetry(1, time.Millisecond),
+		resilient.WithClock(clk), m.Option())
+	defer c.Close()
+
+	done := make(chan string)
+	go func() {
+		body, _, _ := c.Get(context.Background(), "/slow")
+		done <- string(body)
+	}()
+
+	// The Retry-After date is an hour away on the fake clock.
+	clk.BlockUntil(1)
+	clk.Advance(59 * time.Minute)
+	select {
+	case <-done:
+		t.Fatal("expected the retry to wait for Retry-After")
+	case <-time.After(20 * time.Millisecond):
+	}
+	clk.Advance(time.Minute)
+	if body := <-done; body != "done" {
+		t.Fatalf("expected the retry to succeed, got %q", body)
+	}
+}
+
+func TestFakeClockCooldown(t *testing.T) {
+	clk := NewFakeClock(time.Now())
+	m := NewMock(On("GET", "/").Reply(429, "").Reply(200, ""))
+	c := resilient.New(resilient.WithBaseURL(BaseURL), resilient.WithRetry(1, 0), resilient.WithRateLimit(100, 1),
+		resilient.WithAdaptive(time.Hour), resilient.WithClock(clk), m.Option())
+	defer c.Close()
+	events := c.Events()
+
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	if clk.Waiters() != 1 {
+		t.Fatalf("expected a pending cooldown, got %d timers", clk.Waiters())
+	}
+	clk.Advance(time.Hour)
+	for ev := range events {
+		if ev.Type == resilient.EventRateRestored {
+			if ev.Rate != 100 {
+				t.Fatalf("expected rate restored to 100, got %v", ev.Rate)
+			}
+			return
+		}
+	}
+}
+
+func TestFakeClockMaxElapsedTime(t *testing.T) {
+	clk := NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	down := On("GET", "/").Reply(503, "").Header("Retry-After", "4")
+	m := NewMock(down)
+	c := resilient.New(resilient.WithBaseURL(BaseURL), resilient.WithRetry(5, time.Millisecond),
+		resilient.WithMaxElapsedTime(10*time.Second), resilient.WithClock(clk), m.Option())
+	defer c.Close()
+
+	errc := make(chan error)
+	go func() {
+		_, _, err := c.Get(context.Background(), "/")
+		errc <- err
+	}()
+
+	// Two 4s waits fit the 10s budget on the fake clock; a third doesn't.
+	for range 2 {
+		clk.BlockUntil(1)
+		clk.Advance(4 * time.Second)
+	}
+	select {
+	case err := <-errc:
+		if !errors.Is(err, resilient.ErrMaxElapsedTime) || down.Calls() != 3 {
+			t.Fatalf("expected ErrMaxElapsedTime after 3 attempts, got %v after %d", err, down.Calls())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the budget to run out on the fake clock")
+	}
+}
@@ -0,0 +1,221 @@
+package resilienttest
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+// BaseURL is the base URL of clients returned by NewClient.
+const BaseURL = "http://resilienttest.invalid"
+
+// step is one scripted outcome of a Responder.
+type step struct {
+	status int
+	body   string
+	header http.Header
+	err    error
+}
+
+// Responder scripts the responses to matching requests. Each matching call
+// consumes the next reply added with Reply or Fail; once they run out, the
+// last one repeats. Build one with On.
+type Responder struct {
+	method string
+	path   string
+	match  func(*http.Request) bool
+	steps  []step
+
+	mu    sync.Mutex
+	calls int
+}
+
+// On returns a Responder for requests with the given method and path. An
+// empty method matches any method. path is compared with the request's URL
+// path, or with scheme, host and path when it contains "://".
+func On(method, path string) *Responder {
+	return &Responder{method: method, path: path}
+}
+
+// Match adds a condition on the request, e.g. on a header or query value.
+func (r *Responder) Match(fn func(*http.Request) bool) *Responder {
+	r.match = fn
+	return r
+}
+
+// Reply adds a response with the given status and body.
+func (r *Responder) Reply(status int, body string) *Responder {
+	r.steps = append(r.steps, step{status: status, body: body, header: http.Header{}})
+	return r
+}
+
+// Header sets a header on the response added last with Reply.
+func (r *Responder) Header(key, value string) *Responder {
+	if n := len(r.steps); n > 0 && r.steps[n-1].header != nil {
+		r.steps[n-1].header.Set(key, value)
+	}
+	return r
+}
+
+// Fail adds a transport error, as if the connection had failed.
+func (r *Responder) Fail(err error) *Responder {
+	r.steps = append(r.steps, step{err: err})
+	return r
+}
+
+// Calls returns the number of requests the Responder has answered.
+func (r *Responder) Calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+func (r *Responder) matches(req *http.Request) bool {
+	if r.method != "" && r.method != req.Method {
+		return false
+	}
+	if r.path != "" {
+		u := *req.URL
+		u.RawQuery, u.Fragment = "", ""
+		target := u.Path
+		if strings.Contains(r.path, "://") {
+			target = u.String()
+		}
+		if target != r.path {
+			return false
+		}
+	}
+	return r.match == nil || r.match(req)
+}
+
+// next returns the outcome of the next call.
+func (r *Responder) next() step {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := step{status: http.StatusOK, header: http.Header{}}
+	if len(r.steps) > 0 {
+		s = r.steps[min(r.calls, len(r.steps)-1)]
+	}
+	r.calls++
+	return s
+}
+
+// Call is a request received by a Mock.
+type Call struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// Mock is an http.RoundTripper that answers requests from Responders
+// instead of the network. Requests no Responder matches fail with an error.
+// It is safe for concurrent use.
+type Mock struct {
+	responders []*Responder
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewMock returns a Mock answering with responders, tried in order.
+func NewMock(responders ...*Responder) *Mock {
+	return &Mock{responders: responders}
+}
+
+// Option makes a client send its requests to the Mock. It is built on
+// resilient.WithHTTPClient, so WithTimeout has no effect on that client.
+func (m *Mock) Option() resilient.Option {
+	return resilient.WithHTTPClient(&http.Client{Transport: m})
+}
+
+// RoundTrip implements http.RoundTripper.
+func (m *Mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: body})
+	m.mu.Unlock()
+
+	for _, r := range m.responders {
+		if !r.matches(req) {
+			continue
+		}
+		s := r.next()
+		if s.err != nil {
+			return nil, s.err
+		}
+		return &http.Response{
+			Status:        strconv.Itoa(s.status) + " " + http.StatusText(s.status),
+			StatusCode:    s.status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        s.header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(s.body))),
+			ContentLength: int64(len(s.body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("resilienttest: no responder for %s %s", req.Method, req.URL)
+}
+
+// Calls returns the requests received so far, in order.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// AssertCalled fails t unless the Mock received exactly want requests with
+// the given method and path, matched as in On.
+func (m *Mock) AssertCalled(t testing.TB, method, path string, want int) {
+	t.Helper()
+	r := On(method, path)
+	got := 0
+	for _, c := range m.Calls() {
+		req, err := http.NewRequest(c.Method, c.URL, nil)
+		if err == nil && r.matches(req) {
+			got++
+		}
+	}
+	if got != want {
+		t.Errorf("resilienttest: expected %d calls to %s %s, got %d", want, method, path, got)
+	}
+}
+
+// AssertExpectations fails t for every Responder that answered no request.
+func (m *Mock) AssertExpectations(t testing.TB) {
+	t.Helper()
+	for _, r := range m.responders {
+		if r.Calls() == 0 {
+			t.Errorf("resilienttest: no call matched %s %s", cmp.Or(r.method, "*"), cmp.Or(r.path, "*"))
+		}
+	}
+}
+
+// Client is a resilient.Client wired to a Mock.
+type Client struct {
+	*resilient.Client
+	Mock *Mock
+}
+
+// NewClient returns a client whose requests are answered by responders.
+// Its base URL is BaseURL and it retries up to three times without
+// backoff, so tests of retry behavior run instantly. For other settings,
+// pass a Mock's Option to resilient.New instead.
+func NewClient(responders ...*Responder) *Client {
+	m := NewMock(responders...)
+	c := resilient.New(resilient.WithBaseURL(BaseURL), resilient.WithRetry(3, 0), m.Option())
+	return &Client{Client: c, Mock: m}
+}
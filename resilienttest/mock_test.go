@@ -0,0 +1,71 @@
+package resilienttest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMockSequence(t *testing.T) {
+	c := NewClient(
+		On("GET", "/users/1").Reply(503, "").Reply(200, `{"id":1}`).Header("Content-Type", "application/json"),
+		On("POST", "/users").Match(func(r *http.Request) bool {
+			return r.Header.Get("Idempotency-Key") != ""
+		}).Reply(201, "created"),
+	)
+	defer c.Close()
+
+	body, status, err := c.Get(context.Background(), "/users/1")
+	if err != nil || status != 200 || string(body) != `{"id":1}` {
+		t.Fatalf("expected retry into the second reply, got %d %q %v", status, body, err)
+	}
+	body, status, err = c.Post(context.Background(), "/users", "text/plain", strings.NewReader("x"),
+		map[string]string{"Idempotency-Key": "k1"})
+	if err != nil || status != 201 || string(body) != "created" {
+		t.Fatalf("expected created, got %d %q %v", status, body, err)
+	}
+
+	c.Mock.AssertCalled(t, "GET", "/users/1", 2)
+	c.Mock.AssertCalled(t, "POST", "/users", 1)
+	c.Mock.AssertExpectations(t)
+	if calls := c.Mock.Calls(); string(calls[2].Body) != "x" {
+		t.Fatalf("expected the POST body recorded, got %+v", calls[2])
+	}
+}
+
+func TestMockErrors(t *testing.T) {
+	reset := errors.New("connection reset by peer")
+	c := NewClient(On("", BaseURL+"/flaky").Fail(reset))
+	defer c.Close()
+
+	if _, _, err := c.Get(context.Background(), "/flaky"); !errors.Is(err, reset) {
+		t.Fatalf("expected the injected error, got %v", err)
+	}
+	c.Mock.AssertCalled(t, "GET", "/flaky", 4) // first attempt and three retries
+
+	if _, _, err := c.Get(context.Background(), "/unknown"); err == nil || !strings.Contains(err.Error(), "no responder") {
+		t.Fatalf("expected unmatched requests to fail, got %v", err)
+	}
+}
+
+func TestMockAssertionsFail(t *testing.T) {
+	m := NewMock(On("GET", "/never").Reply(200, ""))
+	ft := &fakeT{TB: t}
+	m.AssertExpectations(ft)
+	m.AssertCalled(ft, "GET", "/never", 1)
+	if ft.errors != 2 {
+		t.Fatalf("expected 2 assertion failures, got %d", ft.errors)
+	}
+}
+
+// fakeT counts failures instead of failing the test.
+type fakeT struct {
+	testing.TB
+	errors int
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(string, ...any) { f.errors++ }
@@ -0,0 +1,330 @@
+// Package resilienttest provides test helpers for code built on
+// resilient.Client.
+//
+// NewClient returns a client answered by scripted Responders, with no
+// server involved:
+//
+//	c := resilienttest.NewClient(
+//		resilienttest.On("GET", "/users/1").Reply(503, "").Reply(200, `{"id":1}`),
+//	)
+//	defer c.Close()
+//	// ... exercise code using c.Client ...
+//	c.Mock.AssertCalled(t, "GET", "/users/1", 2)
+//
+// A Recorder records real HTTP interactions to a cassette file and replays
+// them later without network access, so integration tests become hermetic:
+//
+//	rec, err := resilienttest.NewRecorder("testdata/users.json", resilienttest.ModeAuto)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	t.Cleanup(func() { rec.Save() })
+//	client := resilient.New(resilient.WithBaseURL("https://api.example.com"), rec.Option())
+package resilienttest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+// Mode selects whether a Recorder talks to the network.
+type Mode int
+
+const (
+	// ModeReplay serves every request from the cassette and fails those it
+	// has no recording for. The cassette must exist.
+	ModeReplay Mode = iota
+	// ModeRecord sends every request upstream and records it, replacing
+	// the cassette on Save.
+	ModeRecord
+	// ModeAuto replays an existing cassette, or records a new one when the
+	// file does not exist yet.
+	ModeAuto
+)
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the stored form of a request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitzero"`
+}
+
+// RecordedResponse is the stored form of a response.
+type RecordedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitzero"`
+}
+
+// Body is a recorded body. It is stored as text when it is valid UTF-8 and
+// as base64 otherwise, so cassettes stay readable and editable by hand.
+type Body []byte
+
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+	var enc struct{ Base64 string }
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(enc.Base64)
+	*b = raw
+	return err
+}
+
+// cassette is the on-disk format.
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Matcher reports whether a recorded request answers req. body is req's
+// body, already read.
+type Matcher func(req *http.Request, body []byte, rec RecordedRequest) bool
+
+// MatchMethodURL matches requests with the same method and full URL. It is
+// the default Matcher.
+func MatchMethodURL(req *http.Request, body []byte, rec RecordedRequest) bool {
+	return req.Method == rec.Method && req.URL.String() == rec.URL
+}
+
+// MatchBody matches requests like MatchMethodURL that also carry the same
+// body.
+func MatchBody(req *http.Request, body []byte, rec RecordedRequest) bool {
+	return MatchMethodURL(req, body, rec) && bytes.Equal(body, rec.Body)
+}
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithMatcher sets how requests are matched to recordings during replay.
+func WithMatcher(m Matcher) RecorderOption {
+	return func(r *Recorder) { r.match = m }
+}
+
+// WithRedactedHeaders adds request headers whose values are replaced with
+// "[REDACTED]" in the cassette. Authorization, Proxy-Authorization, Cookie
+// and X-Api-Key are always redacted.
+func WithRedactedHeaders(names ...string) RecorderOption {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.redact[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+}
+
+// Recorder is an http.RoundTripper that records or replays interactions.
+// Install it with Option. It is safe for concurrent use.
+type Recorder struct {
+	path   string
+	record bool
+	match  Matcher
+	redact map[string]bool
+	next   http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder returns a Recorder for the cassette at path. In replay mode
+// the cassette is loaded immediately.
+func NewRecorder(path string, mode Mode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		path:  path,
+		match: MatchMethodURL,
+		redact: map[string]bool{
+			"Authorization":       true,
+			"Proxy-Authorization": true,
+			"Cookie":              true,
+			"X-Api-Key":           true,
+		},
+		next: http.DefaultTransport,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case mode == ModeRecord:
+		r.record = true
+	case mode == ModeAuto && errors.Is(err, fs.ErrNotExist):
+		r.record = true
+	case err != nil:
+		return nil, fmt.Errorf("resilienttest: load cassette: %w", err)
+	default:
+		var c cassette
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("resilienttest: parse cassette %s: %w", path, err)
+		}
+		r.interactions = c.Interactions
+		r.used = make([]bool, len(c.Interactions))
+	}
+	return r, nil
+}
+
+// Option installs the Recorder as the client's innermost transport layer,
+// so retries, hedges and auth refreshes are each recorded as they happen.
+func (r *Recorder) Option() resilient.Option {
+	return resilient.WithTransportMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		r.next = next
+		return r
+	})
+}
+
+// Recording reports whether the Recorder sends requests upstream.
+func (r *Recorder) Recording() bool { return r.record }
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.record {
+		return r.recordTrip(req, body)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if !r.used[i] && r.match(req, body, in.Request) {
+			r.used[i] = true
+			return in.Response.toResponse(req), nil
+		}
+	}
+	return nil, fmt.Errorf("resilienttest: no recorded interaction for %s %s", req.Method, req.URL)
+}
+
+// recordTrip sends req upstream and appends the interaction.
+func (r *Recorder) recordTrip(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := req.Header.Clone()
+	for name, values := range header {
+		if r.redact[name] {
+			for i := range values {
+				values[i] = "[REDACTED]"
+			}
+		}
+	}
+	in := Interaction{
+		Request:  RecordedRequest{Method: req.Method, URL: req.URL.String(), Header: header, Body: body},
+		Response: RecordedResponse{StatusCode: resp.StatusCode, Header: resp.Header.Clone(), Body: respBody},
+	}
+	r.mu.Lock()
+	r.interactions = append(r.interactions, in)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the cassette, creating its
+// directory if needed. It does nothing when replaying.
+func (r *Recorder) Save() error {
+	if !r.record {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("resilienttest: encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("resilienttest: save cassette: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("resilienttest: save cassette: %w", err)
+	}
+	return nil
+}
+
+// Unused returns the recorded interactions that have not been replayed,
+// for asserting that a test made every expected call.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []Interaction
+	for i, in := range r.interactions {
+		if !r.record && !r.used[i] {
+			out = append(out, in)
+		}
+	}
+	return out
+}
+
+// toResponse builds the replayed response for req.
+func (rr RecordedResponse) toResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(rr.StatusCode) + " " + http.StatusText(rr.StatusCode),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rr.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(rr.Body)),
+		ContentLength: int64(len(rr.Body)),
+		Request:       req,
+	}
+}
+
+// readRequestBody returns req's body, leaving req readable again.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
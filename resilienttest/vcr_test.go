@@ -0,0 +1,84 @@
+package resilienttest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+func TestRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Seen", r.Method+" "+r.URL.Path)
+		w.Write(append([]byte("got:"), body...))
+	}))
+	path := filepath.Join(t.TempDir(), "cassettes", "echo.json")
+
+	run := func(mode Mode) *Recorder {
+		t.Helper()
+		rec, err := NewRecorder(path, mode, WithMatcher(MatchBody))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := resilient.New(resilient.WithBaseURL(srv.URL), resilient.WithBearerToken("secret"), rec.Option())
+		defer c.Close()
+		for _, body := range []string{"one", "two"} {
+			got, _, err := c.Post(context.Background(), "/echo", "text/plain", strings.NewReader(body))
+			if err != nil || string(got) != "got:"+body {
+				t.Fatalf("expected echo of %q, got %q %v", body, got, err)
+			}
+		}
+		bin, _, err := c.Post(context.Background(), "/echo", "application/octet-stream", strings.NewReader("\xff\x00"))
+		if err != nil || string(bin) != "got:\xff\x00" {
+			t.Fatalf("expected binary echo, got %q %v", bin, err)
+		}
+		return rec
+	}
+
+	rec := run(ModeAuto)
+	if !rec.Recording() {
+		t.Fatal("expected ModeAuto to record without a cassette")
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret") {
+		t.Fatalf("cassette leaked the token:\n%s", data)
+	}
+
+	srv.Close() // replay must not touch the network
+	rec = run(ModeAuto)
+	if rec.Recording() {
+		t.Fatal("expected ModeAuto to replay an existing cassette")
+	}
+	if n := len(rec.Unused()); n != 0 {
+		t.Fatalf("expected every interaction replayed, %d left", n)
+	}
+}
+
+func TestReplayUnknownRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.json")
+	os.WriteFile(path, []byte(`{"interactions":[]}`), 0o644)
+
+	rec, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := resilient.New(resilient.WithBaseURL("http://example.invalid"), resilient.WithRetry(0, 0), rec.Option())
+	defer c.Close()
+	if _, _, err := c.Get(context.Background(), "/nope"); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Fatalf("expected a missing-recording error, got %v", err)
+	}
+
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay); err == nil {
+		t.Fatal("expected ModeReplay to require a cassette")
+	}
+}
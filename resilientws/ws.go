@@ -0,0 +1,228 @@
+// Package resilientws dials WebSockets through a resilient.Client and keeps
+// them connected:
+//
+//	conn, err := resilientws.DialWS(ctx, client, "/stream",
+//	    resilientws.WithOnReconnect(func(attempt int, err error) {
+//	        log.Printf("reconnected after %d attempts: %v", attempt, err)
+//	    }),
+//	)
+//	defer conn.Close()
+//	for {
+//	    typ, msg, err := conn.Read(ctx)
+//	    ...
+//	}
+//
+// The upgrade request uses the client's base URL, default headers, rate
+// limiters and hooks, so authentication set up there applies as well. When
+// the connection drops, Read and Write redial with the client's backoff
+// settings. Messages in flight when the connection drops may be lost.
+//
+// It lives in its own package so the core package doesn't import a
+// WebSocket library.
+package resilientws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+// ErrClosed is returned by Read and Write after Close.
+var ErrClosed = errors.New("resilientws: connection closed")
+
+// Option configures DialWS.
+type Option func(*config)
+
+type config struct {
+	headers       map[string]string
+	subprotocols  []string
+	maxReconnects int
+	onReconnect   func(attempt int, err error)
+}
+
+// WithHeaders adds headers to the upgrade request, on top of the client's
+// default headers. Keys may also be resilient.PathParam values.
+func WithHeaders(h map[string]string) Option {
+	return func(c *config) { c.headers = h }
+}
+
+// WithSubprotocols sets the subprotocols offered during the handshake.
+func WithSubprotocols(protocols ...string) Option {
+	return func(c *config) { c.subprotocols = protocols }
+}
+
+// WithMaxReconnects bounds the redial attempts after each drop. 0 (the
+// default) retries until the context passed to Read or Write is done.
+func WithMaxReconnects(n int) Option {
+	return func(c *config) { c.maxReconnects = n }
+}
+
+// WithOnReconnect registers fn to run after the connection is
+// re-established, with the number of attempts it took and the error that
+// dropped the previous connection.
+func WithOnReconnect(fn func(attempt int, err error)) Option {
+	return func(c *config) { c.onReconnect = fn }
+}
+
+// Conn is a WebSocket connection that redials when it drops. It is safe to
+// call Read and Write concurrently.
+type Conn struct {
+	client *resilient.Client
+	path   string
+	cfg    config
+
+	redial sync.Mutex    // serializes reconnects
+	done   chan struct{} // closed by Close
+
+	mu     sync.Mutex
+	ws     *websocket.Conn
+	closed bool
+}
+
+// DialWS opens a WebSocket to baseURL+path through c. path may use http(s)
+// or ws(s) schemes when absolute.
+func DialWS(ctx context.Context, c *resilient.Client, path string, opts ...Option) (*Conn, error) {
+	conn := &Conn{client: c, path: path, done: make(chan struct{})}
+	for _, o := range opts {
+		o(&conn.cfg)
+	}
+	ws, err := conn.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn.ws = ws
+	return conn, nil
+}
+
+func (c *Conn) dial(ctx context.Context) (*websocket.Conn, error) {
+	req, err := c.client.NewRequest(ctx, http.MethodGet, c.path, nil, c.cfg.headers)
+	if err != nil {
+		return nil, err
+	}
+	ws, _, err := websocket.Dial(ctx, req.URL.String(), &websocket.DialOptions{
+		HTTPClient:   &http.Client{Transport: c.client.Transport()},
+		HTTPHeader:   req.Header,
+		Subprotocols: c.cfg.subprotocols,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("resilientws: dial: %w", err)
+	}
+	return ws, nil
+}
+
+// Read reads the next message, redialing if the connection has dropped.
+func (c *Conn) Read(ctx context.Context) (websocket.MessageType, []byte, error) {
+	for {
+		ws, err := c.current()
+		if err != nil {
+			return 0, nil, err
+		}
+		typ, data, err := ws.Read(ctx)
+		if err == nil {
+			return typ, data, nil
+		}
+		if err := c.recover(ctx, ws, err); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// Write writes a message, redialing and sending it again if the connection
+// has dropped.
+func (c *Conn) Write(ctx context.Context, typ websocket.MessageType, data []byte) error {
+	for {
+		ws, err := c.current()
+		if err != nil {
+			return err
+		}
+		err = ws.Write(ctx, typ, data)
+		if err == nil {
+			return nil
+		}
+		if err := c.recover(ctx, ws, err); err != nil {
+			return err
+		}
+	}
+}
+
+// Subprotocol returns the subprotocol negotiated for the current connection.
+func (c *Conn) Subprotocol() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws.Subprotocol()
+}
+
+// Close closes the connection with a normal closure and stops redialing.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	return c.ws.Close(websocket.StatusNormalClosure, "")
+}
+
+func (c *Conn) current() (*websocket.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	return c.ws, nil
+}
+
+// recover decides whether cause, returned by ws, warrants a redial and
+// performs it. A nil return means the caller should try again.
+func (c *Conn) recover(ctx context.Context, ws *websocket.Conn, cause error) error {
+	if ctx.Err() != nil {
+		return cause
+	}
+	if websocket.CloseStatus(cause) == websocket.StatusNormalClosure {
+		return cause
+	}
+
+	c.redial.Lock()
+	defer c.redial.Unlock()
+	if current, err := c.current(); err != nil {
+		return err
+	} else if current != ws {
+		// Another goroutine already redialed.
+		return nil
+	}
+	ws.CloseNow()
+
+	for attempt := 1; c.cfg.maxReconnects == 0 || attempt <= c.cfg.maxReconnects; attempt++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.done:
+			return ErrClosed
+		case <-time.After(c.client.Backoff(attempt)):
+		}
+		next, err := c.dial(ctx)
+		if err != nil {
+			continue
+		}
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			next.CloseNow()
+			return ErrClosed
+		}
+		c.ws = next
+		c.mu.Unlock()
+		if c.cfg.onReconnect != nil {
+			c.cfg.onReconnect(attempt, cause)
+		}
+		return nil
+	}
+	return fmt.Errorf("resilientws: reconnect failed after %d attempts: %w", c.cfg.maxReconnects, cause)
+}
@@ -0,0 +1,130 @@
+package resilientws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+func TestDialAndReconnect(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ws, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		n := conns.Add(1)
+		ws.Write(r.Context(), websocket.MessageText, []byte{byte('0' + n)})
+		if n == 1 {
+			// Drop the first connection without a close frame.
+			ws.CloseNow()
+			return
+		}
+		ws.Close(websocket.StatusNormalClosure, "")
+	}))
+	defer srv.Close()
+
+	c := resilient.New(
+		resilient.WithBaseURL(srv.URL),
+		resilient.WithHeader("Authorization", "Bearer token"),
+		resilient.WithRetry(3, time.Millisecond),
+	)
+	defer c.Close()
+
+	var reconnects atomic.Int32
+	ctx := context.Background()
+	conn, err := DialWS(ctx, c, "/ws", WithOnReconnect(func(attempt int, err error) {
+		reconnects.Add(1)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, want := range []string{"1", "2"} {
+		_, msg, err := conn.Read(ctx)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if string(msg) != want {
+			t.Fatalf("expected %q, got %q", want, msg)
+		}
+	}
+	if reconnects.Load() != 1 {
+		t.Fatalf("expected one reconnect, got %d", reconnects.Load())
+	}
+
+	// A normal closure from the server ends the stream instead of redialing.
+	if _, _, err := conn.Read(ctx); websocket.CloseStatus(err) != websocket.StatusNormalClosure {
+		t.Fatalf("expected normal closure, got %v", err)
+	}
+	if s := c.Stats(); s.TotalRequests != 2 {
+		t.Fatalf("expected two upgrade requests counted, got %d", s.TotalRequests)
+	}
+}
+
+func TestMaxReconnects(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conns.Add(1) > 1 {
+			http.Error(w, "gone", http.StatusServiceUnavailable)
+			return
+		}
+		ws, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		ws.CloseNow()
+	}))
+	defer srv.Close()
+
+	c := resilient.New(resilient.WithBaseURL(srv.URL), resilient.WithRetry(0, time.Millisecond))
+	defer c.Close()
+
+	conn, err := DialWS(context.Background(), c, "/", WithMaxReconnects(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, _, err := conn.Read(context.Background()); err == nil {
+		t.Fatal("expected an error once reconnects are exhausted")
+	}
+	if n := conns.Load(); n != 3 {
+		t.Fatalf("expected the dial plus 2 redials, got %d", n)
+	}
+}
+
+func TestClose(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		ws.Read(r.Context())
+	}))
+	defer srv.Close()
+
+	c := resilient.New(resilient.WithBaseURL(srv.URL))
+	defer c.Close()
+
+	conn, err := DialWS(context.Background(), c, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if err := conn.Write(context.Background(), websocket.MessageText, []byte("x")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
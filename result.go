@@ -0,0 +1,55 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Result is the detailed outcome of a request executed with DoResult.
+type Result struct {
+	Body       []byte
+	StatusCode int
+	Header     http.Header
+
+	// Attempts is the number of attempts made, including the first one.
+	Attempts int
+
+	// Cached reports whether the response was served from the cache
+	// configured with WithCache, with or without revalidation.
+	Cached bool
+
+	// Fallback reports whether Body and StatusCode were supplied by the
+	// WithFallback function after the request failed.
+	Fallback bool
+
+	// Shared reports whether the call joined an identical one already in
+	// flight (WithSingleflight) and got a copy of its result. Attempts and
+	// RateLimited then describe the upstream work done for both.
+	Shared bool
+
+	// Redirects lists the redirects followed by the final attempt, oldest
+	// first. The final URL is not included; it is where the last redirect
+	// pointed.
+	Redirects []Redirect
+
+	// RateLimited is the number of attempts answered with 429 Too Many Requests.
+	RateLimited int
+
+	// Duration is the total time spent in the call, including rate-limit
+	// waits and backoff between retries.
+	Duration time.Duration
+
+	// Err is the error of this request in batch calls such as BatchGet,
+	// which return one Result per request. DoResult leaves it nil and
+	// returns the error separately.
+	Err error
+}
+
+// DoResult executes req like Do, but returns a Result that also carries the
+// response headers, the number of attempts, and the total duration.
+// The Result is non-nil even when an error is returned, so the status code
+// and attempt count of a failed call can still be inspected.
+func (c *Client) DoResult(ctx context.Context, req *http.Request) (*Result, error) {
+	return c.do(ctx, req)
+}
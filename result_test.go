@@ -0,0 +1,95 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoResult(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(503)
+			return
+		}
+		w.Header().Set("X-Request-Id", "abc")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithRetry(2, 10*time.Millisecond))
+	defer c.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	res, err := c.DoResult(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 200 || string(res.Body) != "ok" {
+		t.Fatalf("unexpected: status=%d body=%s", res.StatusCode, res.Body)
+	}
+	if got := res.Header.Get("X-Request-Id"); got != "abc" {
+		t.Fatalf("expected header abc, got %q", got)
+	}
+	if res.Attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", res.Attempts)
+	}
+	if res.Duration <= 0 {
+		t.Fatalf("expected positive duration, got %v", res.Duration)
+	}
+}
+
+func TestDoResultOnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(404)
+		w.Write([]byte("missing"))
+	}))
+	defer srv.Close()
+
+	c := New()
+	defer c.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	res, err := c.DoResult(context.Background(), req)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if res == nil || res.StatusCode != 404 || string(res.Body) != "missing" || res.Attempts != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestOnComplete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(429)
+	}))
+	defer srv.Close()
+
+	var calls atomic.Int32
+	var last *Result
+	c := New(
+		WithBaseURL(srv.URL),
+		WithRetry(1, 10*time.Millisecond),
+		WithOnComplete(func(req *http.Request, res *Result, err error) { calls.Add(1) }),
+		WithOnComplete(func(req *http.Request, res *Result, err error) {
+			if err == nil {
+				t.Error("expected error")
+			}
+			last = res
+		}),
+	)
+	defer c.Close()
+
+	c.Get(context.Background(), "/")
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 call, got %d", calls.Load())
+	}
+	if last == nil || last.Attempts != 2 || last.RateLimited != 2 {
+		t.Fatalf("unexpected result: %+v", last)
+	}
+}
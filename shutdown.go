@@ -0,0 +1,127 @@
+package resilient
+
+import (
+	"context"
+	"sync"
+)
+
+// inflightSet tracks the calls running in execute, so that Shutdown can
+// wait for them and abort the stragglers.
+type inflightSet struct {
+	mu       sync.Mutex
+	n        int
+	draining bool
+	idle     chan struct{} // closed once n reaches 0 while draining
+
+	abort  context.Context // canceled to abort the current calls
+	cancel context.CancelFunc
+}
+
+func newInflightSet() *inflightSet {
+	s := &inflightSet{}
+	s.abort, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+// add registers a call, returning a context canceled when the set is
+// aborted and a function to call when the call completes. It fails with
+// ErrClientClosed once the set is draining.
+func (s *inflightSet) add(ctx context.Context) (context.Context, func(), error) {
+	s.mu.Lock()
+	if s.draining {
+		s.mu.Unlock()
+		return ctx, nil, ErrClientClosed
+	}
+	s.n++
+	abort := s.abort
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(abort, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+		s.mu.Lock()
+		s.n--
+		if s.n == 0 && s.idle != nil {
+			close(s.idle)
+			s.idle = nil
+		}
+		s.mu.Unlock()
+	}, nil
+}
+
+// cancelAll aborts the calls in flight; later calls are not affected.
+func (s *inflightSet) cancelAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancel()
+	s.abort, s.cancel = context.WithCancel(context.Background())
+}
+
+// len returns the number of calls in flight.
+func (s *inflightSet) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.n
+}
+
+// closing reports whether the set is draining.
+func (s *inflightSet) closing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draining
+}
+
+// drain stops new calls and returns a channel closed once none are left.
+func (s *inflightSet) drain() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.draining = true
+	idle := make(chan struct{})
+	if s.n == 0 {
+		close(idle)
+	} else if s.idle != nil {
+		idle = s.idle
+	} else {
+		s.idle = idle
+	}
+	return idle
+}
+
+// Shutdown closes the client gracefully: new calls fail with
+// ErrClientClosed right away, calls in flight may finish until ctx is done,
+// and those still running then are canceled. Shutdown waits for them to
+// return before releasing the client's resources as Close does, and
+// reports ctx's error if it had to cancel any. Clients derived with With
+// are drained together with their parent; Shutdown on a derived client
+// does nothing.
+func (c *Client) Shutdown(ctx context.Context) error {
+	if c.derived {
+		return nil
+	}
+	idle := c.inflight.drain()
+	var err error
+	select {
+	case <-idle:
+	case <-ctx.Done():
+		err = ctx.Err()
+		c.inflight.cancelAll()
+		<-idle
+	}
+	c.Close()
+	return err
+}
+
+// InFlight returns the number of calls currently running, including those
+// of clients derived with With.
+func (c *Client) InFlight() int {
+	return c.inflight.len()
+}
+
+// CancelAll cancels every call currently running, including those of
+// clients derived with With; they fail with context.Canceled. The client
+// stays usable for new calls.
+func (c *Client) CancelAll() {
+	c.inflight.cancelAll()
+}
@@ -0,0 +1,117 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForInFlight(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.Write([]byte("done"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(0, 0))
+	result := make(chan error, 1)
+	go func() {
+		_, _, err := c.Get(context.Background(), "/")
+		result <- err
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- c.Shutdown(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	if _, _, err := c.Get(context.Background(), "/"); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("expected ErrClientClosed for a new call, got %v", err)
+	}
+	select {
+	case <-shutdown:
+		t.Fatal("expected Shutdown to wait for the call in flight")
+	default:
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Fatalf("expected the call in flight to complete, got %v", err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShutdownCancelsAfterDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(0, 0))
+	result := make(chan error, 1)
+	go func() {
+		_, _, err := c.Get(context.Background(), "/")
+		result <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline error, got %v", err)
+	}
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the call to be canceled, got %v", err)
+	}
+	if !c.closed {
+		t.Fatal("expected Shutdown to close the client")
+	}
+}
+
+func TestCancelAll(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hang" {
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(0, 0))
+	defer c.Close()
+	child := c.With()
+
+	result := make(chan error, 2)
+	for _, cl := range []*Client{c, child} {
+		go func() {
+			_, _, err := cl.Get(context.Background(), "/hang")
+			result <- err
+		}()
+	}
+	deadline := time.Now().Add(time.Second)
+	for c.InFlight() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 calls in flight, got %d", c.InFlight())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	c.CancelAll()
+	for range 2 {
+		if err := <-result; !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+	if n := c.InFlight(); n != 0 {
+		t.Fatalf("expected no calls in flight, got %d", n)
+	}
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatalf("expected the client to stay usable, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package resilient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHMACSigner(t *testing.T) {
+	key := []byte("secret")
+	var attempts atomic.Int32
+	var timestamps []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts := r.Header.Get("X-Timestamp")
+		timestamps = append(timestamps, ts)
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(ts + r.Method + r.URL.RequestURI() + string(body)))
+		if r.Header.Get("X-Signature") != hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("bad signature on attempt %d", attempts.Load()+1)
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	var tick atomic.Int64
+	signer := &HMACSigner{Key: key, now: func() time.Time { return time.UnixMilli(tick.Add(1)) }}
+	c := New(WithBaseURL(srv.URL), WithRetry(1, time.Millisecond), WithSigner(signer))
+	defer c.Close()
+
+	if _, _, err := c.Post(context.Background(), "/orders?x=1", "application/json", strings.NewReader(`{"qty":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(timestamps) != 2 || timestamps[0] == timestamps[1] {
+		t.Fatalf("expected each attempt signed afresh, got %v", timestamps)
+	}
+}
+
+func TestSignerError(t *testing.T) {
+	c := New(WithRetry(0, 0), WithSigner(SignerFunc(func(*http.Request) error {
+		return errors.New("no key")
+	})))
+	defer c.Close()
+
+	req, _ := http.NewRequest("GET", "http://127.0.0.1:1", nil)
+	if _, _, err := c.Do(context.Background(), req); err == nil || !strings.Contains(err.Error(), "no key") {
+		t.Fatalf("expected the signing error, got %v", err)
+	}
+}
+
+func TestSignerErrorIsTerminal(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("primary"))
+	}))
+	defer primary.Close()
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fallback"))
+	}))
+	defer fallback.Close()
+
+	errNoKey := errors.New("no key")
+	var failing atomic.Bool
+	failing.Store(true)
+	c := New(WithBaseURLs(primary.URL, fallback.URL), WithRetry(3, 50*time.Millisecond),
+		WithPerHostPolicy(HostPolicy{BreakerThreshold: 1, BreakerCooldown: time.Minute}),
+		WithSigner(SignerFunc(func(*http.Request) error {
+			if failing.Load() {
+				return errNoKey
+			}
+			return nil
+		})))
+	defer c.Close()
+
+	req, _ := c.NewRequest(context.Background(), http.MethodGet, "/", nil)
+	start := time.Now()
+	res, err := c.DoResult(context.Background(), req)
+	if !errors.Is(err, errNoKey) {
+		t.Fatalf("expected the signing error, got %v", err)
+	}
+	if res.Attempts != 1 || time.Since(start) > 40*time.Millisecond {
+		t.Fatalf("expected no retries, got %d attempts in %v", res.Attempts, time.Since(start))
+	}
+	if s := c.Stats(); s.TotalRetries != 0 {
+		t.Fatalf("expected no retries, got %d", s.TotalRetries)
+	}
+
+	failing.Store(false)
+	body, _, err := c.Get(context.Background(), "/")
+	if err != nil || string(body) != "primary" {
+		t.Fatalf("expected the primary to stay up, got %q %v", body, err)
+	}
+	if c.CircuitOpen(mustHost(t, primary.URL)) {
+		t.Fatal("expected the signing error to be kept out of the breaker")
+	}
+}
+
+func decodeJWT(t *testing.T, token string) (header, claims map[string]any, signing string, sig []byte) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed token %q", token)
+	}
+	for i, out := range []*map[string]any{&header, &claims} {
+		data, _ := base64.RawURLEncoding.DecodeString(parts[i])
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig, _ = base64.RawURLEncoding.DecodeString(parts[2])
+	return header, claims, parts[0] + "." + parts[1], sig
+}
+
+func TestJWTSignerHS256(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := &JWTSigner{Key: []byte("k"), Issuer: "me", Audience: "api", Claims: map[string]any{"scope": "read"}, now: func() time.Time { return now }}
+
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+	if err := s.Sign(req); err != nil {
+		t.Fatal(err)
+	}
+	header, claims, signing, sig := decodeJWT(t, strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+	if header["alg"] != "HS256" || claims["iss"] != "me" || claims["aud"] != "api" || claims["scope"] != "read" {
+		t.Fatalf("unexpected token: %v %v", header, claims)
+	}
+	if claims["exp"].(float64) != float64(now.Add(time.Minute).Unix()) {
+		t.Fatalf("expected a one minute TTL, got %v", claims["exp"])
+	}
+	mac := hmac.New(sha256.New, []byte("k"))
+	mac.Write([]byte(signing))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		t.Fatal("bad HS256 signature")
+	}
+}
+
+func TestJWTSignerES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := (&JWTSigner{PrivateKey: key, KeyID: "k1"}).Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, _, signing, sig := decodeJWT(t, token)
+	if header["alg"] != "ES256" || header["kid"] != "k1" || len(sig) != 64 {
+		t.Fatalf("unexpected header %v / signature length %d", header, len(sig))
+	}
+	digest := sha256.Sum256([]byte(signing))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Fatal("bad ES256 signature")
+	}
+}
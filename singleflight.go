@@ -0,0 +1,118 @@
+package resilient
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// flight is an in-progress or completed shared call. dups counts the
+// callers that joined it, and panicked holds what fn panicked with, if it
+// did.
+type flight struct {
+	wg       sync.WaitGroup
+	res      *Result
+	err      error
+	dups     int
+	panicked any
+}
+
+// flightGroup deduplicates concurrent calls with the same key.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// do runs fn once for all concurrent callers with the same key, and
+// reports whether the caller joined an existing call. When a call is
+// shared, each caller receives its own copy of the Result and of the
+// attempt history in the error. If fn panics, every caller panics with the
+// same value.
+func (g *flightGroup) do(key string, fn func() (*Result, error)) (*Result, error, bool) {
+	g.mu.Lock()
+	if f, ok := g.calls[key]; ok {
+		f.dups++
+		g.mu.Unlock()
+		f.wg.Wait()
+		if f.panicked != nil {
+			panic(f.panicked)
+		}
+		res := f.res.clone()
+		res.Shared = true
+		return res, cloneFlightErr(f.err), true
+	}
+	f := &flight{}
+	f.wg.Add(1)
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				f.panicked = r
+			}
+		}()
+		f.res, f.err = fn()
+	}()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	shared := f.dups > 0
+	g.mu.Unlock()
+	f.wg.Done()
+	if f.panicked != nil {
+		panic(f.panicked)
+	}
+	if shared {
+		return f.res.clone(), cloneFlightErr(f.err), false
+	}
+	return f.res, f.err, false
+}
+
+// clone returns a copy of r that shares no mutable data with it.
+func (r *Result) clone() *Result {
+	res := *r
+	res.Body = bytes.Clone(r.Body)
+	res.Header = r.Header.Clone()
+	res.Redirects = slices.Clone(r.Redirects)
+	return &res
+}
+
+// cloneFlightErr copies the attempt history carried by err, so that callers
+// sharing a call can't change each other's.
+func cloneFlightErr(err error) error {
+	switch e := err.(type) {
+	case *RetryError:
+		return &RetryError{Attempts: slices.Clone(e.Attempts), err: e.err}
+	case *CurlError:
+		return &CurlError{Command: e.Command, err: cloneFlightErr(e.err)}
+	}
+	return err
+}
+
+// flightKey identifies a request by method, URL and headers.
+func flightKey(req *http.Request) string {
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteByte(' ')
+	b.WriteString(req.URL.String())
+
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteByte('\n')
+		b.WriteString(k)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(req.Header[k], ","))
+	}
+	return b.String()
+}
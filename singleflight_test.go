@@ -0,0 +1,194 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleflight(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Write([]byte("shared"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithSingleflight())
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, _, err := c.Get(context.Background(), "/resource")
+			if err != nil || string(body) != "shared" {
+				t.Errorf("unexpected: body=%s err=%v", body, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", n)
+	}
+}
+
+func TestSingleflightKeyIncludesHeaders(t *testing.T) {
+	a, _ := http.NewRequest(http.MethodGet, "http://example.com/x", nil)
+	b, _ := http.NewRequest(http.MethodGet, "http://example.com/x", nil)
+	b.Header.Set("Authorization", "Bearer other")
+	if flightKey(a) == flightKey(b) {
+		t.Fatal("requests with different headers must not share a key")
+	}
+}
+
+func TestSingleflightFollowersAreIsolated(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Header().Set("X-Shared", "value")
+		w.Write([]byte("shared"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithSingleflight())
+	defer c.Close()
+
+	results := make(chan *Result, 3)
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(func() {
+			req, _ := c.NewRequest(context.Background(), http.MethodGet, "/", nil)
+			res, err := c.DoResult(context.Background(), req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results <- res
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 upstream call, got %d", n)
+	}
+	var all []*Result
+	for res := range results {
+		all = append(all, res)
+	}
+	shared := 0
+	for _, res := range all {
+		if res.Shared {
+			shared++
+		}
+	}
+	if shared != 2 {
+		t.Fatalf("expected 2 results marked shared, got %d", shared)
+	}
+	all[0].Header.Set("X-Shared", "changed")
+	all[0].Body[0] = 'S'
+	for _, res := range all[1:] {
+		if res.Header.Get("X-Shared") != "value" || string(res.Body) != "shared" {
+			t.Fatalf("expected each caller to own its result, got %q %v", res.Body, res.Header)
+		}
+	}
+}
+
+func TestSingleflightPanic(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithSingleflight(), WithResponseHook(func(*http.Response) {
+		panic("boom")
+	}))
+	defer c.Close()
+
+	var panics atomic.Int32
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Go(func() {
+			defer func() {
+				if recover() == "boom" {
+					panics.Add(1)
+				}
+			}()
+			c.Get(context.Background(), "/")
+		})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("callers blocked after the shared call panicked")
+	}
+	if n := panics.Load(); n != 3 {
+		t.Fatalf("expected the panic to reach every caller, got %d", n)
+	}
+}
+
+func TestSingleflightLeaderCanceled(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithSingleflight(), WithRetry(0, 0))
+	defer c.Close()
+
+	leader, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := c.Get(leader, "/")
+		errc <- err
+	}()
+	waitUntil(t, "leader request", func() bool { return calls.Load() == 1 })
+
+	bodyc := make(chan string, 1)
+	go func() {
+		body, _, err := c.Get(context.Background(), "/")
+		if err != nil {
+			t.Error(err)
+		}
+		bodyc <- string(body)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the leader to be canceled, got %v", err)
+	}
+	if body := <-bodyc; body != "ok" {
+		t.Fatalf("expected the follower to send its own request, got %q", body)
+	}
+}
@@ -0,0 +1,124 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// sloSlots is the number of slots the SLO window is divided into; samples
+// expire one slot at a time.
+const sloSlots = 10
+
+// sloMinRequests is the number of calls a window needs before a breach is
+// reported, so a single early failure does not fire the callback.
+const sloMinRequests = 10
+
+// SLOReport is the success rate of calls over the WithSLO window.
+type SLOReport struct {
+	Target      float64
+	SuccessRate float64
+	Total       uint64
+	Failed      uint64
+	Window      time.Duration
+	Time        time.Time
+}
+
+// sloSlot counts calls in one slice of the window.
+type sloSlot struct {
+	start         time.Time
+	total, failed uint64
+}
+
+// sloTracker keeps the success rate over a moving window and reports when
+// it drops below the target.
+type sloTracker struct {
+	target   float64
+	window   time.Duration
+	onBreach func(SLOReport)
+
+	mu       sync.Mutex
+	slots    [sloSlots]sloSlot
+	breached bool
+}
+
+func newSLOTracker(cfg *config) *sloTracker {
+	if cfg.sloWindow <= 0 {
+		return nil
+	}
+	return &sloTracker{target: cfg.sloTarget, window: cfg.sloWindow, onBreach: cfg.sloOnBreach}
+}
+
+// record counts a completed call and runs the breach callback when the
+// success rate falls below the target. The callback fires once per breach
+// and is re-armed when the rate recovers.
+func (t *sloTracker) record(failed bool, now time.Time) {
+	t.mu.Lock()
+	width := max(t.window/sloSlots, 1)
+	start := now.Truncate(width)
+	i := start.UnixNano() / int64(width) % sloSlots
+	if i < 0 {
+		i += sloSlots
+	}
+	s := &t.slots[i]
+	if !s.start.Equal(start) {
+		*s = sloSlot{start: start}
+	}
+	s.total++
+	if failed {
+		s.failed++
+	}
+	r := t.reportLocked(now)
+	fire := false
+	if r.Total >= sloMinRequests {
+		below := r.SuccessRate < t.target
+		fire = below && !t.breached
+		t.breached = below
+	}
+	t.mu.Unlock()
+
+	if fire && t.onBreach != nil {
+		t.onBreach(r)
+	}
+}
+
+// report returns the success rate over the window ending at now.
+func (t *sloTracker) report(now time.Time) SLOReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.reportLocked(now)
+}
+
+func (t *sloTracker) reportLocked(now time.Time) SLOReport {
+	r := SLOReport{Target: t.target, SuccessRate: 1, Window: t.window, Time: now}
+	oldest := now.Add(-t.window)
+	for _, s := range t.slots {
+		if s.start.After(oldest) {
+			r.Total += s.total
+			r.Failed += s.failed
+		}
+	}
+	if r.Total > 0 {
+		r.SuccessRate = float64(r.Total-r.Failed) / float64(r.Total)
+	}
+	return r
+}
+
+// observeSLO records the outcome of a call for WithSLO. Calls canceled by
+// the caller are not counted.
+func (c *Client) observeSLO(err error) {
+	if c.slo == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	c.slo.record(err != nil, c.cfg.clock.Now())
+}
+
+// SLO returns the success rate over the WithSLO window, or a zero report
+// when WithSLO is not set.
+func (c *Client) SLO() SLOReport {
+	if c.slo == nil {
+		return SLOReport{}
+	}
+	return c.slo.report(c.cfg.clock.Now())
+}
@@ -0,0 +1,81 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSLOTrackerWindow(t *testing.T) {
+	var breaches []SLOReport
+	tr := &sloTracker{target: 0.9, window: 10 * time.Second, onBreach: func(r SLOReport) { breaches = append(breaches, r) }}
+	now := time.Unix(1_000_000, 0)
+
+	for range 18 {
+		tr.record(false, now)
+	}
+	tr.record(true, now)
+	if len(breaches) != 0 {
+		t.Fatal("expected no breach at 94.7%")
+	}
+	tr.record(true, now)
+	if len(breaches) != 0 {
+		t.Fatal("expected no breach at exactly the target")
+	}
+	tr.record(true, now)
+	tr.record(true, now)
+	if len(breaches) != 1 {
+		t.Fatalf("expected one breach while below target, got %d", len(breaches))
+	}
+	if r := breaches[0]; r.Total != 21 || r.Failed != 3 || r.SuccessRate >= 0.9 {
+		t.Fatalf("unexpected report %+v", r)
+	}
+
+	// Once the failures leave the window the rate recovers and the
+	// callback is re-armed.
+	now = now.Add(11 * time.Second)
+	if r := tr.report(now); r.Total != 0 || r.SuccessRate != 1 {
+		t.Fatalf("expected an empty window, got %+v", r)
+	}
+	for range 10 {
+		tr.record(false, now)
+	}
+	for range 2 {
+		tr.record(true, now)
+	}
+	if len(breaches) != 2 {
+		t.Fatalf("expected a second breach after recovery, got %d", len(breaches))
+	}
+}
+
+func TestWithSLO(t *testing.T) {
+	var fail atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	var breached atomic.Int32
+	c := New(WithBaseURL(srv.URL), WithRetry(0, 0), WithSLO(0.95, time.Minute, func(r SLOReport) {
+		breached.Add(1)
+	}))
+	defer c.Close()
+
+	for range 10 {
+		c.Get(context.Background(), "/")
+	}
+	fail.Store(true)
+	c.Get(context.Background(), "/")
+
+	if n := breached.Load(); n != 1 {
+		t.Fatalf("expected one breach, got %d", n)
+	}
+	if r := c.SLO(); r.Total != 11 || r.Failed != 1 || r.Target != 0.95 {
+		t.Fatalf("unexpected report %+v", r)
+	}
+}
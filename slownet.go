@@ -0,0 +1,95 @@
+package resilient
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// slowTransport simulates a slow network link: added latency per round trip
+// and a shared bandwidth cap on request and response bodies.
+type slowTransport struct {
+	base     http.RoundTripper
+	minDelay time.Duration
+	maxDelay time.Duration
+	up, down *rate.Limiter
+}
+
+// newSlowTransport wraps base according to WithSimulatedLatency and
+// WithBandwidthLimit, or returns base unchanged.
+func newSlowTransport(base http.RoundTripper, cfg *config) http.RoundTripper {
+	if cfg.simMaxDelay <= 0 && cfg.bandwidth <= 0 {
+		return base
+	}
+	t := &slowTransport{base: base, minDelay: cfg.simMinDelay, maxDelay: cfg.simMaxDelay}
+	if cfg.bandwidth > 0 {
+		t.up, t.down = bandwidthLimiter(cfg.bandwidth), bandwidthLimiter(cfg.bandwidth)
+	}
+	return t
+}
+
+// bandwidthLimiter allows bps bytes per second in chunks of a tenth of that.
+func bandwidthLimiter(bps int) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(bps), max(bps/10, 1))
+}
+
+func (t *slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if d := t.delay(); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if t.up != nil && req.Body != nil && req.Body != http.NoBody {
+		r := *req
+		r.Body = &throttledBody{ctx: ctx, rc: req.Body, lim: t.up}
+		req = &r
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || t.down == nil {
+		return resp, err
+	}
+	resp.Body = &throttledBody{ctx: ctx, rc: resp.Body, lim: t.down}
+	return resp, nil
+}
+
+// delay returns a random latency between minDelay and maxDelay.
+func (t *slowTransport) delay() time.Duration {
+	if t.maxDelay <= t.minDelay {
+		return t.minDelay
+	}
+	return t.minDelay + rand.N(t.maxDelay-t.minDelay)
+}
+
+// throttledBody reads no faster than lim allows.
+type throttledBody struct {
+	ctx context.Context
+	rc  io.ReadCloser
+	lim *rate.Limiter
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if len(p) > b.lim.Burst() {
+		p = p[:b.lim.Burst()]
+	}
+	n, err := b.rc.Read(p)
+	if n > 0 {
+		if werr := b.lim.WaitN(b.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+func (b *throttledBody) Close() error { return b.rc.Close() }
@@ -0,0 +1,55 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulatedLatency(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(0, 0), WithSimulatedLatency(40*time.Millisecond, 50*time.Millisecond))
+	defer c.Close()
+
+	start := time.Now()
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 40*time.Millisecond {
+		t.Fatalf("expected at least 40ms of latency, took %v", d)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.Get(ctx, "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to cut the delay short, got %v", err)
+	}
+}
+
+func TestBandwidthLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		io.WriteString(w, strings.Repeat("x", int(n)))
+	}))
+	defer srv.Close()
+
+	// 10 KB/s with a 1 KB burst: 2 KB up and 2 KB down take about 0.2s.
+	c := New(WithBaseURL(srv.URL), WithBandwidthLimit(10_000))
+	defer c.Close()
+
+	start := time.Now()
+	body, _, err := c.Post(context.Background(), "/", "text/plain", strings.NewReader(strings.Repeat("y", 2000)))
+	if err != nil || len(body) != 2000 {
+		t.Fatalf("expected 2000 bytes back, got %d %v", len(body), err)
+	}
+	if d := time.Since(start); d < 150*time.Millisecond {
+		t.Fatalf("expected throttled transfer, took %v", d)
+	}
+}
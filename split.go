@@ -0,0 +1,101 @@
+package resilient
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RouteStats counts the calls sent to one side of a WithTrafficSplit.
+type RouteStats struct {
+	BaseURL  string
+	Requests uint64
+	Errors   uint64
+	// Latency is the histogram of whole calls, retries included.
+	Latency LatencyStats
+}
+
+// TrafficSplitStats compares the primary and canary sides of a
+// WithTrafficSplit.
+type TrafficSplitStats struct {
+	Primary RouteStats
+	Canary  RouteStats
+}
+
+// splitRoute is one side of a traffic split.
+type splitRoute struct {
+	base     string
+	requests atomic.Uint64
+	errors   atomic.Uint64
+	latency  *latencyHistogram
+}
+
+func (r *splitRoute) observe(d time.Duration, err error) {
+	r.requests.Add(1)
+	if err != nil {
+		r.errors.Add(1)
+	}
+	r.latency.observe(d)
+}
+
+func (r *splitRoute) stats() RouteStats {
+	return RouteStats{
+		BaseURL:  r.base,
+		Requests: r.requests.Load(),
+		Errors:   r.errors.Load(),
+		Latency:  r.latency.snapshot(),
+	}
+}
+
+// trafficSplit sends a share of the calls made against the primary base
+// URL to the canary.
+type trafficSplit struct {
+	percent float64
+	primary splitRoute
+	canary  splitRoute
+}
+
+// newTrafficSplit returns nil unless WithTrafficSplit is configured.
+func newTrafficSplit(cfg *config) *trafficSplit {
+	if cfg.splitCanary == "" {
+		return nil
+	}
+	return &trafficSplit{
+		percent: cfg.splitPercent,
+		primary: splitRoute{base: cfg.baseURL, latency: newLatencyHistogram(cfg.latencyBuckets)},
+		canary:  splitRoute{base: cfg.splitCanary, latency: newLatencyHistogram(cfg.latencyBuckets)},
+	}
+}
+
+// route picks the side req goes to, rewriting it for the canary. It
+// returns nil for requests not made against the primary base URL.
+func (s *trafficSplit) route(req *http.Request) (*http.Request, *splitRoute) {
+	if s == nil {
+		return req, nil
+	}
+	raw := req.URL.String()
+	if !strings.HasPrefix(raw, s.primary.base) {
+		return req, nil
+	}
+	if rand.Float64()*100 >= s.percent {
+		return req, &s.primary
+	}
+	u, err := req.URL.Parse(s.canary.base + raw[len(s.primary.base):])
+	if err != nil {
+		return req, &s.primary
+	}
+	req = req.Clone(req.Context())
+	req.URL, req.Host = u, u.Host
+	return req, &s.canary
+}
+
+// TrafficSplit returns the per-side statistics of WithTrafficSplit, or
+// zero values when it is not set.
+func (c *Client) TrafficSplit() TrafficSplitStats {
+	if c.split == nil {
+		return TrafficSplitStats{}
+	}
+	return TrafficSplitStats{Primary: c.split.primary.stats(), Canary: c.split.canary.stats()}
+}
@@ -0,0 +1,43 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrafficSplit(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v1 " + r.URL.Path))
+	}))
+	defer primary.Close()
+	canary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer canary.Close()
+
+	c := New(WithTrafficSplit(primary.URL, canary.URL, 20))
+	defer c.Close()
+	const n = 500
+	for range n {
+		body, _, err := c.Get(context.Background(), "/users")
+		if err == nil && string(body) != "v1 /users" {
+			t.Fatalf("unexpected primary response %q", body)
+		}
+	}
+
+	s := c.TrafficSplit()
+	if s.Primary.Requests+s.Canary.Requests != n {
+		t.Fatalf("expected %d calls in total, got %+v", n, s)
+	}
+	if s.Canary.Requests < 60 || s.Canary.Requests > 140 {
+		t.Fatalf("expected about 20%% of calls on the canary, got %d", s.Canary.Requests)
+	}
+	if s.Primary.Errors != 0 || s.Canary.Errors != s.Canary.Requests || s.Canary.BaseURL != canary.URL {
+		t.Fatalf("expected errors tracked per side, got %+v %+v", s.Primary, s.Canary)
+	}
+	if s.Canary.Latency.Count != s.Canary.Requests {
+		t.Fatalf("expected a latency sample per canary call, got %d", s.Canary.Latency.Count)
+	}
+}
@@ -0,0 +1,127 @@
+package resilient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+)
+
+// DoJSONStream sends reqBody as JSON and returns the elements of the
+// response one by one, without holding the whole body in memory. It
+// accepts newline-delimited JSON (or any sequence of concatenated values)
+// as well as a single top-level array, whose elements are yielded in turn:
+//
+//	items, err := client.DoJSONStream(ctx, "GET", "/events", nil)
+//	for raw, err := range items {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// The request is sent when iteration starts, through the usual retry and
+// rate-limit pipeline; request and decode failures are yielded as a final
+// (nil, err) pair. Breaking out of the loop closes the response. Like
+// Download, the body is not subject to WithMaxResponseSize. The returned
+// error only reports failures building the request.
+func (c *Client) DoJSONStream(ctx context.Context, method, path string, reqBody any, headers ...map[string]string) (iter.Seq2[json.RawMessage, error], error) {
+	var body io.Reader
+	if reqBody != nil {
+		data, err := json.Marshal(reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("resilient: marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := c.NewRequest(ctx, method, path, body, headers...)
+	if err != nil {
+		return nil, err
+	}
+	if reqBody != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json, application/x-ndjson")
+	}
+
+	return func(yield func(json.RawMessage, error) bool) {
+		stopped := false
+		_, err := c.execute(req.Context(), c.withDefaultHeaders(req), func(resp *http.Response) error {
+			for raw, err := range decodeJSONStream(resp.Body) {
+				if err != nil {
+					return err
+				}
+				if !yield(raw, nil) {
+					stopped = true
+					return nil
+				}
+			}
+			return nil
+		})
+		if err != nil && !stopped {
+			yield(nil, err)
+		}
+	}, nil
+}
+
+// decodeJSONStream yields the values of r: the elements of a top-level
+// array, or each value of a whitespace-separated sequence.
+func decodeJSONStream(r io.Reader) iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		br := bufio.NewReader(r)
+		array, err := startsWithArray(br)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				yield(nil, err)
+			}
+			return
+		}
+
+		dec := json.NewDecoder(br)
+		if array {
+			if _, err := dec.Token(); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+		for !array || dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				if !array && errors.Is(err, io.EOF) {
+					return
+				}
+				yield(nil, err)
+				return
+			}
+			if !yield(raw, nil) {
+				return
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// startsWithArray skips leading whitespace and reports whether the next
+// byte opens a JSON array.
+func startsWithArray(br *bufio.Reader) (bool, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return false, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b == '[', br.UnreadByte()
+	}
+}
@@ -0,0 +1,112 @@
+package resilient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func collectStream(t *testing.T, c *Client, path string) ([]string, error) {
+	t.Helper()
+	items, err := c.DoJSONStream(context.Background(), "GET", path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for raw, err := range items {
+		if err != nil {
+			return got, err
+		}
+		got = append(got, string(raw))
+	}
+	return got, nil
+}
+
+func TestDoJSONStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ndjson":
+			io.WriteString(w, "{\"id\":1}\n{\"id\":2}\n\n{\"id\":3}\n")
+		case "/array":
+			io.WriteString(w, ` [ {"id":1}, {"id":2} ] `)
+		case "/empty":
+		case "/broken":
+			io.WriteString(w, `[{"id":1}, {"id":`)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	got, err := collectStream(t, c, "/ndjson")
+	if err != nil || strings.Join(got, ",") != `{"id":1},{"id":2},{"id":3}` {
+		t.Fatalf("ndjson: got %v, %v", got, err)
+	}
+	got, err = collectStream(t, c, "/array")
+	if err != nil || strings.Join(got, ",") != `{"id":1},{"id":2}` {
+		t.Fatalf("array: got %v, %v", got, err)
+	}
+	got, err = collectStream(t, c, "/empty")
+	if err != nil || len(got) != 0 {
+		t.Fatalf("empty: got %v, %v", got, err)
+	}
+	got, err = collectStream(t, c, "/broken")
+	if err == nil || len(got) != 1 {
+		t.Fatalf("broken: expected one item then an error, got %v, %v", got, err)
+	}
+}
+
+func TestDoJSONStreamRetryAndBreak(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		for i := range 1000 {
+			json.NewEncoder(w).Encode(map[string]int{"n": i})
+		}
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(1, time.Millisecond))
+	defer c.Close()
+
+	items, _ := c.DoJSONStream(context.Background(), "GET", "/", nil)
+	n := 0
+	for _, err := range items {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 3 {
+			break
+		}
+	}
+	if n != 3 || attempts != 2 {
+		t.Fatalf("expected 3 items after a retry, got %d items, %d attempts", n, attempts)
+	}
+	if s := c.Stats(); s.TotalErrors != 1 {
+		t.Fatalf("expected only the 503 counted as an error, got %d", s.TotalErrors)
+	}
+}
+
+func TestDoJSONStreamHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	if _, err := collectStream(t, c, "/"); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("expected HTTP 400 error, got %v", err)
+	}
+}
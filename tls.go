@@ -0,0 +1,99 @@
+package resilient
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PinError is returned when no certificate presented by the server matches
+// the pins set with WithPinnedCertificates.
+type PinError struct {
+	Host string
+	// Pins are the SPKI pins ("sha256/<base64>") of the certificates the
+	// server presented.
+	Pins []string
+}
+
+func (e *PinError) Error() string {
+	return fmt.Sprintf("resilient: certificate pin mismatch for %s (got %s)", e.Host, strings.Join(e.Pins, ", "))
+}
+
+// SPKIPin returns the pin of cert in the "sha256/<base64>" form accepted
+// by WithPinnedCertificates.
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// verifyPins returns a tls.Config.VerifyConnection func that accepts a
+// connection if any certificate in its chain matches one of pins.
+func verifyPins(pins []string) func(tls.ConnectionState) error {
+	want := make(map[string]bool, len(pins))
+	for _, p := range pins {
+		want["sha256/"+strings.TrimPrefix(p, "sha256/")] = true
+	}
+	return func(cs tls.ConnectionState) error {
+		certs := cs.PeerCertificates
+		for _, chain := range cs.VerifiedChains {
+			certs = append(certs, chain...)
+		}
+		got := make([]string, 0, len(cs.PeerCertificates))
+		for i, cert := range certs {
+			pin := SPKIPin(cert)
+			if want[pin] {
+				return nil
+			}
+			if i < len(cs.PeerCertificates) {
+				got = append(got, pin)
+			}
+		}
+		return &PinError{Host: cs.ServerName, Pins: got}
+	}
+}
+
+// loadClientCertificate returns a tls.Config.GetClientCertificate func
+// that reads the key pair on every handshake, so renewed certificates are
+// picked up by new connections.
+func loadClientCertificate(certFile, keyFile string) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("resilient: load client certificate: %w", err)
+		}
+		return &cert, nil
+	}
+}
+
+// hasTLSOptions reports whether any TLS option is set.
+func hasTLSOptions(cfg *config) bool {
+	return cfg.tlsConfig != nil || cfg.certFile != "" || len(cfg.pins) > 0 ||
+		cfg.rootCAs != nil || cfg.minTLS != 0
+}
+
+// applyTLS applies the TLS options of cfg to tr.
+func applyTLS(tr *http.Transport, cfg *config) {
+	switch {
+	case cfg.tlsConfig != nil:
+		tr.TLSClientConfig = cfg.tlsConfig.Clone()
+	case tr.TLSClientConfig == nil:
+		tr.TLSClientConfig = &tls.Config{}
+	}
+	tc := tr.TLSClientConfig
+	if cfg.rootCAs != nil {
+		tc.RootCAs = cfg.rootCAs
+	}
+	if cfg.minTLS != 0 {
+		tc.MinVersion = cfg.minTLS
+	}
+	if cfg.certFile != "" {
+		tc.GetClientCertificate = loadClientCertificate(cfg.certFile, cfg.keyFile)
+	}
+	if len(cfg.pins) > 0 {
+		tc.VerifyConnection = verifyPins(cfg.pins)
+	}
+}
@@ -0,0 +1,178 @@
+package resilient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPinnedCertificates(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	pin := SPKIPin(srv.Certificate())
+	c := New(WithBaseURL(srv.URL), WithHTTPClient(srv.Client()), WithPinnedCertificates(pin))
+	defer c.Close()
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatalf("expected matching pin to pass, got %v", err)
+	}
+
+	c = New(
+		WithBaseURL(srv.URL),
+		WithHTTPClient(srv.Client()),
+		WithRetry(3, time.Millisecond),
+		WithPinnedCertificates("sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="),
+	)
+	defer c.Close()
+	_, _, err := c.Get(context.Background(), "/")
+	var pinErr *PinError
+	if !errors.As(err, &pinErr) || len(pinErr.Pins) == 0 || pinErr.Pins[0] != pin {
+		t.Fatalf("expected PinError reporting %s, got %v", pin, err)
+	}
+	if s := c.Stats(); s.TotalErrors != 1 {
+		t.Fatalf("expected the mismatch not to be retried, got %d errors", s.TotalErrors)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected no request to reach the server on mismatch, got %d", calls.Load())
+	}
+}
+
+func TestPinnedCertificatesUnsupportedTransport(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	// A transport the pins can't be applied to must not send unpinned.
+	var order []string
+	wrapped := &http.Client{Transport: &closingTransport{next: srv.Client().Transport, order: &order}}
+	c := New(WithBaseURL(srv.URL), WithHTTPClient(wrapped), WithRetry(3, time.Millisecond),
+		WithPinnedCertificates(SPKIPin(srv.Certificate())))
+	defer c.Close()
+	if _, _, err := c.Get(context.Background(), "/"); !errors.Is(err, ErrUnsupportedTransport) {
+		t.Fatalf("expected ErrUnsupportedTransport, got %v", err)
+	}
+	if _, err := c.Transport().RoundTrip(httptest.NewRequest(http.MethodGet, srv.URL, nil)); !errors.Is(err, ErrUnsupportedTransport) {
+		t.Fatalf("expected ErrUnsupportedTransport from Transport, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected no request to be sent, got %d", calls.Load())
+	}
+}
+
+func TestWithTLSLeavesClientUntouched(t *testing.T) {
+	hc := &http.Client{}
+	c := New(WithHTTPClient(hc), WithPinnedCertificates("x"))
+	defer c.Close()
+	if hc.Transport != nil || c.httpClient == hc {
+		t.Fatal("expected the caller's client to be copied, not modified")
+	}
+}
+
+// writeClientCert writes a self-signed client certificate for cn and its
+// key as PEM files in dir.
+func writeClientCert(t *testing.T, dir, cn string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+func TestClientCertificate(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	certFile, keyFile := writeClientCert(t, t.TempDir(), "svc-a")
+
+	c := New(
+		WithBaseURL(srv.URL),
+		WithTLSConfig(&tls.Config{RootCAs: roots}),
+		WithClientCertificate(certFile, keyFile),
+	)
+	defer c.Close()
+
+	body, _, err := c.Get(context.Background(), "/")
+	if err != nil || string(body) != "svc-a" {
+		t.Fatalf("expected mTLS as svc-a, got %q %v", body, err)
+	}
+
+	c = New(
+		WithBaseURL(srv.URL),
+		WithRetry(0, 0),
+		WithTLSConfig(&tls.Config{RootCAs: roots}),
+		WithClientCertificate(filepath.Join(t.TempDir(), "missing.crt"), keyFile),
+	)
+	defer c.Close()
+	if _, _, err := c.Get(context.Background(), "/"); err == nil {
+		t.Fatal("expected a missing certificate to fail the handshake")
+	}
+}
+
+func TestRootCAsAndMinTLSVersion(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	srv.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	c := New(WithBaseURL(srv.URL), WithRootCAs(roots))
+	defer c.Close()
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatalf("expected the private root to be trusted, got %v", err)
+	}
+
+	c = New(WithBaseURL(srv.URL), WithRetry(0, 0), WithRootCAs(roots), WithMinTLSVersion(tls.VersionTLS13))
+	defer c.Close()
+	if _, _, err := c.Get(context.Background(), "/"); err == nil {
+		t.Fatal("expected a TLS 1.2 server to be rejected")
+	}
+}
@@ -0,0 +1,192 @@
+package resilient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Transport returns an http.RoundTripper that sends each request once
+// through the client's default headers, rate limiters and hooks, without
+// retries, caching or body buffering. It suits protocols that take over the
+// connection, such as WebSocket upgrades, and integrations that run their
+// own retry loop (see Backoff).
+func (c *Client) Transport() http.RoundTripper {
+	return clientTransport{c}
+}
+
+// Backoff returns how long to wait before retry attempt n (starting at 1)
+// under the client's backoff, jitter and cap settings.
+func (c *Client) Backoff(n int) time.Duration {
+	if n < 1 {
+		n = 1
+	}
+	return c.backoffDuration(n, 0)
+}
+
+type clientTransport struct {
+	c *Client
+}
+
+func (t clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c := t.c
+	if c.inflight.closing() {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, ErrClientClosed
+	}
+	if c.transportErr != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, c.transportErr
+	}
+	if c.offline.Load() {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, ErrOffline
+	}
+	tokens, err := c.waitRateLimit(req.Context(), req.URL)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: %w", ErrRateLimitWait, err)
+	}
+
+	// A RoundTripper must not modify the caller's request.
+	req = c.withDefaultHeaders(req)
+	req = req.Clone(req.Context())
+	c.setBearer(req)
+	c.setAPIKey(req)
+	if c.digest != nil {
+		c.digest.authorize(req)
+	}
+	if c.cfg.requestHook != nil {
+		c.cfg.requestHook(req)
+	}
+	if c.cfg.signer != nil {
+		if err := c.cfg.signer.Sign(req); err != nil {
+			tokens.refund()
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, fmt.Errorf("resilient: sign request: %w", err)
+		}
+	}
+
+	c.totalReqs.Add(1)
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		c.totalErrors.Add(1)
+		return nil, err
+	}
+	if c.cfg.responseHook != nil {
+		c.cfg.responseHook(resp)
+	}
+	c.observeQuota(req, resp.Header)
+	if c.keys != nil {
+		c.keys.observe(resp, time.Now())
+	}
+	return resp, nil
+}
+
+// withTransport returns hc with the transport-level options of cfg (TLS
+// settings, protocols, proxy pool, DNS cache) applied to a copy of its transport, leaving hc
+// itself untouched. Transports other than *http.Transport are left as they
+// are, with an error if TLS options were set, since they would be silently
+// dropped.
+func withTransport(hc *http.Client, cfg *config, proxies *proxyPool, dns *dnsCache) (*http.Client, error) {
+	if !hasTLSOptions(cfg) && proxies == nil && dns == nil && cfg.protocols == nil {
+		var tc TransportConfig
+		if tr, ok := hc.Transport.(*http.Transport); ok {
+			tc.TLS = tr.TLSClientConfig
+		}
+		return withMiddleware(hc, cfg, tc), nil
+	}
+	var tr *http.Transport
+	switch t := hc.Transport.(type) {
+	case nil:
+		tr = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		tr = t.Clone()
+	default:
+		var err error
+		if hasTLSOptions(cfg) {
+			err = fmt.Errorf("%w, got %T", ErrUnsupportedTransport, t)
+		}
+		return withMiddleware(hc, cfg, TransportConfig{}), err
+	}
+	if hasTLSOptions(cfg) {
+		applyTLS(tr, cfg)
+	}
+	if cfg.protocols != nil {
+		p := *cfg.protocols
+		tr.Protocols = &p
+		// A transport that has been used already advertises h2 in its
+		// TLS config; drop it when HTTP/2 is off.
+		if tc := tr.TLSClientConfig; tc != nil && !p.HTTP2() && slices.Contains(tc.NextProtos, "h2") {
+			tc = tc.Clone()
+			tc.NextProtos = slices.DeleteFunc(slices.Clone(tc.NextProtos), func(proto string) bool { return proto == "h2" })
+			tr.TLSClientConfig = tc
+		}
+	}
+	if dns != nil {
+		dial := tr.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		tr.DialContext = dns.dialContext(dial)
+	}
+
+	copied := *hc
+	copied.Transport = tr
+	if proxies != nil {
+		tr.Proxy = proxyFromContext
+		copied.Transport = &proxyTransport{base: tr, pool: proxies}
+	}
+	return withMiddleware(&copied, cfg, TransportConfig{TLS: tr.TLSClientConfig, Proxied: proxies != nil}), nil
+}
+
+// TransportConfig describes the client's transport to middleware added with
+// WithTransportMiddlewareConfig.
+type TransportConfig struct {
+	// TLS is the TLS configuration of the client's *http.Transport, with
+	// the TLS options applied, or nil for the defaults. It must not be
+	// modified.
+	TLS *tls.Config
+	// Proxied reports whether requests are sent through WithProxyPool.
+	Proxied bool
+}
+
+// withMiddleware returns hc with its transport wrapped by the simulated slow
+// network, the WithDebugDump transport and the WithTransportMiddleware
+// functions.
+func withMiddleware(hc *http.Client, cfg *config, tc TransportConfig) *http.Client {
+	if len(cfg.middleware) == 0 && cfg.debugDump == nil && cfg.simMaxDelay <= 0 && cfg.bandwidth <= 0 {
+		return hc
+	}
+	rt := hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	rt = newSlowTransport(rt, cfg)
+	if cfg.debugDump != nil {
+		rt = &dumpTransport{base: rt, w: cfg.debugDump, redact: redactedHeaders(cfg)}
+	}
+	for _, mw := range cfg.middleware {
+		rt = mw(rt, tc)
+	}
+	copied := *hc
+	copied.Transport = rt
+	return &copied
+}
@@ -0,0 +1,137 @@
+package resilient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Default") != "d" || r.Header.Get("X-Hook") != "h" {
+			t.Errorf("missing headers: %v", r.Header)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := New(
+		WithHeader("X-Default", "d"),
+		WithRequestHook(func(r *http.Request) { r.Header.Set("X-Hook", "h") }),
+	)
+	defer c.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	resp, err := (&http.Client{Transport: c.Transport()}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Fatalf("expected the raw 503 without retries, got %d", resp.StatusCode)
+	}
+	if req.Header.Get("X-Hook") != "" {
+		t.Fatal("transport modified the caller's request")
+	}
+	if s := c.Stats(); s.TotalRequests != 1 {
+		t.Fatalf("expected 1 request, got %d", s.TotalRequests)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := New(WithRetry(3, 10*time.Millisecond), WithJitter(JitterNone, 0))
+	defer c.Close()
+
+	if d := c.Backoff(1); d != 10*time.Millisecond {
+		t.Fatalf("expected 10ms, got %v", d)
+	}
+	if d := c.Backoff(3); d != 40*time.Millisecond {
+		t.Fatalf("expected 40ms, got %v", d)
+	}
+}
+
+func TestHTTP2Options(t *testing.T) {
+	proto := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+
+	tlsSrv := httptest.NewUnstartedServer(proto)
+	tlsSrv.EnableHTTP2 = true
+	tlsSrv.StartTLS()
+	defer tlsSrv.Close()
+
+	for _, tc := range []struct {
+		opt  Option
+		want string
+	}{
+		{WithHTTP2(true), "HTTP/2.0"},
+		{WithHTTP2(false), "HTTP/1.1"},
+	} {
+		c := New(WithBaseURL(tlsSrv.URL), WithRetry(0, 0), WithRootCAs(tlsSrv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs), tc.opt)
+		body, _, err := c.Get(context.Background(), "/")
+		c.Close()
+		if err != nil || string(body) != tc.want {
+			t.Fatalf("expected %s, got %q %v", tc.want, body, err)
+		}
+	}
+
+	h2c := httptest.NewUnstartedServer(proto)
+	h2c.Config.Protocols = new(http.Protocols)
+	h2c.Config.Protocols.SetUnencryptedHTTP2(true)
+	h2c.Start()
+	defer h2c.Close()
+
+	c := New(WithBaseURL(h2c.URL), WithH2C())
+	defer c.Close()
+	body, _, err := c.Get(context.Background(), "/")
+	if err != nil || string(body) != "HTTP/2.0" {
+		t.Fatalf("expected h2c, got %q %v", body, err)
+	}
+}
+
+// closingTransport records the middleware order and whether it was closed.
+type closingTransport struct {
+	next   http.RoundTripper
+	name   string
+	order  *[]string
+	closed bool
+}
+
+func (t *closingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	*t.order = append(*t.order, t.name)
+	return t.next.RoundTrip(req)
+}
+
+func (t *closingTransport) Close() error {
+	t.closed = true
+	return nil
+}
+
+func TestTransportMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	var order []string
+	var outer *closingTransport
+	c := New(WithBaseURL(srv.URL),
+		WithTransportMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return &closingTransport{next: next, name: "inner", order: &order}
+		}),
+		WithTransportMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			outer = &closingTransport{next: next, name: "outer", order: &order}
+			return outer
+		}),
+	)
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Fatalf("expected outer then inner, got %v", order)
+	}
+	c.Close()
+	if !outer.closed {
+		t.Fatal("expected Close to close the transport")
+	}
+}
@@ -0,0 +1,150 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// DefaultChunkSize is the chunk size Upload uses when none is given.
+const DefaultChunkSize = 5 << 20 // 5 MiB
+
+// UploadDriver adapts chunked uploads to a server protocol such as tus or
+// S3 multipart. Requests should be sent through the given client with the
+// given context, so each chunk is retried, rate limited and reported to
+// WithProgress like any other request.
+type UploadDriver interface {
+	// Start creates an upload of size bytes and returns its ID.
+	Start(ctx context.Context, c *Client, size int64) (id string, err error)
+	// Offset reports how many bytes of the upload the server has, so an
+	// interrupted upload can resume from there.
+	Offset(ctx context.Context, c *Client, id string) (int64, error)
+	// UploadChunk sends chunk, which starts at offset.
+	UploadChunk(ctx context.Context, c *Client, id string, offset int64, chunk []byte) error
+	// Finish completes the upload once every chunk has been sent.
+	Finish(ctx context.Context, c *Client, id string) error
+}
+
+// Upload sends size bytes from r in chunks of chunkSize (DefaultChunkSize
+// if <= 0) through d. It returns the upload ID even when it fails, so the
+// upload can be continued with ResumeUpload.
+func (c *Client) Upload(ctx context.Context, d UploadDriver, r io.ReaderAt, size int64, chunkSize int) (string, error) {
+	id, err := d.Start(ctx, c, size)
+	if err != nil {
+		return "", fmt.Errorf("resilient: start upload: %w", err)
+	}
+	return id, c.uploadFrom(ctx, d, id, r, 0, size, chunkSize)
+}
+
+// ResumeUpload continues upload id from the offset the server reports.
+func (c *Client) ResumeUpload(ctx context.Context, d UploadDriver, id string, r io.ReaderAt, size int64, chunkSize int) error {
+	offset, err := d.Offset(ctx, c, id)
+	if err != nil {
+		return fmt.Errorf("resilient: upload offset: %w", err)
+	}
+	return c.uploadFrom(ctx, d, id, r, offset, size, chunkSize)
+}
+
+func (c *Client) uploadFrom(ctx context.Context, d UploadDriver, id string, r io.ReaderAt, offset, size int64, chunkSize int) error {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	buf := make([]byte, chunkSize)
+	for offset < size {
+		n, err := r.ReadAt(buf[:min(int64(chunkSize), size-offset)], offset)
+		if err != nil && !(errors.Is(err, io.EOF) && int64(n) == size-offset) {
+			return fmt.Errorf("resilient: read chunk at %d: %w", offset, err)
+		}
+		chunkCtx := c.withUploadOffset(ctx, offset, size)
+		if err := d.UploadChunk(chunkCtx, c, id, offset, buf[:n]); err != nil {
+			return fmt.Errorf("resilient: upload chunk at %d: %w", offset, err)
+		}
+		offset += int64(n)
+	}
+	if err := d.Finish(ctx, c, id); err != nil {
+		return fmt.Errorf("resilient: finish upload: %w", err)
+	}
+	return nil
+}
+
+// TusDriver uploads to a tus 1.0 server (https://tus.io). Endpoint is the
+// creation URL, relative to the client's base URL or absolute; upload IDs
+// are the upload URLs the server returns.
+type TusDriver struct {
+	Endpoint string
+}
+
+// Compile-time interface check.
+var _ UploadDriver = TusDriver{}
+
+const tusVersion = "1.0.0"
+
+// Start creates the upload with a POST to the endpoint.
+func (t TusDriver) Start(ctx context.Context, c *Client, size int64) (string, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, t.Endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Tus-Resumable", tusVersion)
+	req.Header.Set("Upload-Length", strconv.FormatInt(size, 10))
+	res, err := c.DoResult(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	loc := res.Header.Get("Location")
+	if loc == "" {
+		return "", errors.New("tus: no Location in creation response")
+	}
+	u, err := req.URL.Parse(loc)
+	if err != nil {
+		return "", fmt.Errorf("tus: bad Location %q: %w", loc, err)
+	}
+	return u.String(), nil
+}
+
+// Offset asks the server for Upload-Offset with a HEAD request.
+func (t TusDriver) Offset(ctx context.Context, c *Client, id string) (int64, error) {
+	req, err := tusRequest(ctx, http.MethodHead, id, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := c.DoResult(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(res.Header.Get("Upload-Offset"), 10, 64)
+}
+
+// UploadChunk PATCHes the chunk at offset.
+func (t TusDriver) UploadChunk(ctx context.Context, c *Client, id string, offset int64, chunk []byte) error {
+	req, err := tusRequest(ctx, http.MethodPatch, id, chunk)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	_, err = c.DoResult(ctx, req)
+	return err
+}
+
+// Finish is a no-op: a tus upload completes with its last chunk.
+func (t TusDriver) Finish(ctx context.Context, c *Client, id string) error {
+	return nil
+}
+
+func tusRequest(ctx context.Context, method, uploadURL string, body []byte) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, uploadURL, r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Tus-Resumable", tusVersion)
+	return req, nil
+}
@@ -0,0 +1,104 @@
+package resilient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tusServer is a minimal in-memory tus server. failAt makes the first PATCH
+// at that offset fail with the given status.
+type tusServer struct {
+	mu      sync.Mutex
+	data    []byte
+	patches int
+	failAt  int64
+	failed  bool
+	status  int
+}
+
+func (s *tusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		w.Header().Set("Location", "/files/1")
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodHead:
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+	case http.MethodPatch:
+		offset, _ := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+		if offset == s.failAt && !s.failed {
+			s.failed = true
+			w.WriteHeader(s.status)
+			return
+		}
+		if offset != int64(len(s.data)) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.data = append(s.data, body...)
+		s.patches++
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestUploadTus(t *testing.T) {
+	ts := &tusServer{failAt: 100, status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRetry(2, time.Millisecond))
+	defer c.Close()
+
+	payload := strings.Repeat("abcdefghij", 25)
+	id, err := c.Upload(context.Background(), TusDriver{Endpoint: "/files"}, strings.NewReader(payload), int64(len(payload)), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != srv.URL+"/files/1" {
+		t.Fatalf("unexpected upload id %q", id)
+	}
+	if string(ts.data) != payload || ts.patches != 3 {
+		t.Fatalf("expected 3 chunks with the full payload, got %d chunks, %d bytes", ts.patches, len(ts.data))
+	}
+}
+
+func TestUploadResume(t *testing.T) {
+	ts := &tusServer{failAt: 64, status: http.StatusBadRequest}
+	srv := httptest.NewServer(ts)
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL))
+	defer c.Close()
+
+	payload := []byte(strings.Repeat("x", 200))
+	d := TusDriver{Endpoint: "/files"}
+	id, err := c.Upload(context.Background(), d, bytes.NewReader(payload), int64(len(payload)), 64)
+	if err == nil {
+		t.Fatal("expected the second chunk to fail")
+	}
+	if len(ts.data) != 64 {
+		t.Fatalf("expected the first chunk to be stored, got %d bytes", len(ts.data))
+	}
+
+	if err := c.ResumeUpload(context.Background(), d, id, bytes.NewReader(payload), int64(len(payload)), 64); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ts.data, payload) {
+		t.Fatalf("expected resumed upload to complete, got %d bytes", len(ts.data))
+	}
+}
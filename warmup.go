@@ -0,0 +1,63 @@
+package resilient
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Warmup opens up to n connections to the base URL ahead of the first
+// calls, resolving its host and completing the TLS handshakes, so that
+// latency-sensitive services don't pay for them on their first requests.
+// It sends n concurrent HEAD requests to the base URL straight to the
+// transport: they are not rate limited, retried or counted in Stats, and
+// any response counts as success. Over HTTP/2 they share one connection;
+// over HTTP/1.1 the transport keeps at most MaxIdleConnsPerHost of them
+// (2 by default) open for reuse.
+func (c *Client) Warmup(ctx context.Context, n int) error {
+	if c.cfg.baseURL == "" {
+		return errors.New("resilient: warmup: no base URL")
+	}
+	rt := c.httpClient.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.cfg.baseURL, nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			req = c.withDefaultHeaders(req)
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		})
+	}
+	wg.Wait()
+
+	failed := 0
+	var first error
+	for _, err := range errs {
+		if err != nil {
+			failed++
+			first = cmp.Or(first, err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("resilient: warmup: %d of %d connections failed: %w", failed, n, first)
+	}
+	return nil
+}
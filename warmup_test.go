@@ -0,0 +1,49 @@
+package resilient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWarmup(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	c := New(WithBaseURL(srv.URL), WithRootCAs(srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs), WithHTTP2(false))
+	defer c.Close()
+
+	if err := c.Warmup(context.Background(), 2); err != nil {
+		t.Fatal(err)
+	}
+	if n := conns.Load(); n != 2 {
+		t.Fatalf("expected 2 connections, got %d", n)
+	}
+	if s := c.Stats(); s.TotalRequests != 0 {
+		t.Fatalf("expected warmup requests not to be counted, got %d", s.TotalRequests)
+	}
+	if _, _, err := c.Get(context.Background(), "/"); err != nil {
+		t.Fatal(err)
+	}
+	if n := conns.Load(); n != 2 {
+		t.Fatalf("expected the first call to reuse a warm connection, got %d connections", n)
+	}
+}
+
+func TestWarmupError(t *testing.T) {
+	c := New(WithBaseURL("http://127.0.0.1:1"))
+	defer c.Close()
+	if err := c.Warmup(context.Background(), 3); err == nil {
+		t.Fatal("expected an error for an unreachable base URL")
+	}
+}
@@ -0,0 +1,335 @@
+// Package webhook delivers signed webhooks through a resilient.Client, on a
+// retry schedule measured in minutes rather than the client's backoff, and
+// stops sending to endpoints that keep failing:
+//
+//	s := webhook.New(client,
+//	    webhook.WithSecret(secret),
+//	    webhook.WithSchedule(time.Minute, 5*time.Minute, 30*time.Minute),
+//	)
+//	defer s.Close()
+//	err := s.Send(ctx, "https://example.com/hooks", payload)
+//
+// Receivers check the signature with Verify.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+)
+
+// DefaultSchedule is the delay before each retry unless WithSchedule
+// replaces it: four retries over roughly two and a half hours.
+var DefaultSchedule = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour}
+
+// DefaultSignatureHeader carries the payload signature.
+const DefaultSignatureHeader = "X-Webhook-Signature"
+
+// ErrEndpointDisabled is returned by Send for an endpoint disabled after
+// repeated failures; see WithDisableAfter and Sender.Enable.
+var ErrEndpointDisabled = errors.New("webhook: endpoint disabled")
+
+// ErrInvalidSignature is returned by Verify when the signature header is
+// missing, malformed, expired or does not match the payload.
+var ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+// Option configures a Sender.
+type Option func(*Sender)
+
+// WithSecret signs every payload with HMAC-SHA256 under secret.
+func WithSecret(secret []byte) Option {
+	return func(s *Sender) { s.secret = secret }
+}
+
+// WithSignatureHeader sets the header carrying the signature; the default
+// is DefaultSignatureHeader.
+func WithSignatureHeader(name string) Option {
+	return func(s *Sender) { s.header = name }
+}
+
+// WithSchedule sets the delays before each retry of a failed delivery; the
+// number of delays is the number of retries.
+func WithSchedule(delays ...time.Duration) Option {
+	return func(s *Sender) { s.schedule = delays }
+}
+
+// WithEndpointSchedule overrides the retry schedule for one destination URL.
+func WithEndpointSchedule(url string, delays ...time.Duration) Option {
+	return func(s *Sender) { s.schedules[url] = delays }
+}
+
+// WithDisableAfter disables an endpoint after n consecutive failed
+// attempts (5 by default); 0 never disables endpoints. A 410 Gone response
+// disables it at once.
+func WithDisableAfter(n int) Option {
+	return func(s *Sender) { s.disableAfter = n }
+}
+
+// WithOnResult sets a callback invoked after every attempt, including the
+// retries made in the background.
+func WithOnResult(fn func(Result)) Option {
+	return func(s *Sender) { s.onResult = fn }
+}
+
+// WithClock replaces the system clock used for signing timestamps and the
+// retry schedule, for tests.
+func WithClock(clk resilient.Clock) Option {
+	return func(s *Sender) { s.clock = clk }
+}
+
+// Result is the outcome of one delivery attempt.
+type Result struct {
+	URL        string
+	Attempt    int // 1 for the first attempt
+	StatusCode int
+	Err        error
+	// Final reports that no further attempt will be made, because the
+	// delivery succeeded, the schedule is exhausted or the endpoint was
+	// disabled.
+	Final bool
+}
+
+// EndpointState is the delivery health of one destination URL.
+type EndpointState struct {
+	URL                 string
+	ConsecutiveFailures int
+	LastAttempt         time.Time
+	LastError           error
+	Disabled            bool
+}
+
+// Sender delivers webhooks. It is safe for concurrent use.
+type Sender struct {
+	client       *resilient.Client
+	secret       []byte
+	header       string
+	schedule     []time.Duration
+	schedules    map[string][]time.Duration
+	disableAfter int
+	onResult     func(Result)
+	clock        resilient.Clock
+
+	mu        sync.Mutex
+	endpoints map[string]*EndpointState
+	timers    map[resilient.Timer]struct{}
+	closed    bool
+}
+
+// New returns a Sender that sends through client. Each attempt is made
+// once, without the client's own retries, since the schedule replaces them.
+func New(client *resilient.Client, opts ...Option) *Sender {
+	s := &Sender{
+		client:       client.With(resilient.WithRetry(0, 0)),
+		header:       DefaultSignatureHeader,
+		schedule:     DefaultSchedule,
+		schedules:    make(map[string][]time.Duration),
+		disableAfter: 5,
+		clock:        systemClock{},
+		endpoints:    make(map[string]*EndpointState),
+		timers:       make(map[resilient.Timer]struct{}),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Send posts payload as JSON to url. The first attempt is made right away
+// and its error returned; if it fails, the retries follow the schedule in
+// the background and are reported to WithOnResult.
+func (s *Sender) Send(ctx context.Context, url string, payload []byte) error {
+	if s.disabled(url) {
+		return fmt.Errorf("%w: %s", ErrEndpointDisabled, url)
+	}
+	return s.attempt(ctx, url, payload, 1)
+}
+
+func (s *Sender) attempt(ctx context.Context, url string, payload []byte, n int) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != nil {
+		req.Header.Set(s.header, Sign(s.secret, s.clock.Now(), payload))
+	}
+	_, status, err := s.client.Do(ctx, req)
+
+	res := Result{URL: url, Attempt: n, StatusCode: status, Err: err}
+	delays := s.scheduleFor(url)
+	s.mu.Lock()
+	disabled := s.record(url, status, err)
+	res.Final = err == nil || disabled || n > len(delays) || s.closed
+	if !res.Final {
+		var t resilient.Timer
+		t = s.clock.AfterFunc(delays[n-1], func() {
+			s.mu.Lock()
+			delete(s.timers, t)
+			s.mu.Unlock()
+			s.retry(url, payload, n+1)
+		})
+		s.timers[t] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	if s.onResult != nil {
+		s.onResult(res)
+	}
+	return err
+}
+
+// retry makes a scheduled attempt, unless the endpoint was disabled or the
+// Sender closed meanwhile.
+func (s *Sender) retry(url string, payload []byte, n int) {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed || s.disabled(url) {
+		return
+	}
+	s.attempt(context.Background(), url, payload, n)
+}
+
+func (s *Sender) scheduleFor(url string) []time.Duration {
+	if d, ok := s.schedules[url]; ok {
+		return d
+	}
+	return s.schedule
+}
+
+// record updates the endpoint's state after an attempt and reports whether
+// it is now disabled. It must be called with s.mu held.
+func (s *Sender) record(url string, status int, err error) bool {
+	e := s.endpoints[url]
+	if e == nil {
+		e = &EndpointState{URL: url}
+		s.endpoints[url] = e
+	}
+	e.LastAttempt = s.clock.Now()
+	e.LastError = err
+	if err == nil {
+		e.ConsecutiveFailures = 0
+		return false
+	}
+	e.ConsecutiveFailures++
+	if status == http.StatusGone || (s.disableAfter > 0 && e.ConsecutiveFailures >= s.disableAfter) {
+		e.Disabled = true
+	}
+	return e.Disabled
+}
+
+func (s *Sender) disabled(url string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.endpoints[url]
+	return e != nil && e.Disabled
+}
+
+// Enable re-enables a disabled endpoint and clears its failure count.
+func (s *Sender) Enable(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.endpoints[url]; e != nil {
+		e.Disabled, e.ConsecutiveFailures = false, 0
+	}
+}
+
+// Endpoints returns the state of every endpoint sent to, sorted by URL.
+func (s *Sender) Endpoints() []EndpointState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	states := make([]EndpointState, 0, len(s.endpoints))
+	for _, e := range s.endpoints {
+		states = append(states, *e)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].URL < states[j].URL })
+	return states
+}
+
+// Pending returns the number of retries scheduled.
+func (s *Sender) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.timers)
+}
+
+// Close cancels the scheduled retries. It does not close the client.
+func (s *Sender) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for t := range s.timers {
+		t.Stop()
+	}
+	clear(s.timers)
+}
+
+// Sign returns the signature header value for payload sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<payload>">".
+// Including the timestamp lets receivers reject replayed deliveries.
+func Sign(secret []byte, t time.Time, payload []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, payload))
+}
+
+// Verify checks a signature header produced by Sign against payload,
+// rejecting signatures older than tolerance (if positive).
+func Verify(secret []byte, header string, payload []byte, tolerance time.Duration) error {
+	var ts string
+	var sigs [][]byte
+	for part := range strings.SplitSeq(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && time.Since(time.Unix(unix, 0)) > tolerance {
+		return fmt.Errorf("%w: expired", ErrInvalidSignature)
+	}
+	want := mac(secret, ts, payload)
+	if !slices.ContainsFunc(sigs, func(sig []byte) bool { return hmac.Equal(sig, want) }) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func mac(secret []byte, ts string, payload []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// systemClock is the Clock backed by package time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) resilient.Timer {
+	return time.AfterFunc(d, f)
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/egorkaBurkenya/resilient-go"
+	"github.com/egorkaBurkenya/resilient-go/resilienttest"
+)
+
+func TestSendSigned(t *testing.T) {
+	secret := []byte("s3cret")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify(secret, r.Header.Get(DefaultSignatureHeader), body, time.Minute); err != nil {
+			t.Errorf("signature: %v", err)
+		}
+		if err := Verify([]byte("other"), r.Header.Get(DefaultSignatureHeader), body, 0); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("expected a wrong secret to fail, got %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	c := resilient.New()
+	defer c.Close()
+	s := New(c, WithSecret(secret))
+	defer s.Close()
+	if err := s.Send(context.Background(), srv.URL, []byte(`{"event":"paid"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if eps := s.Endpoints(); len(eps) != 1 || eps[0].ConsecutiveFailures != 0 || eps[0].Disabled {
+		t.Fatalf("unexpected endpoint state %+v", eps)
+	}
+}
+
+func TestRetryScheduleAndDisable(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	clock := resilienttest.NewFakeClock(time.Now())
+	var mu sync.Mutex
+	var results []Result
+	c := resilient.New(resilient.WithRetry(5, 0))
+	defer c.Close()
+	s := New(c,
+		WithClock(clock),
+		WithSchedule(time.Minute, 5*time.Minute, 30*time.Minute),
+		WithDisableAfter(3),
+		WithOnResult(func(r Result) {
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		}),
+	)
+	defer s.Close()
+
+	if err := s.Send(context.Background(), srv.URL, []byte(`{}`)); err == nil {
+		t.Fatal("expected the first attempt to fail")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected one attempt without the client's retries, got %d", n)
+	}
+
+	wait := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for calls.Load() != int32(n) {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d attempts, got %d", n, calls.Load())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	clock.Advance(59 * time.Second)
+	time.Sleep(20 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected no retry before a minute, got %d attempts", n)
+	}
+	clock.Advance(time.Second)
+	wait(2)
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Minute)
+	wait(3)
+
+	// The third failure disables the endpoint and ends the schedule.
+	deadline := time.Now().Add(time.Second)
+	for s.Pending() != 0 || len(s.Endpoints()) == 0 || !s.Endpoints()[0].Disabled {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the endpoint to be disabled, got %+v", s.Endpoints())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := s.Send(context.Background(), srv.URL, nil); !errors.Is(err, ErrEndpointDisabled) {
+		t.Fatalf("expected ErrEndpointDisabled, got %v", err)
+	}
+	mu.Lock()
+	if last := results[len(results)-1]; len(results) != 3 || last.Attempt != 3 || !last.Final || last.StatusCode != 500 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	mu.Unlock()
+
+	s.Enable(srv.URL)
+	if err := s.Send(context.Background(), srv.URL, nil); errors.Is(err, ErrEndpointDisabled) {
+		t.Fatal("expected Enable to re-enable the endpoint")
+	}
+}